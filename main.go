@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"pkg/controllers"
-	"pkg/routes"
-	"pkg/utils"
-	"pkg/services"
-	
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/routes"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
 )
 
 func main() {
@@ -20,55 +27,68 @@ func main() {
 
 	// Load environment variables.
 	port := os.Getenv("PORT")
-	if os.Getenv("JWT_SECRET") == "" {
-		log.Fatalf("JWT_SECRET not set")
-	}
-	if os.Getenv("DB_NAME") == "" {
-		log.Fatalf("DB_NAME not set")
-	}
 
 	if port == "" {
 		port = "8080"
 		log.Printf("defaulting to port %s", port)
 	}
 
-	// Connect to MongoDB using the utility function from db.go
-	client, ctx, err := utils.ConnectDB()
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
-	}
+	// DB_DRIVER selects the repository backend: "mongo" (default) or "memory".
+	var repos *repositories.Repositories
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "mongo":
+		if os.Getenv("DB_NAME") == "" {
+			log.Fatalf("DB_NAME not set")
+		}
 
-	defer func() {
-		if err := client.Disconnect(context.TODO()); err != nil {
-			panic(err)
+		// Connect to MongoDB using the utility function from db.go
+		client, err := utils.ConnectDB()
+		if err != nil {
+			log.Fatalf("Failed to connect to MongoDB: %v", err)
 		}
-	}()
 
-	db := client.Database(os.Getenv("DB_NAME")) // Use the global db variable
-	// We don't use database migrations for this project because we're using MongoDB.
-	// MongoDB is a NoSQL database that doesn't require schema migrations in the same way as relational databases.
-	// Schema changes can be handled dynamically within the application.
+		defer func() {
+			if err := client.Disconnect(context.TODO()); err != nil {
+				panic(err)
+			}
+		}()
 
-	// Dependency Injection
-	userController := controllers.NewUserController(db)
-	heroController := controllers.NewHeroController(db)
-	serviceController := controllers.NewServiceController(db)
-	videoService := services.NewVideoService(db, utils.NewS3Client())
-	videoController := controllers.NewVideoController(videoService)
-	aboutService := services.NewAboutService(db, utils.NewS3Client())
-	aboutController := controllers.NewAboutController(aboutService)
-	blogService := services.NewBlogService(db, utils.NewS3Client())
-	blogController := controllers.NewBlogController(blogService)
+		db := client.Database(os.Getenv("DB_NAME"))
+		// We don't use database migrations for this project because we're using MongoDB.
+		// MongoDB is a NoSQL database that doesn't require schema migrations in the same way as relational databases.
+		// Schema changes can be handled dynamically within the application.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := repositories.EnsureMongoIndexes(ctx, db); err != nil {
+			log.Fatalf("Failed to create MongoDB indexes: %v", err)
+		}
+		cancel()
+		repos = repositories.NewMongoRepositories(db)
+	case "memory":
+		// MEMORY_SNAPSHOT_PATH optionally persists the in-memory data to a JSON file.
+		db, err := repositories.NewMemoryDatabase(os.Getenv("MEMORY_SNAPSHOT_PATH"))
+		if err != nil {
+			log.Fatalf("Failed to load in-memory database: %v", err)
+		}
+		log.Printf("using in-memory repositories")
+		repos = repositories.NewMemoryRepositories(db)
+	default:
+		log.Fatalf("unknown DB_DRIVER %q", driver)
+	}
 
-	router := gin.Default()
+	storage, err := newStorage()
+	if err != nil {
+		log.Printf("file storage is not configured, uploads are disabled: %v", err)
+	}
 
-	// Routes Setup
-	routes.VideoRoutes(router, videoController)
-	routes.BlogRoutes(router, blogController)	
+	mailer, err := newMailer()
+	if err != nil {
+		log.Fatalf("Failed to configure mail: %v", err)
+	}
 
-	routes.UserRoutes(router, userController) // Use the imported UserRoutes
-	routes.HeroRoutes(router,heroController)
-	routes.ServiceRoutes(router, serviceController)
+	router, err := setupRouter(repos, storage, mailer)
+	if err != nil {
+		log.Fatalf("Failed to set up routes: %v", err)
+	}
 
 	// Start HTTP Server
 	srv := &http.Server{
@@ -80,6 +100,332 @@ func main() {
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("listen: %s\n", err)
 	}
-	routes.AboutRoutes(router, aboutController)
+}
+
+// newStorage creates the file storage selected by STORAGE_DRIVER: "s3"
+// (default) or "local".
+func newStorage() (utils.Storage, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "s3":
+		s3Client, err := utils.NewS3Client()
+		if err != nil {
+			return nil, err
+		}
+		return s3Client, nil
+	case "local":
+		dir := os.Getenv("LOCAL_STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		baseURL := os.Getenv("LOCAL_STORAGE_URL")
+		if baseURL == "" {
+			baseURL = "/uploads"
+		}
+		localStorage, err := utils.NewLocalStorage(dir, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		return localStorage, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+}
+
+// developmentMode reports whether APP_ENV is "development", which enables
+// defaults that are unsafe in production.
+func developmentMode() bool {
+	return os.Getenv("APP_ENV") == "development"
+}
+
+// newMailer creates the mail sender selected by MAIL_DRIVER: "log", "file"
+// or "smtp". The log mailer prints mails, including password reset links,
+// so it is the default only in development and refused otherwise.
+func newMailer() (utils.Mailer, error) {
+	driver := os.Getenv("MAIL_DRIVER")
+	if driver == "" {
+		if !developmentMode() {
+			return nil, errors.New("MAIL_DRIVER not set")
+		}
+		driver = "log"
+	}
+	switch driver {
+	case "log":
+		if !developmentMode() {
+			return nil, errors.New(`MAIL_DRIVER "log" is only allowed when APP_ENV=development`)
+		}
+		return &utils.FileMailer{From: os.Getenv("MAIL_FROM")}, nil
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return utils.NewFileMailer(dir, os.Getenv("MAIL_FROM"))
+	case "smtp":
+		return utils.NewSMTPMailer(utils.SMTPConfigFromEnv())
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
+
+// newKeyService loads the access token signing keys and starts rotating
+// them. JWT_ALGORITHM selects "RS256" (default) or "EdDSA" and
+// JWT_KEY_ROTATION_INTERVAL how long each key signs, e.g. "720h".
+func newKeyService(keys repositories.SigningKeyRepository) (*services.KeyService, error) {
+	algorithm := os.Getenv("JWT_ALGORITHM")
+	if algorithm == "" {
+		algorithm = utils.AlgorithmRS256
+	}
+	var rotationInterval time.Duration
+	if value := os.Getenv("JWT_KEY_ROTATION_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_KEY_ROTATION_INTERVAL: %w", err)
+		}
+		rotationInterval = interval
+	}
+
+	keyService := services.NewKeyService(keys, algorithm, rotationInterval)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := keyService.Init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	go keyService.Run(context.Background())
+	return keyService, nil
+}
+
+// newOIDCService configures sign-in with the identity provider at
+// OIDC_ISSUER. It returns nil if no issuer is set. OIDC_ROLE_MAPPING lists
+// "value=role" pairs for the values of OIDC_ROLE_CLAIM, e.g.
+// "cms-admins=admin,cms-editors=editor".
+func newOIDCService(logins repositories.OIDCLoginRepository, users *services.UserService) (*services.OIDCService, error) {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil, nil
+	}
+	client, err := utils.NewOIDCClient(issuer, os.Getenv("OIDC_CLIENT_ID"), os.Getenv("OIDC_CLIENT_SECRET"), os.Getenv("OIDC_REDIRECT_URL"), strings.Fields(os.Getenv("OIDC_SCOPES")))
+	if err != nil {
+		return nil, err
+	}
+
+	roleClaim := os.Getenv("OIDC_ROLE_CLAIM")
+	if roleClaim == "" {
+		roleClaim = "groups"
+	}
+	roleMapping := map[string]models.Role{}
+	for _, pair := range strings.Split(os.Getenv("OIDC_ROLE_MAPPING"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		value, role, ok := strings.Cut(pair, "=")
+		if !ok || !models.Role(strings.TrimSpace(role)).Valid() {
+			return nil, fmt.Errorf("invalid OIDC_ROLE_MAPPING entry %q", pair)
+		}
+		roleMapping[strings.TrimSpace(value)] = models.Role(strings.TrimSpace(role))
+	}
+	defaultRole := models.Role(os.Getenv("OIDC_DEFAULT_ROLE"))
+	if defaultRole != "" && !defaultRole.Valid() {
+		return nil, fmt.Errorf("invalid OIDC_DEFAULT_ROLE %q", defaultRole)
+	}
+
+	return services.NewOIDCService(client, logins, users, roleClaim, roleMapping, defaultRole), nil
+}
+
+// totpIssuer returns the name authenticator apps show for our accounts.
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "TVWC"
+}
+
+// siteName returns the name of the site used in feed titles.
+func siteName() string {
+	if name := os.Getenv("SITE_NAME"); name != "" {
+		return name
+	}
+	return "TVWC"
+}
+
+// publicURLs returns the public website, SITE_URL, and the public URL of
+// this server, API_URL, which defaults to the website. Feeds, sitemaps and
+// robots.txt link to them, so they are configured rather than taken from
+// request headers. In development SITE_URL defaults to the local server.
+func publicURLs() (site, api string, err error) {
+	site = os.Getenv("SITE_URL")
+	if site == "" {
+		if !developmentMode() {
+			return "", "", errors.New("SITE_URL not set")
+		}
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		site = "http://localhost:" + port
+	}
+	api = os.Getenv("API_URL")
+	if api == "" {
+		api = site
+	}
+	if site, err = absoluteURL("SITE_URL", site); err != nil {
+		return "", "", err
+	}
+	if api, err = absoluteURL("API_URL", api); err != nil {
+		return "", "", err
+	}
+	return site, api, nil
+}
+
+// absoluteURL checks that the named setting is an absolute http or https
+// URL and returns it without a trailing slash.
+func absoluteURL(name, value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid %s %q, expected an absolute http or https URL", name, value)
+	}
+	return strings.TrimSuffix(value, "/"), nil
+}
+
+// robotsDisallow returns the path prefixes robots.txt asks crawlers to skip.
+// An empty ROBOTS_DISALLOW allows everything.
+func robotsDisallow() []string {
+	value, ok := os.LookupEnv("ROBOTS_DISALLOW")
+	if !ok {
+		value = "/admin/,/api/admin/,/users/,/auth/,/invitations/,/api-keys,/signing-keys,/login-lockouts,/audit-log,/api/search"
+	}
+	var paths []string
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// trustProxies configures where the client IP used by the login throttle,
+// sessions and the audit log comes from. TRUSTED_PROXIES lists the IPs or
+// CIDRs of the proxies whose X-Forwarded-For header is trusted; by default
+// none are and the address of the connection is used. TRUSTED_PLATFORM
+// reads the IP from the header of a hosting platform instead: "appengine",
+// "cloudflare" or "flyio".
+func trustProxies(router *gin.Engine) error {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		return fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	switch platform := os.Getenv("TRUSTED_PLATFORM"); platform {
+	case "":
+	case "appengine":
+		router.TrustedPlatform = gin.PlatformGoogleAppEngine
+	case "cloudflare":
+		router.TrustedPlatform = gin.PlatformCloudflare
+	case "flyio":
+		router.TrustedPlatform = gin.PlatformFlyIO
+	default:
+		return fmt.Errorf("unknown TRUSTED_PLATFORM %q", platform)
+	}
+	return nil
+}
+
+// setupRouter wires the controllers to the given repositories, storage and
+// mailer and registers every route.
+func setupRouter(repos *repositories.Repositories, storage utils.Storage, mailer utils.Mailer) (*gin.Engine, error) {
+	// Dependency Injection
+	keyService, err := newKeyService(repos.SigningKeys)
+	if err != nil {
+		return nil, err
+	}
+	// Changes to content and users are logged with their diffs.
+	auditService := services.NewAuditService(repos.Audit)
+	repos = auditService.Audited(repos)
+	sessionService := services.NewSessionService(repos.Sessions, keyService, os.Getenv("JWT_ISSUER"))
+	userService := services.NewUserService(repos.Users, sessionService)
+	apiKeyService := services.NewAPIKeyService(repos.APIKeys, userService)
+	authMiddleware := middlewares.AuthMiddleware(repos.Users, sessionService, apiKeyService)
+	invitationService := services.NewInvitationService(repos.Invitations, userService)
+	loginThrottleService := services.NewLoginThrottleService(repos.LoginThrottles, auditService)
+	twoFactorService := services.NewTwoFactorService(userService, repos.LoginChallenges, loginThrottleService, totpIssuer())
+	userController := controllers.NewUserController(userService, invitationService, sessionService, twoFactorService, loginThrottleService)
+	loginLockoutController := controllers.NewLoginLockoutController(loginThrottleService, userService)
+	keyController := controllers.NewKeyController(keyService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	auditController := controllers.NewAuditController(auditService)
+	twoFactorController := controllers.NewTwoFactorController(twoFactorService, sessionService)
+	invitationController := controllers.NewInvitationController(invitationService)
+	passwordResetService := services.NewPasswordResetService(repos.PasswordResets, repos.LoginThrottles, userService, mailer, os.Getenv("PASSWORD_RESET_URL"))
+	passwordResetController := controllers.NewPasswordResetController(passwordResetService)
+	heroController := controllers.NewHeroController(repos.Heroes)
+	serviceService := services.NewServiceService(repos.Services)
+	serviceController := controllers.NewServiceController(serviceService)
+	videoService := services.NewVideoService(repos.Videos, repos.PendingUploads, storage)
+	videoController := controllers.NewVideoController(videoService)
+	aboutService := services.NewAboutService(repos.Abouts, storage)
+	aboutController := controllers.NewAboutController(aboutService)
+	blogService := services.NewBlogService(repos.Blogs, repos.BlogRevisions, storage)
+	go blogService.RunScheduler(context.Background())
+	taxonomyService := services.NewTaxonomyService(repos.Tags, repos.Categories, blogService)
+	blogController := controllers.NewBlogController(blogService, taxonomyService)
+	taxonomyController := controllers.NewTaxonomyController(taxonomyService)
+	searchController := controllers.NewSearchController(services.NewSearchService(repos.Blogs, repos.Videos, repos.Services))
+	siteURL, apiURL, err := publicURLs()
+	if err != nil {
+		return nil, err
+	}
+	feedController := controllers.NewFeedController(services.NewFeedService(blogService, videoService, siteName()), services.FeedLinks{Site: siteURL, Origin: apiURL})
+	sitemapService := services.NewSitemapService(blogService, videoService, serviceService, siteURL)
+	sitemapController := controllers.NewSitemapController(sitemapService, apiURL, robotsDisallow())
+	oidcService, err := newOIDCService(repos.OIDCLogins, userService)
+	if err != nil {
+		return nil, err
+	}
+
+	// INITIAL_ADMIN_EMAIL invites the first admin while there are no users.
+	if email := os.Getenv("INITIAL_ADMIN_EMAIL"); email != "" {
+		token, err := invitationService.BootstrapAdmin(context.Background(), email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to invite initial admin: %w", err)
+		}
+		if token != "" {
+			log.Printf("invited initial admin %s, sign up at /users/signup with token %s", email, token)
+		}
+	}
+
+	router := gin.Default()
+	if err := trustProxies(router); err != nil {
+		return nil, err
+	}
+
+	// Routes Setup
+	routes.VideoRoutes(router, videoController, authMiddleware)
+	routes.BlogRoutes(router, blogController, authMiddleware)
+	routes.TaxonomyRoutes(router, taxonomyController, authMiddleware)
+	routes.SearchRoutes(router, searchController)
+	routes.FeedRoutes(router, feedController)
+	routes.SitemapRoutes(router, sitemapController)
+	routes.AboutRoutes(router, aboutController, authMiddleware)
+	routes.UserRoutes(router, userController, authMiddleware)
+	routes.InvitationRoutes(router, invitationController, authMiddleware)
+	routes.PasswordResetRoutes(router, passwordResetController)
+	routes.TwoFactorRoutes(router, twoFactorController, authMiddleware)
+	if oidcService != nil {
+		routes.OIDCRoutes(router, controllers.NewOIDCController(oidcService, sessionService))
+	}
+	routes.LoginLockoutRoutes(router, loginLockoutController, authMiddleware)
+	routes.KeyRoutes(router, keyController, authMiddleware)
+	routes.APIKeyRoutes(router, apiKeyController, authMiddleware)
+	routes.AuditRoutes(router, auditController, authMiddleware)
+	routes.HeroRoutes(router, heroController, authMiddleware)
+	routes.ServiceRoutes(router, serviceController, authMiddleware)
+	if localStorage, ok := storage.(*utils.LocalStorage); ok {
+		if err := routes.StorageRoutes(router, localStorage); err != nil {
+			return nil, err
+		}
+	}
 
-}
\ No newline at end of file
+	return router, nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/routes"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// TestLoginThrottleIgnoresSpoofedForwardedFor checks that a client cannot
+// escape the per-IP login throttle by sending its own X-Forwarded-For.
+func TestLoginThrottleIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name           string
+		trustedProxies string
+		remoteIP       string
+		// wantForwarded is whether failures count against the forwarded IP
+		// rather than the remote one.
+		wantForwarded bool
+	}{
+		{name: "no trusted proxies", remoteIP: "192.0.2.1"},
+		{name: "untrusted remote", trustedProxies: "10.0.0.0/8", remoteIP: "192.0.2.1"},
+		{name: "trusted proxy", trustedProxies: "10.0.0.0/8, 172.16.0.1", remoteIP: "10.1.2.3", wantForwarded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", tt.trustedProxies)
+			t.Setenv("TRUSTED_PLATFORM", "")
+			db, err := repositories.NewMemoryDatabase("")
+			if err != nil {
+				t.Fatalf("NewMemoryDatabase: %v", err)
+			}
+			repos := repositories.NewMemoryRepositories(db)
+			throttle := services.NewLoginThrottleService(repos.LoginThrottles, services.NewAuditService(repos.Audit))
+			userController := controllers.NewUserController(services.NewUserService(repos.Users, nil), nil, nil, nil, throttle)
+			router := gin.New()
+			if err := trustProxies(router); err != nil {
+				t.Fatalf("trustProxies: %v", err)
+			}
+			routes.UserRoutes(router, userController, func(c *gin.Context) { c.Next() })
+
+			const attempts = 5
+			for i := 0; i < attempts; i++ {
+				// A new email and forwarded IP every time, so only the
+				// counter of the real client IP can add up.
+				body := fmt.Sprintf(`{"email":"user%d@example.com","password":"wrong"}`, i)
+				req := httptest.NewRequest(http.MethodPost, "/users/login", strings.NewReader(body))
+				req.RemoteAddr = tt.remoteIP + ":40000"
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i))
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != http.StatusUnauthorized {
+					t.Fatalf("login %d: got status %d, want %d", i, w.Code, http.StatusUnauthorized)
+				}
+			}
+
+			ctx := context.Background()
+			remote, err := repos.LoginThrottles.FindByID(ctx, models.LoginThrottleID(models.ThrottleKindIP, tt.remoteIP))
+			if tt.wantForwarded {
+				if !errors.Is(err, repositories.ErrNotFound) {
+					t.Errorf("throttle of the proxy: got %+v, %v, want none", remote, err)
+				}
+				forwarded, err := repos.LoginThrottles.FindByID(ctx, models.LoginThrottleID(models.ThrottleKindIP, "203.0.113.0"))
+				if err != nil || forwarded.Failures != 1 {
+					t.Errorf("throttle of the forwarded IP: got %+v, %v, want 1 failure", forwarded, err)
+				}
+				return
+			}
+			if err != nil || remote.Failures != attempts {
+				t.Errorf("throttle of the remote IP: got %+v, %v, want %d failures", remote, err, attempts)
+			}
+			for i := 0; i < attempts; i++ {
+				spoofed := fmt.Sprintf("203.0.113.%d", i)
+				if _, err := repos.LoginThrottles.FindByID(ctx, models.LoginThrottleID(models.ThrottleKindIP, spoofed)); !errors.Is(err, repositories.ErrNotFound) {
+					t.Errorf("throttle of spoofed IP %s: got error %v, want %v", spoofed, err, repositories.ErrNotFound)
+				}
+			}
+		})
+	}
+}
+
+func TestTrustProxiesInvalid(t *testing.T) {
+	tests := []struct {
+		name            string
+		trustedProxies  string
+		trustedPlatform string
+	}{
+		{name: "invalid proxy", trustedProxies: "not-an-ip"},
+		{name: "unknown platform", trustedPlatform: "heroku"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", tt.trustedProxies)
+			t.Setenv("TRUSTED_PLATFORM", tt.trustedPlatform)
+			if err := trustProxies(gin.New()); err == nil {
+				t.Error("trustProxies: got no error")
+			}
+		})
+	}
+}
+
+func TestPublicURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		siteURL  string
+		apiURL   string
+		wantSite string
+		wantAPI  string
+		wantErr  bool
+	}{
+		{name: "site only", siteURL: "https://example.com/", wantSite: "https://example.com", wantAPI: "https://example.com"},
+		{name: "site and API", siteURL: "https://example.com", apiURL: "https://api.example.com/", wantSite: "https://example.com", wantAPI: "https://api.example.com"},
+		{name: "development default", env: "development", wantSite: "http://localhost:8099", wantAPI: "http://localhost:8099"},
+		{name: "no site", wantErr: true},
+		{name: "relative site", siteURL: "/blog", wantErr: true},
+		{name: "no scheme", siteURL: "example.com", wantErr: true},
+		{name: "other scheme", siteURL: "ftp://example.com", wantErr: true},
+		{name: "invalid API", siteURL: "https://example.com", apiURL: "api.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.env)
+			t.Setenv("PORT", "8099")
+			t.Setenv("SITE_URL", tt.siteURL)
+			t.Setenv("API_URL", tt.apiURL)
+			site, api, err := publicURLs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("publicURLs: got error %v, want error %v", err, tt.wantErr)
+			}
+			if site != tt.wantSite || api != tt.wantAPI {
+				t.Errorf("publicURLs: got %q, %q, want %q, %q", site, api, tt.wantSite, tt.wantAPI)
+			}
+		})
+	}
+}
+
+func TestStorageRoutesBasePath(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		wantErr bool
+	}{
+		{baseURL: "/uploads"},
+		{baseURL: "https://api.example.com/files/"},
+		{baseURL: "/", wantErr: true},
+		{baseURL: "https://api.example.com", wantErr: true},
+		{baseURL: "https://api.example.com/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			storage, err := utils.NewLocalStorage(t.TempDir(), tt.baseURL)
+			if err != nil {
+				t.Fatalf("NewLocalStorage: %v", err)
+			}
+			router := gin.New()
+			router.GET("/blogs", func(c *gin.Context) {})
+			if err := routes.StorageRoutes(router, storage); (err != nil) != tt.wantErr {
+				t.Errorf("StorageRoutes: got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
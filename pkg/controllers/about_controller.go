@@ -1,27 +1,23 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 
-	"your_project/pkg/models"
-	"your_project/pkg/services"
-	"your_project/pkg/utils"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
 )
 
 type AboutController struct {
-	db             *gorm.DB
-	s3Util         *utils.S3Util
-	aboutService   *services.AboutService
+	aboutService *services.AboutService
 }
 
-func NewAboutController(db *gorm.DB, s3Util *utils.S3Util, aboutService *services.AboutService) *AboutController {
+func NewAboutController(aboutService *services.AboutService) *AboutController {
 	return &AboutController{
-		db:             db,
-		s3Util:         s3Util,
 		aboutService: aboutService,
 	}
 }
@@ -35,7 +31,7 @@ func (ac *AboutController) CreateAbout(c *gin.Context) {
 
 	about.ID = uuid.New().String()
 
-	err := ac.aboutService.CreateAbout(&about)
+	err := ac.aboutService.CreateAbout(c.Request.Context(), &about)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create about"})
 		return
@@ -45,8 +41,12 @@ func (ac *AboutController) CreateAbout(c *gin.Context) {
 }
 
 func (ac *AboutController) GetAbout(c *gin.Context) {
-	about, err := ac.aboutService.GetAbout()
+	about, err := ac.aboutService.GetAbout(c.Request.Context())
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "about not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get about"})
 		return
 	}
@@ -63,9 +63,13 @@ func (ac *AboutController) UpdateAbout(c *gin.Context) {
 		return
 	}
 	about.ID = id
-	err := ac.aboutService.UpdateAbout(&about)
+	err := ac.aboutService.UpdateAbout(c.Request.Context(), &about)
 
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "about not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update about"})
 		return
 	}
@@ -73,34 +77,50 @@ func (ac *AboutController) UpdateAbout(c *gin.Context) {
 	c.JSON(http.StatusOK, about)
 }
 
+// UploadImage expects a multipart form with an "image" file and replaces the
+// about entry's image.
+func (ac *AboutController) UploadImage(c *gin.Context) {
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read image file"})
+		return
+	}
+	defer file.Close()
+
+	about, err := ac.aboutService.UploadImage(c.Request.Context(), c.Param("id"), file, fileHeader.Filename)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "about not found"})
+			return
+		}
+		if errors.Is(err, services.ErrUnsupportedFileType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload about image"})
+		return
+	}
+
+	c.JSON(http.StatusOK, about)
+}
+
 func (ac *AboutController) DeleteAbout(c *gin.Context) {
 	id := c.Param("id")
 
-	err := ac.aboutService.DeleteAbout(id)
+	err := ac.aboutService.DeleteAbout(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "about not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete about"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "about deleted"})
 }
-```
-```go
-package routes
-
-import (
-	"your_project/pkg/controllers"
-	"your_project/pkg/middlewares"
-
-	"github.com/gin-gonic/gin"
-)
-
-func RegisterAboutRoutes(router *gin.Engine, aboutController *controllers.AboutController) {
-	aboutGroup := router.Group("/about")
-	{
-		aboutGroup.GET("", aboutController.GetAbout)
-		aboutGroup.POST("", middlewares.AuthMiddleware(), aboutController.CreateAbout)
-		aboutGroup.PUT("/:id", middlewares.AuthMiddleware(), aboutController.UpdateAbout)
-		aboutGroup.DELETE("/:id", middlewares.AuthMiddleware(), aboutController.DeleteAbout)
-	}
-}
\ No newline at end of file
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// APIKeyController handles API keys for machine clients.
+type APIKeyController struct {
+	apiKeys *services.APIKeyService
+}
+
+// NewAPIKeyController creates a new APIKeyController.
+func NewAPIKeyController(apiKeys *services.APIKeyService) *APIKeyController {
+	return &APIKeyController{apiKeys: apiKeys}
+}
+
+// CreateAPIKey creates a key acting for the current admin. The key is only
+// returned here.
+func (kc *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var req services.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	admin, _ := middlewares.CurrentUser(c)
+
+	apiKey, token, err := kc.apiKeys.CreateKey(c.Request.Context(), req, admin.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKey) || errors.Is(err, services.ErrInvalidScope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println("Error creating API key:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"api_key": apiKey, "key": token})
+}
+
+// GetAPIKeys returns every API key.
+func (kc *APIKeyController) GetAPIKeys(c *gin.Context) {
+	apiKeys, err := kc.apiKeys.ListKeys(c.Request.Context())
+	if err != nil {
+		log.Println("Error getting API keys:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get API keys"})
+		return
+	}
+	c.JSON(http.StatusOK, apiKeys)
+}
+
+// RevokeAPIKey stops an API key from being accepted.
+func (kc *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	apiKey, err := kc.apiKeys.RevokeKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		log.Println("Error revoking API key:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+	c.JSON(http.StatusOK, apiKey)
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// AuditController lets admins query the audit log.
+type AuditController struct {
+	audit *services.AuditService
+}
+
+// NewAuditController creates a new AuditController.
+func NewAuditController(audit *services.AuditService) *AuditController {
+	return &AuditController{audit: audit}
+}
+
+// GetAuditLog returns audit entries, newest first. The query parameters
+// actor_id, action, resource_type and resource_id filter the entries; from
+// and to (RFC 3339, to is exclusive) bound their time. limit caps the
+// number of entries; pass the created_at of the last entry as to for the
+// next page.
+func (ac *AuditController) GetAuditLog(c *gin.Context) {
+	filter := repositories.AuditFilter{
+		ActorID:      c.Query("actor_id"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	}
+	for name, bound := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " time, expected RFC 3339"})
+			return
+		}
+		*bound = t
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := ac.audit.Find(c.Request.Context(), filter)
+	if err != nil {
+		log.Println("Error getting audit log:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
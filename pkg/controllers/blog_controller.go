@@ -1,29 +1,30 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 
-	"your_module/pkg/models"
-	"your_module/pkg/services"
-	"your_module/pkg/utils"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
 )
 
 type BlogController struct {
-	DB          *gorm.DB
-	S3          *utils.S3Utility
 	BlogService *services.BlogService
+	Taxonomy    *services.TaxonomyService
 }
 
-func NewBlogController(db *gorm.DB, s3 *utils.S3Utility, blogService *services.BlogService) *BlogController {
+func NewBlogController(blogService *services.BlogService, taxonomy *services.TaxonomyService) *BlogController {
 	return &BlogController{
-		DB:          db,
-		S3:          s3,
 		BlogService: blogService,
+		Taxonomy:    taxonomy,
 	}
 }
 
@@ -35,9 +36,26 @@ func (bc *BlogController) CreateBlog(c *gin.Context) {
 	}
 
 	blog.ID = uuid.New().String()
+	if user, ok := middlewares.CurrentUser(c); ok {
+		blog.AuthorID = user.ID
+		if blog.Author == "" {
+			blog.Author = user.Name
+		}
+	}
+	if !canChangeStatus(c, models.BlogDraft, blog.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to publish blogs"})
+		return
+	}
+	if !bc.assignTaxonomy(c, &blog) {
+		return
+	}
 
-	err := bc.BlogService.CreateBlog(&blog)
+	err := bc.BlogService.CreateBlog(c.Request.Context(), &blog)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidBlogStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create blog"})
 		return
 	}
@@ -51,8 +69,15 @@ func (bc *BlogController) DeleteBlog(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
 		return
 	}
-	err := bc.BlogService.DeleteBlog(id)
+	if !bc.authorizeBlog(c, id, models.PermBlogsDelete, models.PermBlogsDeleteOwn) {
+		return
+	}
+	err := bc.BlogService.DeleteBlog(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete blog"})
 		return
 	}
@@ -65,14 +90,24 @@ func (bc *BlogController) UpdateBlog(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
 		return
 	}
+	if !bc.authorizeBlog(c, id, models.PermBlogsUpdate, models.PermBlogsUpdateOwn) {
+		return
+	}
 	var updatedBlog models.Blog
 	if err := c.ShouldBindJSON(&updatedBlog); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !bc.assignTaxonomy(c, &updatedBlog) {
+		return
+	}
 
-	err := bc.BlogService.UpdateBlog(id, &updatedBlog)
+	err := bc.BlogService.UpdateBlog(c.Request.Context(), id, &updatedBlog)
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update blog"})
 		return
 	}
@@ -80,13 +115,177 @@ func (bc *BlogController) UpdateBlog(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedBlog)
 }
 
+// UploadImage expects a multipart form with an "image" file and replaces the
+// blog's cover image.
+func (bc *BlogController) UploadImage(c *gin.Context) {
+	if !bc.authorizeBlog(c, c.Param("id"), models.PermBlogsUpdate, models.PermBlogsUpdateOwn) {
+		return
+	}
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read image file"})
+		return
+	}
+	defer file.Close()
+
+	blog, err := bc.BlogService.UploadImage(c.Request.Context(), c.Param("id"), file, fileHeader.Filename)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
+			return
+		}
+		if errors.Is(err, services.ErrUnsupportedFileType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload blog image"})
+		return
+	}
+
+	c.JSON(http.StatusOK, blog)
+}
+
+type blogStatusRequest struct {
+	Status    models.BlogStatus `json:"status" binding:"required"`
+	PublishAt *time.Time        `json:"publish_at"`
+}
+
+// UpdateBlogStatus moves a blog through the workflow. Authors may move their
+// blogs between draft and in review; anything that publishes, schedules,
+// archives or unpublishes a blog requires the publish permission.
+func (bc *BlogController) UpdateBlogStatus(c *gin.Context) {
+	id := c.Param("id")
+	if !bc.authorizeBlog(c, id, models.PermBlogsUpdate, models.PermBlogsUpdateOwn) {
+		return
+	}
+	var req blogStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	blog, err := bc.BlogService.GetBlog(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve blog"})
+		return
+	}
+	if !canChangeStatus(c, blog.EffectiveStatus(), req.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not allowed to publish blogs"})
+		return
+	}
+
+	blog, err = bc.BlogService.SetStatus(c.Request.Context(), id, req.Status, req.PublishAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidBlogStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repositories.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update blog status"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, blog)
+}
+
+// GetAdminBlogs returns a page of blogs of every status, optionally
+// filtered by the status query parameter and the filters of GetAllBlogs.
+// Users who may only change their own blogs get only those.
+func (bc *BlogController) GetAdminBlogs(c *gin.Context) {
+	query, ok := bc.bindBlogQuery(c)
+	if !ok {
+		return
+	}
+	if !middlewares.Can(c, models.PermBlogsUpdate) {
+		user, _ := middlewares.CurrentUser(c)
+		query.Filters = append(query.Filters, repositories.Filter{Field: "author_id", Op: repositories.OpEq, Value: user.ID})
+	}
+	if value := c.Query("status"); value != "" {
+		status := models.BlogStatus(value)
+		if !status.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+		filter := repositories.Filter{Field: "status", Op: repositories.OpEq, Value: status}
+		// Blogs from before the workflow have no status and are published.
+		if status == models.BlogPublished {
+			filter = repositories.Filter{Field: "status", Op: repositories.OpIn, Value: []interface{}{status, nil}}
+		}
+		query.Filters = append(query.Filters, filter)
+	}
+
+	page, err := bc.BlogService.ListBlogs(c.Request.Context(), query)
+	if err != nil {
+		listFailed(c, err, "failed to retrieve blogs")
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetAdminBlog returns a blog by ID whatever its status.
+func (bc *BlogController) GetAdminBlog(c *gin.Context) {
+	id := c.Param("id")
+	if !bc.authorizeBlog(c, id, models.PermBlogsUpdate, models.PermBlogsUpdateOwn) {
+		return
+	}
+	blog, err := bc.BlogService.GetBlog(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve blog"})
+		return
+	}
+	c.JSON(http.StatusOK, blog)
+}
+
+// GetAllBlogs returns a page of the published blogs. They can be filtered
+// by author_id, author, tag, category (including its subcategories) and a
+// from/to range of the creation time, and sorted by created_at (the default, newest first), updated_at or
+// title.
 func (bc *BlogController) GetAllBlogs(c *gin.Context) {
-	blogs, err := bc.BlogService.GetAllBlogs()
+	query, ok := bc.bindBlogQuery(c)
+	if !ok {
+		return
+	}
+	page, err := bc.BlogService.ListPublishedBlogs(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve blogs"})
+		listFailed(c, err, "failed to retrieve blogs")
 		return
 	}
-	c.JSON(http.StatusOK, blogs)
+	c.JSON(http.StatusOK, page)
+}
+
+func (bc *BlogController) bindBlogQuery(c *gin.Context) (repositories.ListQuery, bool) {
+	query, ok := bindListQuery(c, "-created_at", "created_at", "updated_at", "title")
+	if !ok {
+		return query, false
+	}
+	bindEqualFilters(c, &query, map[string]string{
+		"author_id": "author_id",
+		"author":    "author",
+		"tag":       "tags",
+	})
+	if slug := c.Query("category"); slug != "" {
+		filter, err := bc.Taxonomy.CategoryFilter(c.Request.Context(), slug)
+		if err != nil {
+			log.Println("Error getting category filter:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve blogs"})
+			return query, false
+		}
+		query.Filters = append(query.Filters, filter)
+	}
+	return query, bindTimeRange(c, &query, "created_at")
 }
 
 func (bc *BlogController) GetBlogBySlug(c *gin.Context) {
@@ -95,9 +294,9 @@ func (bc *BlogController) GetBlogBySlug(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "slug is required"})
 		return
 	}
-	blog, err := bc.BlogService.GetBlogBySlug(slug)
+	blog, err := bc.BlogService.GetBlogBySlug(c.Request.Context(), slug)
 	if err != nil {
-		if err.Error() == "blog not found" {
+		if errors.Is(err, repositories.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to retrieve blog: %v", err)})
@@ -105,4 +304,58 @@ func (bc *BlogController) GetBlogBySlug(c *gin.Context) {
 		return
 	}
 	c.JSON(http.StatusOK, blog)
-}
\ No newline at end of file
+}
+
+// assignTaxonomy resolves the tags and categories of a blog, creating new
+// tags.
+func (bc *BlogController) assignTaxonomy(c *gin.Context, blog *models.Blog) bool {
+	if err := bc.Taxonomy.AssignTaxonomy(c.Request.Context(), blog); err != nil {
+		if errors.Is(err, services.ErrInvalidTaxonomy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to assign tags and categories"})
+		return false
+	}
+	return true
+}
+
+// canChangeStatus reports whether the current user may move a blog from one
+// status to another. Only draft and in review are open to every editor of
+// the blog.
+func canChangeStatus(c *gin.Context, from, to models.BlogStatus) bool {
+	if to == "" {
+		to = models.BlogDraft
+	}
+	open := func(s models.BlogStatus) bool { return s == models.BlogDraft || s == models.BlogInReview }
+	return (open(from) && open(to)) || middlewares.Can(c, models.PermBlogsPublish)
+}
+
+// authorizeBlog checks that the current user may act on a blog, either with
+// the permission on every blog or with ownPermission on a blog they wrote.
+// It writes the error response and returns false when they may not.
+func (bc *BlogController) authorizeBlog(c *gin.Context, id string, permission, ownPermission models.Permission) bool {
+	user, ok := middlewares.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return false
+	}
+	if middlewares.Can(c, permission) {
+		return true
+	}
+
+	blog, err := bc.BlogService.GetBlog(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve blog"})
+		}
+		return false
+	}
+	if !middlewares.Can(c, ownPermission) || blog.AuthorID == "" || blog.AuthorID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you can only change your own blogs"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+)
+
+// GetBlogRevisions lists the revisions of a blog, newest first.
+func (bc *BlogController) GetBlogRevisions(c *gin.Context) {
+	id := c.Param("id")
+	if !bc.authorizeBlog(c, id, models.PermBlogsUpdate, models.PermBlogsUpdateOwn) {
+		return
+	}
+	revisions, err := bc.BlogService.ListRevisions(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve revisions"})
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetBlogRevision returns a single revision of a blog.
+func (bc *BlogController) GetBlogRevision(c *gin.Context) {
+	id := c.Param("id")
+	if !bc.authorizeBlog(c, id, models.PermBlogsUpdate, models.PermBlogsUpdateOwn) {
+		return
+	}
+	number, ok := revisionNumber(c, c.Param("number"))
+	if !ok {
+		return
+	}
+	revision, err := bc.BlogService.GetRevision(c.Request.Context(), id, number)
+	if err != nil {
+		revisionError(c, err, "failed to retrieve revision")
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffBlogRevisions returns the line diff of the content of the revisions
+// given by the from and to query parameters.
+func (bc *BlogController) DiffBlogRevisions(c *gin.Context) {
+	id := c.Param("id")
+	if !bc.authorizeBlog(c, id, models.PermBlogsUpdate, models.PermBlogsUpdateOwn) {
+		return
+	}
+	from, ok := revisionNumber(c, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := revisionNumber(c, c.Query("to"))
+	if !ok {
+		return
+	}
+	diff, err := bc.BlogService.DiffRevisions(c.Request.Context(), id, from, to)
+	if err != nil {
+		revisionError(c, err, "failed to diff revisions")
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreBlogRevision makes an old revision the current version of a blog.
+func (bc *BlogController) RestoreBlogRevision(c *gin.Context) {
+	id := c.Param("id")
+	if !bc.authorizeBlog(c, id, models.PermBlogsUpdate, models.PermBlogsUpdateOwn) {
+		return
+	}
+	number, ok := revisionNumber(c, c.Param("number"))
+	if !ok {
+		return
+	}
+	blog, err := bc.BlogService.RestoreRevision(c.Request.Context(), id, number)
+	if err != nil {
+		revisionError(c, err, "failed to restore revision")
+		return
+	}
+	c.JSON(http.StatusOK, blog)
+}
+
+func revisionNumber(c *gin.Context, value string) (int, bool) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision number"})
+		return 0, false
+	}
+	return number, true
+}
+
+func revisionError(c *gin.Context, err error, message string) {
+	if errors.Is(err, repositories.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeConditional writes a generated document with an ETag of its content
+// and, unless modified is zero, a Last-Modified header. Clients that already
+// have the document get 304 Not Modified. If-None-Match takes precedence
+// over If-Modified-Since.
+func writeConditional(c *gin.Context, contentType string, body []byte, modified time.Time) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		if etagMatches(match, etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !modified.IsZero() {
+		// HTTP dates have no fractional seconds.
+		if !modified.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, using the
+// weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
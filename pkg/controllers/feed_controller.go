@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// FeedController serves the RSS and Atom feeds.
+type FeedController struct {
+	feeds *services.FeedService
+	links services.FeedLinks
+}
+
+// NewFeedController creates a new FeedController. Feed links point to the
+// configured site and origin, never to the host a request names.
+func NewFeedController(feeds *services.FeedService, links services.FeedLinks) *FeedController {
+	links.Site = strings.TrimSuffix(links.Site, "/")
+	links.Origin = strings.TrimSuffix(links.Origin, "/")
+	return &FeedController{feeds: feeds, links: links}
+}
+
+// GetBlogFeed returns the published blogs as an RSS or Atom feed, depending
+// on the "format" path parameter.
+func (fc *FeedController) GetBlogFeed(c *gin.Context) {
+	if !validFeedFormat(c) {
+		return
+	}
+	feed, err := fc.feeds.BlogFeed(c.Request.Context(), fc.links)
+	if err != nil {
+		log.Println("Error building blog feed:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get feed"})
+		return
+	}
+	fc.write(c, feed)
+}
+
+// GetVideoFeed returns the videos of a category as an RSS or Atom feed.
+func (fc *FeedController) GetVideoFeed(c *gin.Context) {
+	if !validFeedFormat(c) {
+		return
+	}
+	feed, err := fc.feeds.VideoFeed(c.Request.Context(), c.Param("category"), fc.links)
+	if err != nil {
+		log.Println("Error building video feed:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get feed"})
+		return
+	}
+	fc.write(c, feed)
+}
+
+func (fc *FeedController) write(c *gin.Context, feed *utils.Feed) {
+	feed.FeedURL = fc.links.Origin + c.Request.URL.Path
+	var body []byte
+	var err error
+	contentType := "application/rss+xml; charset=utf-8"
+	if c.Param("format") == "atom" {
+		body, err = feed.Atom()
+		contentType = "application/atom+xml; charset=utf-8"
+	} else {
+		body, err = feed.RSS()
+	}
+	if err != nil {
+		log.Println("Error encoding feed:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get feed"})
+		return
+	}
+	writeConditional(c, contentType, body, feed.Updated)
+}
+
+func validFeedFormat(c *gin.Context) bool {
+	switch c.Param("format") {
+	case "rss", "atom":
+		return true
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Unknown feed format"})
+	return false
+}
@@ -1,43 +1,41 @@
 package controllers
 
 import (
-	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"your_module_name/pkg/models"
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
 )
 
 // HeroController struct to hold dependencies
 type HeroController struct {
-	collection *mongo.Collection
-	ctx        context.Context
+	heroes repositories.HeroRepository
 }
 
 // NewHeroController creates a new HeroController
-func NewHeroController(collection *mongo.Collection, ctx context.Context) *HeroController {
+func NewHeroController(heroes repositories.HeroRepository) *HeroController {
 	return &HeroController{
-		collection: collection,
-		ctx:        ctx,
+		heroes: heroes,
 	}
 }
 
 // CreateHero creates a new hero section
 func (hc *HeroController) CreateHero(c *gin.Context) {
-	var hero models.Hero
+	var hero models.HeroSection
 	if err := c.ShouldBindJSON(&hero); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	hero.ID = primitive.NewObjectID()
+	hero.ID = uuid.New().String()
 	hero.CreatedAt = time.Now()
 	hero.UpdatedAt = time.Now()
 
-	_, err := hc.collection.InsertOne(hc.ctx, hero)
+	err := hc.heroes.Create(c.Request.Context(), &hero)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create hero section"})
 		return
@@ -48,13 +46,12 @@ func (hc *HeroController) CreateHero(c *gin.Context) {
 
 // GetHero gets the hero section
 func (hc *HeroController) GetHero(c *gin.Context) {
-	var hero models.Hero
-	err := hc.collection.FindOne(hc.ctx, bson.M{}).Decode(&hero)
+	hero, err := hc.heroes.FindFirst(c.Request.Context())
 	if err != nil {
-        if err == mongo.ErrNoDocuments {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Hero section not found"})
-            return
-        }
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hero section not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get hero section"})
 		return
 	}
@@ -64,15 +61,27 @@ func (hc *HeroController) GetHero(c *gin.Context) {
 
 // UpdateHero updates the hero section
 func (hc *HeroController) UpdateHero(c *gin.Context) {
-	var hero models.Hero
+	existing, err := hc.heroes.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hero section not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get hero section"})
+		return
+	}
+
+	var hero models.HeroSection
 	if err := c.ShouldBindJSON(&hero); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	hero.ID = existing.ID
+	hero.CreatedAt = existing.CreatedAt
 	hero.UpdatedAt = time.Now()
 
-	_, err := hc.collection.UpdateOne(hc.ctx, bson.M{}, bson.M{"$set": hero})
+	err = hc.heroes.Update(c.Request.Context(), &hero)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hero section"})
 		return
@@ -83,11 +92,15 @@ func (hc *HeroController) UpdateHero(c *gin.Context) {
 
 // DeleteHero deletes the hero section
 func (hc *HeroController) DeleteHero(c *gin.Context) {
-	_, err := hc.collection.DeleteOne(hc.ctx, bson.M{})
+	err := hc.heroes.Delete(c.Request.Context(), c.Param("id"))
 	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hero section not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete hero section"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Hero section deleted successfully"})
-}
\ No newline at end of file
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// InvitationController handles invitations of new users.
+type InvitationController struct {
+	invitations *services.InvitationService
+}
+
+// NewInvitationController creates a new InvitationController.
+func NewInvitationController(invitations *services.InvitationService) *InvitationController {
+	return &InvitationController{invitations: invitations}
+}
+
+type invitationRequest struct {
+	Email string      `json:"email" binding:"required"`
+	Role  models.Role `json:"role" binding:"required"`
+}
+
+// CreateInvitation invites a new user. The token is only returned here and
+// has to be passed to the invitee, who redeems it at /users/signup.
+func (ic *InvitationController) CreateInvitation(c *gin.Context) {
+	var req invitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	admin, _ := middlewares.CurrentUser(c)
+
+	invitation, token, err := ic.invitations.CreateInvitation(c.Request.Context(), req.Email, req.Role, admin.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
+		case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrInvalidUser):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Println("Error creating invitation:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invitation": invitation, "token": token})
+}
+
+// GetInvitations returns every invitation.
+func (ic *InvitationController) GetInvitations(c *gin.Context) {
+	invitations, err := ic.invitations.ListInvitations(c.Request.Context())
+	if err != nil {
+		log.Println("Error getting invitations:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invitations"})
+		return
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation revokes a pending invitation.
+func (ic *InvitationController) RevokeInvitation(c *gin.Context) {
+	invitation, err := ic.invitations.RevokeInvitation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+			return
+		}
+		log.Println("Error revoking invitation:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+	c.JSON(http.StatusOK, invitation)
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// KeyController publishes and rotates the access token signing keys.
+type KeyController struct {
+	keys *services.KeyService
+}
+
+// NewKeyController creates a new KeyController.
+func NewKeyController(keys *services.KeyService) *KeyController {
+	return &KeyController{keys: keys}
+}
+
+// JWKS returns the public keys that verify access tokens as a JSON Web Key
+// Set.
+func (kc *KeyController) JWKS(c *gin.Context) {
+	keys, err := kc.keys.JWKS()
+	if err != nil {
+		log.Println("Error building JWKS:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get keys"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// GetKeys returns the signing keys that still verify tokens.
+func (kc *KeyController) GetKeys(c *gin.Context) {
+	keys, err := kc.keys.ListKeys(c.Request.Context())
+	if err != nil {
+		log.Println("Error getting signing keys:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get keys"})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// RotateKeys replaces the signing key immediately, e.g. after a suspected
+// leak.
+func (kc *KeyController) RotateKeys(c *gin.Context) {
+	key, err := kc.keys.Rotate(c.Request.Context())
+	if err != nil {
+		log.Println("Error rotating signing keys:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate keys"})
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+)
+
+// bindListQuery reads the limit, cursor and sort query parameters of a list
+// endpoint. sort names one of sortFields, prefixed with "-" for descending
+// order; defaultSort is used without it.
+func bindListQuery(c *gin.Context, defaultSort string, sortFields ...string) (repositories.ListQuery, bool) {
+	query := repositories.ListQuery{Cursor: c.Query("cursor")}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return query, false
+		}
+		query.Limit = limit
+	}
+
+	sort := c.DefaultQuery("sort", defaultSort)
+	query.Sort.Desc = strings.HasPrefix(sort, "-")
+	query.Sort.Field = strings.TrimPrefix(sort, "-")
+	for _, field := range sortFields {
+		if field == query.Sort.Field {
+			return query, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort, expected one of " + strings.Join(sortFields, ", ")})
+	return query, false
+}
+
+// bindEqualFilters adds an equality filter for every given query parameter
+// that is set. params maps parameter names to fields.
+func bindEqualFilters(c *gin.Context, query *repositories.ListQuery, params map[string]string) {
+	for param, field := range params {
+		if value := c.Query(param); value != "" {
+			query.Filters = append(query.Filters, repositories.Filter{Field: field, Op: repositories.OpEq, Value: value})
+		}
+	}
+}
+
+// bindTimeRange adds filters for the from and to query parameters, in
+// RFC 3339, on a time field. to is exclusive.
+func bindTimeRange(c *gin.Context, query *repositories.ListQuery, field string) bool {
+	for param, op := range map[string]repositories.FilterOp{"from": repositories.OpGte, "to": repositories.OpLt} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + " time, expected RFC 3339"})
+			return false
+		}
+		query.Filters = append(query.Filters, repositories.Filter{Field: field, Op: op, Value: t})
+	}
+	return true
+}
+
+// listFailed responds to an error of a list query.
+func listFailed(c *gin.Context, err error, message string) {
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": repositories.ErrInvalidCursor.Error()})
+		return
+	}
+	log.Println("Error listing records:", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
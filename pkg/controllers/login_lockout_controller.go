@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// LoginLockoutController lets admins inspect and lift login lockouts.
+type LoginLockoutController struct {
+	throttle *services.LoginThrottleService
+	users    *services.UserService
+}
+
+// NewLoginLockoutController creates a new LoginLockoutController.
+func NewLoginLockoutController(throttle *services.LoginThrottleService, users *services.UserService) *LoginLockoutController {
+	return &LoginLockoutController{throttle: throttle, users: users}
+}
+
+// GetLockouts returns the accounts and IPs that are currently locked.
+func (lc *LoginLockoutController) GetLockouts(c *gin.Context) {
+	lockouts, err := lc.throttle.ListLocked(c.Request.Context())
+	if err != nil {
+		log.Println("Error getting login lockouts:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get lockouts"})
+		return
+	}
+	c.JSON(http.StatusOK, lockouts)
+}
+
+// DeleteLockout lifts a lockout by its ID, e.g. "ip:203.0.113.7".
+func (lc *LoginLockoutController) DeleteLockout(c *gin.Context) {
+	admin, _ := middlewares.CurrentUser(c)
+	if err := lc.throttle.Unlock(c.Request.Context(), c.Param("id"), admin.ID, c.ClientIP()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Lockout not found"})
+			return
+		}
+		log.Println("Error unlocking login:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UnlockUser clears the failed logins of a user.
+func (lc *LoginLockoutController) UnlockUser(c *gin.Context) {
+	user, err := lc.users.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondUserError(c, err, "Failed to unlock user")
+		return
+	}
+	admin, _ := middlewares.CurrentUser(c)
+	if err := lc.throttle.UnlockAccount(c.Request.Context(), user, admin.ID, c.ClientIP()); err != nil {
+		log.Println("Error unlocking user:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// OIDCController handles sign-in with the company identity provider.
+type OIDCController struct {
+	oidc     *services.OIDCService
+	sessions *services.SessionService
+}
+
+// NewOIDCController creates a new OIDCController.
+func NewOIDCController(oidc *services.OIDCService, sessions *services.SessionService) *OIDCController {
+	return &OIDCController{oidc: oidc, sessions: sessions}
+}
+
+// Login redirects to the identity provider.
+func (oc *OIDCController) Login(c *gin.Context) {
+	authURL, err := oc.oidc.BeginLogin(c.Request.Context())
+	if err != nil {
+		log.Println("Error starting identity provider login:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes the sign-in with the code and state the identity
+// provider sent back and returns a token pair.
+func (oc *OIDCController) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login was denied by the identity provider: " + providerError})
+		return
+	}
+
+	user, err := oc.oidc.CompleteLogin(c.Request.Context(), c.Query("state"), c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOIDCState):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired login, please try again"})
+		case errors.Is(err, services.ErrOIDCLogin):
+			log.Println("Error completing identity provider login:", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login with the identity provider failed"})
+		case errors.Is(err, services.ErrOIDCNoRole):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Your account has no access to this site"})
+		case errors.Is(err, services.ErrUserExists), errors.Is(err, repositories.ErrDuplicateKey):
+			c.JSON(http.StatusConflict, gin.H{"error": "Another account already uses this email"})
+		default:
+			log.Println("Error completing identity provider login:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		}
+		return
+	}
+	if !user.Active() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+		return
+	}
+
+	tokens, err := oc.sessions.CreateSession(c.Request.Context(), user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		log.Println("Error creating session:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// PasswordResetController handles forgotten passwords.
+type PasswordResetController struct {
+	resets *services.PasswordResetService
+}
+
+// NewPasswordResetController creates a new PasswordResetController.
+func NewPasswordResetController(resets *services.PasswordResetService) *PasswordResetController {
+	return &PasswordResetController{resets: resets}
+}
+
+type passwordResetRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// RequestReset mails a password reset link. The response is the same
+// whether or not the email belongs to an account.
+func (pc *PasswordResetController) RequestReset(c *gin.Context) {
+	var req passwordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := pc.resets.RequestReset(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrTooManyResetRequests) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many password reset requests, try again later"})
+			return
+		}
+		log.Println("Error requesting password reset:", err)
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the email belongs to an account, a reset link has been sent"})
+}
+
+type confirmPasswordResetRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ConfirmReset sets a new password using the token from the reset link.
+func (pc *PasswordResetController) ConfirmReset(c *gin.Context) {
+	var req confirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := pc.resets.ConfirmReset(c.Request.Context(), req.Token, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidResetToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		case errors.Is(err, services.ErrWeakPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Println("Error resetting password:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
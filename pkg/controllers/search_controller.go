@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// SearchController handles the site search.
+type SearchController struct {
+	search *services.SearchService
+}
+
+// NewSearchController creates a new SearchController.
+func NewSearchController(search *services.SearchService) *SearchController {
+	return &SearchController{search: search}
+}
+
+// Search returns the published blogs, videos and services matching the "q"
+// query parameter, best first. "type" restricts the search to a
+// comma-separated list of types.
+func (sc *SearchController) Search(c *gin.Context) {
+	query := services.SearchQuery{Text: c.Query("q")}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		query.Limit = limit
+	}
+	if value := c.Query("type"); value != "" {
+		for _, t := range strings.Split(value, ",") {
+			query.Types = append(query.Types, services.SearchType(strings.TrimSpace(t)))
+		}
+	}
+
+	results, err := sc.search.Search(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println("Error searching:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"query": query.Text, "count": len(results), "results": results})
+}
@@ -1,14 +1,14 @@
 package controllers
 
 import (
-	"context"
+	"errors"
 	"net/http"
 
-	"your_project_name/pkg/models"
-	"your_project_name/pkg/services"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // ServiceController handles service-related operations.
@@ -41,7 +41,7 @@ func (sc *ServiceController) CreateService(c *gin.Context) {
 		return
 	}
 
-	createdService, err := sc.ServiceService.CreateService(context.TODO(), service)
+	createdService, err := sc.ServiceService.CreateService(c.Request.Context(), service)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -57,21 +57,15 @@ func (sc *ServiceController) CreateService(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Service ID"
 // @Success 200 {object} models.Service
-// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /service/{id} [get]
 func (sc *ServiceController) GetService(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := primitive.ObjectIDFromHex(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
-	}
+	id := c.Param("id")
 
-	service, err := sc.ServiceService.GetService(context.TODO(), id)
+	service, err := sc.ServiceService.GetService(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "mongo: no documents in result" {
+		if errors.Is(err, repositories.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -82,22 +76,33 @@ func (sc *ServiceController) GetService(c *gin.Context) {
 	c.JSON(http.StatusOK, service)
 }
 
-// GetAllServices retrieves all services.
+// GetAllServices retrieves a page of services.
 // @Summary Get all services
-// @Description Get all services.
+// @Description Get a page of services, optionally filtered by location.
 // @Tags services
 // @Produce json
-// @Success 200 {array} models.Service
+// @Param location query string false "Location"
+// @Param sort query string false "name (default) or location, prefixed with - for descending order"
+// @Param limit query int false "Page size, at most 100"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} repositories.Page[models.Service]
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /service [get]
 func (sc *ServiceController) GetAllServices(c *gin.Context) {
-	services, err := sc.ServiceService.GetAllServices(context.TODO())
+	query, ok := bindListQuery(c, "name", "name", "location")
+	if !ok {
+		return
+	}
+	bindEqualFilters(c, &query, map[string]string{"location": "location"})
+
+	page, err := sc.ServiceService.GetAllServices(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		listFailed(c, err, "Failed to get services")
 		return
 	}
 
-	c.JSON(http.StatusOK, services)
+	c.JSON(http.StatusOK, page)
 }
 
 // UpdateService updates an existing service.
@@ -114,12 +119,7 @@ func (sc *ServiceController) GetAllServices(c *gin.Context) {
 // @Failure 500 {object} map[string]string
 // @Router /service/{id} [put]
 func (sc *ServiceController) UpdateService(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := primitive.ObjectIDFromHex(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
-	}
+	id := c.Param("id")
 
 	var service models.Service
 	if err := c.ShouldBindJSON(&service); err != nil {
@@ -128,9 +128,9 @@ func (sc *ServiceController) UpdateService(c *gin.Context) {
 	}
 	service.ID = id
 
-	updatedService, err := sc.ServiceService.UpdateService(context.TODO(), service)
+	updatedService, err := sc.ServiceService.UpdateService(c.Request.Context(), service)
 	if err != nil {
-		if err.Error() == "mongo: no documents in result" {
+		if errors.Is(err, repositories.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -148,21 +148,15 @@ func (sc *ServiceController) UpdateService(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Service ID"
 // @Success 204
-// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /service/{id} [delete]
 func (sc *ServiceController) DeleteService(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := primitive.ObjectIDFromHex(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
-	}
+	id := c.Param("id")
 
-	err = sc.ServiceService.DeleteService(context.TODO(), id)
+	err := sc.ServiceService.DeleteService(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "mongo: no documents in result" {
+		if errors.Is(err, repositories.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -171,4 +165,4 @@ func (sc *ServiceController) DeleteService(c *gin.Context) {
 	}
 
 	c.Status(http.StatusNoContent)
-}
\ No newline at end of file
+}
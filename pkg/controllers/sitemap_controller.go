@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// SitemapController serves the sitemap and robots.txt.
+type SitemapController struct {
+	sitemaps *services.SitemapService
+	origin   string
+	disallow []string
+}
+
+// NewSitemapController creates a new SitemapController. origin is the public
+// URL of this server, which the sitemap index and robots.txt point to.
+// robots.txt asks crawlers to skip the disallow path prefixes.
+func NewSitemapController(sitemaps *services.SitemapService, origin string, disallow []string) *SitemapController {
+	return &SitemapController{sitemaps: sitemaps, origin: strings.TrimSuffix(origin, "/"), disallow: disallow}
+}
+
+// GetSitemap returns the sitemap of the site. Beyond utils.MaxSitemapURLs
+// URLs it returns a sitemap index of the parts served by GetSitemapPart.
+func (sc *SitemapController) GetSitemap(c *gin.Context) {
+	entries, ok := sc.entries(c)
+	if !ok {
+		return
+	}
+	if len(entries) <= utils.MaxSitemapURLs {
+		sc.write(c, entries, utils.EncodeSitemap)
+		return
+	}
+
+	var parts []utils.SitemapEntry
+	for i := 0; i*utils.MaxSitemapURLs < len(entries); i++ {
+		parts = append(parts, utils.SitemapEntry{
+			Loc:     fmt.Sprintf("%s/sitemaps/%d.xml", sc.origin, i+1),
+			LastMod: lastModified(sitemapPart(entries, i+1)),
+		})
+	}
+	sc.write(c, parts, utils.EncodeSitemapIndex)
+}
+
+// GetSitemapPart returns a part of a sitemap split by GetSitemap, given by
+// the "file" path parameter "<number>.xml".
+func (sc *SitemapController) GetSitemapPart(c *gin.Context) {
+	number, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".xml"))
+	if err != nil || !strings.HasSuffix(c.Param("file"), ".xml") || number < 1 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sitemap not found"})
+		return
+	}
+	entries, ok := sc.entries(c)
+	if !ok {
+		return
+	}
+	part := sitemapPart(entries, number)
+	if len(part) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sitemap not found"})
+		return
+	}
+	sc.write(c, part, utils.EncodeSitemap)
+}
+
+// GetRobots returns robots.txt, pointing crawlers to the sitemap.
+func (sc *SitemapController) GetRobots(c *gin.Context) {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(sc.disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range sc.disallow {
+		fmt.Fprintf(&b, "Disallow: %s\n", path)
+	}
+	fmt.Fprintf(&b, "\nSitemap: %s/sitemap.xml\n", sc.origin)
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(b.String()))
+}
+
+func (sc *SitemapController) entries(c *gin.Context) ([]utils.SitemapEntry, bool) {
+	entries, err := sc.sitemaps.Entries(c.Request.Context())
+	if err != nil {
+		log.Println("Error building sitemap:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sitemap"})
+		return nil, false
+	}
+	return entries, true
+}
+
+func (sc *SitemapController) write(c *gin.Context, entries []utils.SitemapEntry, encode func([]utils.SitemapEntry) ([]byte, error)) {
+	body, err := encode(entries)
+	if err != nil {
+		log.Println("Error encoding sitemap:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sitemap"})
+		return
+	}
+	writeConditional(c, "application/xml; charset=utf-8", body, lastModified(entries))
+}
+
+// sitemapPart returns the entries of the numbered part, starting at 1, of a
+// sitemap split into parts of utils.MaxSitemapURLs.
+func sitemapPart(entries []utils.SitemapEntry, number int) []utils.SitemapEntry {
+	start := (number - 1) * utils.MaxSitemapURLs
+	if start >= len(entries) {
+		return nil
+	}
+	end := start + utils.MaxSitemapURLs
+	if end > len(entries) {
+		end = len(entries)
+	}
+	return entries[start:end]
+}
+
+// lastModified returns the latest lastmod of the entries.
+func lastModified(entries []utils.SitemapEntry) time.Time {
+	var latest time.Time
+	for _, entry := range entries {
+		if entry.LastMod.After(latest) {
+			latest = entry.LastMod
+		}
+	}
+	return latest
+}
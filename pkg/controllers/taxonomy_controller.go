@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// TaxonomyController handles blog tags and categories.
+type TaxonomyController struct {
+	taxonomy *services.TaxonomyService
+}
+
+// NewTaxonomyController creates a new TaxonomyController.
+func NewTaxonomyController(taxonomy *services.TaxonomyService) *TaxonomyController {
+	return &TaxonomyController{taxonomy: taxonomy}
+}
+
+// GetTags returns every tag with the number of published blogs using it.
+func (tc *TaxonomyController) GetTags(c *gin.Context) {
+	tags, err := tc.taxonomy.ListTags(c.Request.Context())
+	if err != nil {
+		log.Println("Error getting tags:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tags"})
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// tagBlogsPage is a tag with a page of its published blogs.
+type tagBlogsPage struct {
+	Tag *models.Tag `json:"tag"`
+	repositories.Page[models.Blog]
+}
+
+// GetTagBlogs returns a tag and a page of its published blogs, sorted like
+// the blog list.
+func (tc *TaxonomyController) GetTagBlogs(c *gin.Context) {
+	query, ok := bindListQuery(c, "-created_at", "created_at", "updated_at", "title")
+	if !ok {
+		return
+	}
+	tag, page, err := tc.taxonomy.BlogsByTag(c.Request.Context(), c.Param("slug"), query)
+	if err != nil {
+		tc.fail(c, err, "Tag not found", "Failed to get tag")
+		return
+	}
+	c.JSON(http.StatusOK, tagBlogsPage{Tag: tag, Page: *page})
+}
+
+type renameTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// RenameTag changes the name and slug of a tag.
+func (tc *TaxonomyController) RenameTag(c *gin.Context) {
+	var req renameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tag, err := tc.taxonomy.RenameTag(c.Request.Context(), c.Param("id"), req.Name)
+	if err != nil {
+		tc.fail(c, err, "Tag not found", "Failed to rename tag")
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+type mergeTagRequest struct {
+	Into string `json:"into" binding:"required"`
+}
+
+// MergeTag moves the blogs of a tag to the tag given by "into" and deletes
+// the first tag.
+func (tc *TaxonomyController) MergeTag(c *gin.Context) {
+	var req mergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tag, err := tc.taxonomy.MergeTags(c.Request.Context(), c.Param("id"), req.Into)
+	if err != nil {
+		tc.fail(c, err, "Tag not found", "Failed to merge tags")
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// GetCategories returns every category with the number of published blogs
+// in it or its subcategories.
+func (tc *TaxonomyController) GetCategories(c *gin.Context) {
+	categories, err := tc.taxonomy.ListCategories(c.Request.Context())
+	if err != nil {
+		log.Println("Error getting categories:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get categories"})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// categoryBlogsPage is a category with a page of its published blogs.
+type categoryBlogsPage struct {
+	Category *models.Category `json:"category"`
+	repositories.Page[models.Blog]
+}
+
+// GetCategoryBlogs returns a category and a page of the published blogs in
+// it or its subcategories, sorted like the blog list.
+func (tc *TaxonomyController) GetCategoryBlogs(c *gin.Context) {
+	query, ok := bindListQuery(c, "-created_at", "created_at", "updated_at", "title")
+	if !ok {
+		return
+	}
+	category, page, err := tc.taxonomy.BlogsByCategory(c.Request.Context(), c.Param("slug"), query)
+	if err != nil {
+		tc.fail(c, err, "Category not found", "Failed to get category")
+		return
+	}
+	c.JSON(http.StatusOK, categoryBlogsPage{Category: category, Page: *page})
+}
+
+// CreateCategory creates a category.
+func (tc *TaxonomyController) CreateCategory(c *gin.Context) {
+	var req services.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	category, err := tc.taxonomy.CreateCategory(c.Request.Context(), req)
+	if err != nil {
+		tc.fail(c, err, "Category not found", "Failed to create category")
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory changes a category.
+func (tc *TaxonomyController) UpdateCategory(c *gin.Context) {
+	var req services.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	category, err := tc.taxonomy.UpdateCategory(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		tc.fail(c, err, "Category not found", "Failed to update category")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory deletes a category without subcategories.
+func (tc *TaxonomyController) DeleteCategory(c *gin.Context) {
+	if err := tc.taxonomy.DeleteCategory(c.Request.Context(), c.Param("id")); err != nil {
+		tc.fail(c, err, "Category not found", "Failed to delete category")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (tc *TaxonomyController) fail(c *gin.Context, err error, notFound, message string) {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, services.ErrInvalidTaxonomy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Println("Error handling taxonomy:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+)
+
+// TwoFactorController handles TOTP enrollment and the second login step.
+type TwoFactorController struct {
+	twoFactor *services.TwoFactorService
+	sessions  *services.SessionService
+}
+
+// NewTwoFactorController creates a new TwoFactorController.
+func NewTwoFactorController(twoFactor *services.TwoFactorService, sessions *services.SessionService) *TwoFactorController {
+	return &TwoFactorController{twoFactor: twoFactor, sessions: sessions}
+}
+
+type totpCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type disableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type verifyLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// BeginEnrollment returns a new TOTP secret and its provisioning URI for
+// the logged in user.
+func (tc *TwoFactorController) BeginEnrollment(c *gin.Context) {
+	user, _ := middlewares.CurrentUser(c)
+	enrollment, err := tc.twoFactor.BeginEnrollment(c.Request.Context(), user.ID)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to start two-factor enrollment")
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmEnrollment enables two-factor authentication and returns the
+// recovery codes, which are only shown once.
+func (tc *TwoFactorController) ConfirmEnrollment(c *gin.Context) {
+	var req totpCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, _ := middlewares.CurrentUser(c)
+
+	codes, err := tc.twoFactor.ConfirmEnrollment(c.Request.Context(), user.ID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Disable turns off two-factor authentication for the logged in user.
+func (tc *TwoFactorController) Disable(c *gin.Context) {
+	var req disableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, _ := middlewares.CurrentUser(c)
+
+	if err := tc.twoFactor.Disable(c.Request.Context(), user.ID, req.Password, req.Code); err != nil {
+		respondTwoFactorError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the logged in user.
+func (tc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req totpCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, _ := middlewares.CurrentUser(c)
+
+	codes, err := tc.twoFactor.RegenerateRecoveryCodes(c.Request.Context(), user.ID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// ResetUser turns off two-factor authentication for another user.
+func (tc *TwoFactorController) ResetUser(c *gin.Context) {
+	user, err := tc.twoFactor.ResetForUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to reset two-factor authentication")
+		return
+	}
+	c.JSON(http.StatusOK, user.Sanitized())
+}
+
+// VerifyLogin completes a login that returned a challenge token and starts
+// the session.
+func (tc *TwoFactorController) VerifyLogin(c *gin.Context) {
+	var req verifyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := tc.twoFactor.VerifyChallenge(c.Request.Context(), req.ChallengeToken, req.Code, c.ClientIP())
+	if err != nil {
+		var throttled *services.ThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.Wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidChallenge) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login challenge is invalid or expired"})
+			return
+		}
+		respondTwoFactorError(c, err, "Failed to verify login")
+		return
+	}
+
+	tokens, err := tc.sessions.CreateSession(c.Request.Context(), user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		log.Println("Error creating session:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+func respondTwoFactorError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+	case errors.Is(err, services.ErrTOTPAlreadyEnabled), errors.Is(err, services.ErrTOTPNotEnabled), errors.Is(err, services.ErrTOTPNotStarted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondUserError(c, err, message)
+	}
+}
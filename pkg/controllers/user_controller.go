@@ -1,111 +1,357 @@
 package controllers
 
 import (
-	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
+	"math"
 	"net/http"
-	"os"
-	"context"
+	"strconv"
 
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/gin-gonic/gin"
 
-	"your_project_name/pkg/models"
-	"your_project_name/pkg/utils"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
 )
 
-var ctx = context.TODO()
-
 // UserController handles user-related operations.
 type UserController struct {
-	collection *mongo.Collection
+	users       *services.UserService
+	invitations *services.InvitationService
+	sessions    *services.SessionService
+	twoFactor   *services.TwoFactorService
+	throttle    *services.LoginThrottleService
 }
-// NewUserController is a constructor for user controller
+
 // NewUserController creates a new UserController instance.
-func NewUserController(client *mongo.Client, dbName, collectionName string) *UserController {
-	collection := client.Database(dbName).Collection(collectionName)
-	return &UserController{collection: collection}
+func NewUserController(users *services.UserService, invitations *services.InvitationService, sessions *services.SessionService, twoFactor *services.TwoFactorService, throttle *services.LoginThrottleService) *UserController {
+	return &UserController{users: users, invitations: invitations, sessions: sessions, twoFactor: twoFactor, throttle: throttle}
 }
 
-// CreateUser creates a new user.
-// Signup creates a new user.
-func (uc *UserController) Signup(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	var user models.User
-	_ = json.NewDecoder(r.Body).Decode(&user)
+type signupRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
 
-	// check if the user is admin to be able to create user
-	adminId := os.Getenv("ADMIN_ID")
-	if user.ID != adminId {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+// Signup creates a new user from an invitation.
+func (uc *UserController) Signup(c *gin.Context) {
+	var req signupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	
-	// Hash the password before storing it
-	hashedPassword, err := utils.HashPassword(user.Password)
+
+	user, err := uc.invitations.AcceptInvitation(c.Request.Context(), req.Token, req.Name, req.Password)
 	if err != nil {
-		http.Error(w, "Failed to hash password", http.StatusInternalServerError)
+		if errors.Is(err, services.ErrInvalidInvitation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invitation"})
+			return
+		}
+		if errors.Is(err, services.ErrUserExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
+			return
+		}
+		respondUserError(c, err, "Failed to create user")
 		return
 	}
-	user.Password = hashedPassword
-	user.IsAdmin = false
 
-	//check if the user with the same email exists
-	existingUser := models.User{}
-	err = uc.collection.FindOne(ctx, bson.M{"email": user.Email}).Decode(&existingUser)
-	if err == nil {
-		http.Error(w, "User with this email already exists", http.StatusBadRequest)
+	c.JSON(http.StatusCreated, user.Sanitized())
+}
+
+// Login handles user login.
+func (uc *UserController) Login(c *gin.Context) {
+	//parse the request body
+	var loginUser models.LoginUser
+	if err := c.ShouldBindJSON(&loginUser); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
-	} else if err != mongo.ErrNoDocuments{
-		log.Println("Error getting user:", err)
-		http.Error(w, "Error creating user", http.StatusInternalServerError)
 	}
 
-	_, err := uc.collection.InsertOne(ctx, user)
+	//slow down repeated failures for the account and the client
+	wait, err := uc.throttle.Check(c.Request.Context(), loginUser.Email, c.ClientIP())
 	if err != nil {
-		log.Println("Error creating user:", err)
-		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		log.Println("Error checking login throttle:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
 		return
 	}
-
-	json.NewEncoder(w).Encode(user)
-}
-// Login handles user login.
-func (uc *UserController) Login(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	//parse the request body
-	var loginUser models.LoginUser
-	_ = json.NewDecoder(r.Body).Decode(&loginUser)
 
 	//fetch the user from database
-	var user models.User
-	err := uc.collection.FindOne(ctx, bson.M{"email": loginUser.Email}).Decode(&user)
+	user, err := uc.users.Users.FindByEmail(c.Request.Context(), loginUser.Email)
 	if err != nil {
 		log.Println("Error getting user:", err)
-		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+		uc.loginFailed(c, loginUser.Email, "")
 		return
 	}
 	//compare the hash password
-	err = utils.ComparePassword(user.Password, loginUser.Password)
+	if !utils.CompareHashAndPassword(loginUser.Password, user.Password) {
+		uc.loginFailed(c, loginUser.Email, user.ID)
+		return
+	}
+	if !user.Active() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
+		return
+	}
+	//ask for the second factor before starting a session; failed logins
+	//are only cleared once it is verified
+	if user.TOTPEnabled {
+		challenge, err := uc.twoFactor.CreateChallenge(c.Request.Context(), user.ID)
+		if err != nil {
+			log.Println("Error creating login challenge:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+	if err := uc.throttle.RecordSuccess(c.Request.Context(), loginUser.Email); err != nil {
+		log.Println("Error resetting login throttle:", err)
+	}
+	//start a session and generate the tokens
+	tokens, err := uc.sessions.CreateSession(c.Request.Context(), user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		log.Println("Error creating session:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// loginFailed counts a failed login and responds with 401.
+func (uc *UserController) loginFailed(c *gin.Context, email, userID string) {
+	if err := uc.throttle.RecordFailure(c.Request.Context(), email, c.ClientIP(), userID); err != nil {
+		log.Println("Error recording login failure:", err)
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+func (uc *UserController) Refresh(c *gin.Context) {
+	var req refreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := uc.sessions.Refresh(c.Request.Context(), req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		log.Println("Error refreshing session:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the session of a refresh token.
+func (uc *UserController) Logout(c *gin.Context) {
+	var req refreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := uc.sessions.Logout(c.Request.Context(), req.RefreshToken)
+	if err != nil && !errors.Is(err, services.ErrInvalidRefreshToken) && !errors.Is(err, services.ErrRefreshTokenReused) {
+		log.Println("Error revoking session:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetUsers returns every user.
+func (uc *UserController) GetUsers(c *gin.Context) {
+	users, err := uc.users.ListUsers(c.Request.Context())
+	if err != nil {
+		log.Println("Error getting users:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get users"})
+		return
+	}
+	for i := range users {
+		users[i] = users[i].Sanitized()
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// GetUser returns a user by ID.
+func (uc *UserController) GetUser(c *gin.Context) {
+	user, err := uc.users.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondUserError(c, err, "Failed to get user")
+		return
+	}
+	c.JSON(http.StatusOK, user.Sanitized())
+}
+
+// UpdateUser changes the name and email of a user.
+func (uc *UserController) UpdateUser(c *gin.Context) {
+	var update services.UserUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := uc.users.UpdateUser(c.Request.Context(), c.Param("id"), update)
 	if err != nil {
-		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+		respondUserError(c, err, "Failed to update user")
+		return
+	}
+	c.JSON(http.StatusOK, user.Sanitized())
+}
+
+type userRoleRequest struct {
+	Role models.Role `json:"role" binding:"required"`
+}
+
+// UpdateUserRole changes the role of a user.
+func (uc *UserController) UpdateUserRole(c *gin.Context) {
+	var req userRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	//generate jwt token
-	token, err := utils.CreateToken(user.ID)
+
+	user, err := uc.users.SetRole(c.Request.Context(), c.Param("id"), req.Role)
 	if err != nil {
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		respondUserError(c, err, "Failed to update role")
 		return
 	}
-	response := struct {
-		Token string `json:"token"`
-	}{
-		Token: token,
+	c.JSON(http.StatusOK, user.Sanitized())
+}
+
+// DeactivateUser blocks a user from logging in.
+func (uc *UserController) DeactivateUser(c *gin.Context) {
+	user, err := uc.users.DeactivateUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondUserError(c, err, "Failed to deactivate user")
+		return
+	}
+	c.JSON(http.StatusOK, user.Sanitized())
+}
+
+// ActivateUser reactivates a deactivated user.
+func (uc *UserController) ActivateUser(c *gin.Context) {
+	user, err := uc.users.ActivateUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondUserError(c, err, "Failed to activate user")
+		return
 	}
+	c.JSON(http.StatusOK, user.Sanitized())
+}
+
+// DeleteUser deletes a user.
+func (uc *UserController) DeleteUser(c *gin.Context) {
+	if err := uc.users.DeleteUser(c.Request.Context(), c.Param("id")); err != nil {
+		respondUserError(c, err, "Failed to delete user")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetMe returns the logged in user.
+func (uc *UserController) GetMe(c *gin.Context) {
+	user, ok := middlewares.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	c.JSON(http.StatusOK, user.Sanitized())
+}
 
-	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+type updateMeRequest struct {
+	services.UserUpdate
+	// CurrentPassword is required to change the email.
+	CurrentPassword string `json:"current_password"`
+}
+
+// UpdateMe changes the name and email of the logged in user. Changing the
+// email needs the current password.
+func (uc *UserController) UpdateMe(c *gin.Context) {
+	current, ok := middlewares.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	var req updateMeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := uc.users.UpdateProfile(c.Request.Context(), current.ID, req.UserUpdate, req.CurrentPassword)
+	if err != nil {
+		respondUserError(c, err, "Failed to update profile")
+		return
+	}
+	c.JSON(http.StatusOK, user.Sanitized())
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ChangePassword changes the password of the logged in user. All sessions
+// are revoked and a new token pair is returned for the caller.
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	current, ok := middlewares.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := uc.users.ChangePassword(c.Request.Context(), current.ID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		respondUserError(c, err, "Failed to change password")
+		return
+	}
+
+	tokens, err := uc.sessions.CreateSession(c.Request.Context(), current.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		log.Println("Error creating session:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+func respondUserError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, repositories.ErrDuplicateKey):
+		c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
+	case errors.Is(err, services.ErrIncorrectPassword):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+	case errors.Is(err, services.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrInvalidUser), errors.Is(err, services.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Println(message+":", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -1,197 +1,213 @@
 package controllers
 
 import (
-	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
-	"strconv"
-	"time"
-
-	"github.com/gorilla/mux"
-	"yourapp/pkg/middlewares"
-	"yourapp/pkg/models"
-	"yourapp/pkg/services"
-	"yourapp/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
 )
 
 type VideoController struct {
 	VideoService *services.VideoService
-	S3Utils      *utils.S3Utils
-	DBUtils      *utils.DBUtils
 }
 
-func NewVideoController(videoService *services.VideoService, s3Utils *utils.S3Utils, dbUtils *utils.DBUtils) *VideoController {
+func NewVideoController(videoService *services.VideoService) *VideoController {
 	return &VideoController{
 		VideoService: videoService,
-		S3Utils:      s3Utils,
-		DBUtils:      dbUtils,
 	}
 }
 
-func (vc *VideoController) CreateVideo(w http.ResponseWriter, r *http.Request) {
-	middlewares.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var video models.Video
-		err := json.NewDecoder(r.Body).Decode(&video)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+// CreateVideo expects a multipart form with the video details and a "video" file.
+func (vc *VideoController) CreateVideo(c *gin.Context) {
+	fileHeader, err := c.FormFile("video")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "video file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read video file"})
+		return
+	}
+	defer file.Close()
+
+	video := videoFromForm(c)
+	err = vc.VideoService.CreateVideo(c.Request.Context(), &video, file, fileHeader.Filename)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedFileType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		video.CreatedAt = time.Now()
-		video.UpdatedAt = time.Now()
+	c.JSON(http.StatusCreated, video)
+}
 
-		err = vc.VideoService.CreateVideo(&video)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+func (vc *VideoController) DeleteVideo(c *gin.Context) {
+	err := vc.VideoService.DeleteVideo(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "video not found"})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(video)
-	})).ServeHTTP(w, r)
+	c.Status(http.StatusNoContent)
 }
 
-func (vc *VideoController) DeleteVideo(w http.ResponseWriter, r *http.Request) {
-	middlewares.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		params := mux.Vars(r)
-		id, err := strconv.Atoi(params["id"])
+// UpdateVideo expects a multipart form with the video details and an
+// optional "video" file replacing the current one.
+func (vc *VideoController) UpdateVideo(c *gin.Context) {
+	var file io.Reader
+	var filename string
+	fileHeader, err := c.FormFile("video")
+	if err != nil && !errors.Is(err, http.ErrMissingFile) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read video file"})
+		return
+	}
+	if fileHeader != nil {
+		var f multipart.File
+		f, err = fileHeader.Open()
 		if err != nil {
-			http.Error(w, "Invalid video ID", http.StatusBadRequest)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read video file"})
 			return
 		}
+		defer f.Close()
+		file = f
+		filename = fileHeader.Filename
+	}
 
-		err = vc.VideoService.DeleteVideo(id)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	video := videoFromForm(c)
+	updated, err := vc.VideoService.UpdateVideo(c.Request.Context(), c.Param("id"), &video, file, filename)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "video not found"})
 			return
 		}
-
-		w.WriteHeader(http.StatusNoContent)
-	})).ServeHTTP(w, r)
-}
-
-func (vc *VideoController) UpdateVideo(w http.ResponseWriter, r *http.Request) {
-	middlewares.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		params := mux.Vars(r)
-		id, err := strconv.Atoi(params["id"])
-		if err != nil {
-			http.Error(w, "Invalid video ID", http.StatusBadRequest)
+		if errors.Is(err, services.ErrUnsupportedFileType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		var video models.Video
-		err = json.NewDecoder(r.Body).Decode(&video)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		video.ID = id
-		video.UpdatedAt = time.Now()
+	c.JSON(http.StatusOK, updated)
+}
 
-		err = vc.VideoService.UpdateVideo(&video)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+func (vc *VideoController) GetVideo(c *gin.Context) {
+	video, err := vc.VideoService.GetVideo(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "video not found"})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		json.NewEncoder(w).Encode(video)
-	})).ServeHTTP(w, r)
+	c.JSON(http.StatusOK, video)
 }
 
-func (vc *VideoController) GetVideo(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id, err := strconv.Atoi(params["id"])
-	if err != nil {
-		http.Error(w, "Invalid video ID", http.StatusBadRequest)
+// GetAllPublicVideos returns a page of videos, optionally filtered by
+// category and a from/to range of the creation time. They are sorted by
+// created_at (the default, newest first) or title.
+func (vc *VideoController) GetAllPublicVideos(c *gin.Context) {
+	query, ok := bindListQuery(c, "-created_at", "created_at", "title")
+	if !ok {
+		return
+	}
+	bindEqualFilters(c, &query, map[string]string{"category": "category"})
+	if !bindTimeRange(c, &query, "created_at") {
 		return
 	}
 
-	video, err := vc.VideoService.GetVideo(id)
+	page, err := vc.VideoService.GetAllPublicVideos(c.Request.Context(), query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		listFailed(c, err, "failed to retrieve videos")
 		return
 	}
-
-	json.NewEncoder(w).Encode(video)
+	c.JSON(http.StatusOK, page)
 }
 
-func (vc *VideoController) GetAllPublicVideos(w http.ResponseWriter, r *http.Request) {
-	videos, err := vc.VideoService.GetAllPublicVideos()
+// StartDirectUpload returns presigned URLs for uploading a video file
+// straight to the bucket.
+func (vc *VideoController) StartDirectUpload(c *gin.Context) {
+	var req services.DirectUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	upload, err := vc.VideoService.StartDirectUpload(c.Request.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondDirectUploadError(c, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(videos)
+	c.JSON(http.StatusCreated, upload)
 }
 
-func (vc *VideoController) RegisterVideoRoutes(router *mux.Router) {
-	router.HandleFunc("/videos", vc.CreateVideo).Methods("POST")
-	router.HandleFunc("/videos/{id}", vc.DeleteVideo).Methods("DELETE")
-	router.HandleFunc("/videos/{id}", vc.UpdateVideo).Methods("PUT")
-	router.HandleFunc("/videos/{id}", vc.GetVideo).Methods("GET")
-	router.HandleFunc("/videos", vc.GetAllPublicVideos).Methods("GET")
-}
-```
-```go
-package services
+// CompleteDirectUpload verifies the uploaded file and creates the video.
+func (vc *VideoController) CompleteDirectUpload(c *gin.Context) {
+	var req services.CompleteDirectUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-import (
-	"fmt"
-	"yourapp/pkg/models"
-	"yourapp/pkg/utils"
-)
+	video, err := vc.VideoService.CompleteDirectUpload(c.Request.Context(), req)
+	if err != nil {
+		respondDirectUploadError(c, err)
+		return
+	}
 
-type VideoService struct {
-	DBUtils *utils.DBUtils
+	c.JSON(http.StatusCreated, video)
 }
 
-func NewVideoService(dbUtils *utils.DBUtils) *VideoService {
-	return &VideoService{
-		DBUtils: dbUtils,
+// AbortDirectUpload cancels an unfinished multipart upload.
+func (vc *VideoController) AbortDirectUpload(c *gin.Context) {
+	var req services.AbortDirectUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-}
 
-func (vs *VideoService) CreateVideo(video *models.Video) error {
-	result := vs.DBUtils.DB.Create(video)
-	if result.Error != nil {
-		return fmt.Errorf("failed to create video: %w", result.Error)
+	if err := vc.VideoService.AbortDirectUpload(c.Request.Context(), req); err != nil {
+		respondDirectUploadError(c, err)
+		return
 	}
-	return nil
-}
 
-func (vs *VideoService) DeleteVideo(id int) error {
-	result := vs.DBUtils.DB.Delete(&models.Video{}, id)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete video: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("video not found with ID %d", id)
-	}
-	return nil
+	c.Status(http.StatusNoContent)
 }
 
-func (vs *VideoService) UpdateVideo(video *models.Video) error {
-	result := vs.DBUtils.DB.Save(video)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update video: %w", result.Error)
+func respondDirectUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidUpload), errors.Is(err, services.ErrUnsupportedFileType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadNotFound):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDirectUploadNotSupported), errors.Is(err, services.ErrStorageNotConfigured):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
-	return nil
 }
 
-func (vs *VideoService) GetVideo(id int) (*models.Video, error) {
-	var video models.Video
-	result := vs.DBUtils.DB.First(&video, id)
-	if result.Error != nil {
-		return nil, fmt.Errorf("failed to get video: %w", result.Error)
+func videoFromForm(c *gin.Context) models.Video {
+	return models.Video{
+		Category: c.PostForm("category"),
+		Title:    c.PostForm("title"),
+		Content:  c.PostForm("content"),
 	}
-	return &video, nil
 }
-
-func (vs *VideoService) GetAllPublicVideos() ([]models.Video, error) {
-	var videos []models.Video
-	result := vs.DBUtils.DB.Find(&videos)
-	if result.Error != nil {
-		return nil, fmt.Errorf("failed to get videos: %w", result.Error)
-	}
-	return videos, nil
-}
\ No newline at end of file
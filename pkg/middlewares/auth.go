@@ -1,22 +1,29 @@
 package middlewares
 
 import (
-	"context"
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 
-	"yourapp/pkg/models"
-	"yourapp/pkg/utils"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/services"
 )
 
-func AuthMiddleware(db *mongo.Database) gin.HandlerFunc {
+// AuthMiddleware authenticates the bearer token and stores the user in the
+// context. Machine clients may send an API key instead, either as the bearer
+// token or in the X-API-Key header; the request then acts for the user who
+// created the key, limited to its scopes. Routes check what the user may do
+// with RequirePermission.
+func AuthMiddleware(users repositories.UserRepository, sessions *services.SessionService, apiKeys *services.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			authenticateAPIKey(c, apiKeys, apiKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is missing"})
@@ -30,31 +37,94 @@ func AuthMiddleware(db *mongo.Database) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if services.IsAPIKey(tokenString) {
+			authenticateAPIKey(c, apiKeys, tokenString)
+			return
+		}
 
-		userID, err := utils.GetUserIDFromToken(tokenString, os.Getenv("JWT_SECRET"))
-		if err != nil {
+		claims, err := sessions.VerifyAccessToken(tokenString)
+		if err != nil || claims.SessionID == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		var user models.User
-		collection := db.Collection("users")
-		err = collection.FindOne(context.TODO(), bson.M{"_id": userID}).Decode(&user)
+		if err := sessions.ValidateSession(c.Request.Context(), claims.SessionID); err != nil {
+			if errors.Is(err, services.ErrSessionRevoked) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate session"})
+			}
+			c.Abort()
+			return
+		}
+
+		user, err := users.FindByID(c.Request.Context(), claims.UserID)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			c.Abort()
 			return
 		}
 
-		if !user.IsAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized: Admin access required"})
+		if !user.Active() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is deactivated"})
 			c.Abort()
 			return
 		}
-		fmt.Println("admin user found")
 
-		c.Set("user", user)
+		c.Set("user", *user)
+		c.Set("session_id", claims.SessionID)
+		setAuditActor(c, services.AuditActor{UserID: user.ID})
+		c.Next()
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, apiKeys *services.APIKeyService, token string) {
+	key, user, err := apiKeys.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate API key"})
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set("user", *user)
+	c.Set("api_key", *key)
+	setAuditActor(c, services.AuditActor{UserID: user.ID, APIKeyID: key.ID})
+	c.Next()
+}
+
+// setAuditActor attributes the changes made by the request to the
+// authenticated user.
+func setAuditActor(c *gin.Context, actor services.AuditActor) {
+	actor.IP = c.ClientIP()
+	c.Request = c.Request.WithContext(services.WithAuditActor(c.Request.Context(), actor))
+}
+
+// CurrentAPIKey returns the API key the request was authenticated with, if
+// any.
+func CurrentAPIKey(c *gin.Context) (models.APIKey, bool) {
+	value, ok := c.Get("api_key")
+	if !ok {
+		return models.APIKey{}, false
+	}
+	key, ok := value.(models.APIKey)
+	return key, ok
+}
+
+// RequireSession aborts requests authenticated with an API key. It guards
+// routes that manage the caller's own account. It must run after
+// AuthMiddleware.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentAPIKey(c); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: not available to API keys"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// CurrentUser returns the user stored in the context by AuthMiddleware.
+func CurrentUser(c *gin.Context) (models.User, bool) {
+	value, ok := c.Get("user")
+	if !ok {
+		return models.User{}, false
+	}
+	user, ok := value.(models.User)
+	return user, ok
+}
+
+// Can reports whether the current request may use a permission. Requests
+// authenticated with an API key also need the permission among its scopes.
+func Can(c *gin.Context, permission models.Permission) bool {
+	user, ok := CurrentUser(c)
+	if !ok || !user.Can(permission) {
+		return false
+	}
+	if key, ok := CurrentAPIKey(c); ok {
+		return key.HasScope(permission)
+	}
+	return true
+}
+
+// RequirePermission aborts the request unless the authenticated user's role
+// grants the permission. It must run after AuthMiddleware.
+func RequirePermission(permission models.Permission) gin.HandlerFunc {
+	return RequireAnyPermission(permission)
+}
+
+// RequireAnyPermission aborts the request unless the authenticated user's
+// role grants at least one of the permissions. Handlers use it for actions
+// that are allowed either on any record or only on the user's own records,
+// and check ownership themselves.
+func RequireAnyPermission(permissions ...models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentUser(c); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+		for _, permission := range permissions {
+			if Can(c, permission) {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: missing permission"})
+		c.Abort()
+	}
+}
@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func TestCanWithAPIKeyScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		role       models.Role
+		scopes     []models.Permission
+		apiKey     bool
+		permission models.Permission
+		want       bool
+	}{
+		{name: "session with role permission", role: models.RoleEditor, permission: models.PermBlogsCreate, want: true},
+		{name: "session without role permission", role: models.RoleEditor, permission: models.PermUsersManage},
+		{name: "key with scope", role: models.RoleEditor, apiKey: true, scopes: []models.Permission{models.PermBlogsCreate}, permission: models.PermBlogsCreate, want: true},
+		{name: "key without scope", role: models.RoleEditor, apiKey: true, scopes: []models.Permission{models.PermBlogsCreate}, permission: models.PermBlogsDelete},
+		{name: "key with scope the role lacks", role: models.RoleEditor, apiKey: true, scopes: []models.Permission{models.PermUsersManage}, permission: models.PermUsersManage},
+		{name: "key without scopes", role: models.RoleAdmin, apiKey: true, permission: models.PermBlogsCreate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Set("user", models.User{ID: "user-1", Role: tt.role})
+			if tt.apiKey {
+				c.Set("api_key", models.APIKey{ID: "key-1", Scopes: tt.scopes})
+			}
+			if got := Can(c, tt.permission); got != tt.want {
+				t.Errorf("Can(%s): got %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		user       *models.User
+		scopes     []models.Permission
+		wantStatus int
+	}{
+		{name: "not authenticated", wantStatus: http.StatusUnauthorized},
+		{name: "allowed", user: &models.User{Role: models.RoleEditor}, wantStatus: http.StatusOK},
+		{name: "role lacks permission", user: &models.User{Role: models.RoleViewer}, wantStatus: http.StatusForbidden},
+		{name: "key lacks scope", user: &models.User{Role: models.RoleEditor}, scopes: []models.Permission{models.PermVideosWrite}, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set("user", *tt.user)
+				}
+				if tt.scopes != nil {
+					c.Set("api_key", models.APIKey{Scopes: tt.scopes})
+				}
+				c.Next()
+			}, RequirePermission(models.PermBlogsCreate), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -1,13 +1,14 @@
 package models
 
 type About struct {
-	ID               string `json:"id"`
-	Title            string `json:"title"`
-	Subtitle         string `json:"subtitle"`
-	Description      string `json:"description"`
-	ImageURL         string `json:"image_url"`
-	YearsExperience  string `json:"years_experience"`
-	ProjectChallenge string `json:"project_challenge"`
-	PositiveReviews  string `json:"positive_reviews"`
-	TrustedStudents  string `json:"trusted_students"`
-}
\ No newline at end of file
+	ID               string `json:"id" bson:"_id"`
+	Title            string `json:"title" bson:"title"`
+	Subtitle         string `json:"subtitle" bson:"subtitle"`
+	Description      string `json:"description" bson:"description"`
+	ImageURL         string `json:"image_url" bson:"image_url"`
+	ImageKey         string `json:"image_key" bson:"image_key"`
+	YearsExperience  string `json:"years_experience" bson:"years_experience"`
+	ProjectChallenge string `json:"project_challenge" bson:"project_challenge"`
+	PositiveReviews  string `json:"positive_reviews" bson:"positive_reviews"`
+	TrustedStudents  string `json:"trusted_students" bson:"trusted_students"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// APIKey lets a machine client call the API without a login. It acts for
+// the user who created it, limited to its scopes. Only the hash of the key
+// is stored; Prefix identifies it in lists.
+type APIKey struct {
+	ID         string       `json:"id" bson:"_id"`
+	Name       string       `json:"name" bson:"name"`
+	Prefix     string       `json:"prefix" bson:"prefix"`
+	KeyHash    string       `json:"-" bson:"key_hash"`
+	Scopes     []Permission `json:"scopes" bson:"scopes"`
+	CreatedBy  string       `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time    `json:"created_at" bson:"created_at"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Active reports whether the key can be used at the given time.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope reports whether the key was granted a permission.
+func (k *APIKey) HasScope(permission Permission) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
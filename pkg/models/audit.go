@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// AuditEntry records a security relevant event or a change made through
+// the admin API.
+type AuditEntry struct {
+	ID           string            `json:"id" bson:"_id"`
+	ActorID      string            `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	APIKeyID     string            `json:"api_key_id,omitempty" bson:"api_key_id,omitempty"`
+	Action       string            `json:"action" bson:"action"`
+	ResourceType string            `json:"resource_type" bson:"resource_type"`
+	ResourceID   string            `json:"resource_id" bson:"resource_id"`
+	IP           string            `json:"ip,omitempty" bson:"ip,omitempty"`
+	Details      map[string]string `json:"details,omitempty" bson:"details,omitempty"`
+	// Changes maps the JSON name of every changed field to its values
+	// before and after the change.
+	Changes   map[string]AuditChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+}
+
+// AuditChange holds the old and new value of a field. Before is empty for
+// created records and After for deleted ones.
+type AuditChange struct {
+	Before AuditValue `json:"before,omitempty" bson:"before,omitempty"`
+	After  AuditValue `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// AuditValue is a JSON encoded value. It is stored as a string so that any
+// value reads back exactly as it was written.
+type AuditValue json.RawMessage
+
+// MarshalJSON returns the value itself.
+func (v AuditValue) MarshalJSON() ([]byte, error) {
+	if len(v) == 0 {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+// UnmarshalJSON stores a copy of the value.
+func (v *AuditValue) UnmarshalJSON(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
+
+// MarshalBSONValue stores the value as a string.
+func (v AuditValue) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(string(v))
+}
+
+// UnmarshalBSONValue reads a value stored by MarshalBSONValue.
+func (v *AuditValue) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	var s string
+	if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&s); err != nil {
+		return err
+	}
+	*v = AuditValue(s)
+	return nil
+}
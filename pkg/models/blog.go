@@ -2,14 +2,67 @@ package models
 
 import "time"
 
+// BlogStatus is the stage of a blog in the publishing workflow.
+type BlogStatus string
+
+const (
+	BlogDraft     BlogStatus = "draft"
+	BlogInReview  BlogStatus = "in_review"
+	BlogScheduled BlogStatus = "scheduled"
+	BlogPublished BlogStatus = "published"
+	BlogArchived  BlogStatus = "archived"
+)
+
+// Valid reports whether s is a known status.
+func (s BlogStatus) Valid() bool {
+	switch s {
+	case BlogDraft, BlogInReview, BlogScheduled, BlogPublished, BlogArchived:
+		return true
+	}
+	return false
+}
+
+// Blog is a blog post. Status is empty for blogs created before the
+// publishing workflow existed, which count as published. PublishAt is when a
+// scheduled blog goes live and PublishedAt when it was last published. Tags
+// and Categories hold slugs.
 type Blog struct {
-	ID              string    `json:"id"`
-	Title           string    `json:"title"`
-	Slug            string    `json:"slug"`
-	Content         string    `json:"content"`
-	ImageURL        string    `json:"image_url"`
-	Author          string    `json:"author"`
-	AuthorImageURL  string    `json:"author_image_url"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-}
\ No newline at end of file
+	ID             string     `json:"id" bson:"_id"`
+	Title          string     `json:"title" bson:"title"`
+	Slug           string     `json:"slug" bson:"slug"`
+	Content        string     `json:"content" bson:"content"`
+	ImageURL       string     `json:"image_url" bson:"image_url"`
+	ImageKey       string     `json:"image_key" bson:"image_key"`
+	Author         string     `json:"author" bson:"author"`
+	AuthorID       string     `json:"author_id" bson:"author_id"`
+	AuthorImageURL string     `json:"author_image_url" bson:"author_image_url"`
+	Tags           []string   `json:"tags" bson:"tags,omitempty"`
+	Categories     []string   `json:"categories" bson:"categories,omitempty"`
+	Status         BlogStatus `json:"status" bson:"status,omitempty"`
+	PublishAt      *time.Time `json:"publish_at,omitempty" bson:"publish_at,omitempty"`
+	PublishedAt    *time.Time `json:"published_at,omitempty" bson:"published_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" bson:"updated_at"`
+}
+
+// EffectiveStatus returns the status of the blog, treating blogs without
+// one as published.
+func (b *Blog) EffectiveStatus() BlogStatus {
+	if b.Status == "" {
+		return BlogPublished
+	}
+	return b.Status
+}
+
+// Visible reports whether the public may see the blog at the given time.
+// Scheduled blogs are visible from PublishAt on, even before the scheduler
+// has published them.
+func (b *Blog) Visible(now time.Time) bool {
+	switch b.EffectiveStatus() {
+	case BlogPublished:
+		return true
+	case BlogScheduled:
+		return b.PublishAt != nil && !now.Before(*b.PublishAt)
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// BlogRevision is an immutable copy of the content of a blog, saved every
+// time the blog is. Numbers start at 1 for every blog.
+type BlogRevision struct {
+	ID             string `json:"id" bson:"_id"`
+	BlogID         string `json:"blog_id" bson:"blog_id"`
+	Number         int    `json:"number" bson:"number"`
+	Title          string `json:"title" bson:"title"`
+	Content        string `json:"content" bson:"content"`
+	Author         string `json:"author" bson:"author"`
+	AuthorImageURL string `json:"author_image_url" bson:"author_image_url"`
+	// EditorID is the user who saved the revision, if known.
+	EditorID string `json:"editor_id,omitempty" bson:"editor_id,omitempty"`
+	// RestoredFrom is the number of the revision this one restored.
+	RestoredFrom int       `json:"restored_from,omitempty" bson:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+}
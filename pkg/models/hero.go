@@ -1,10 +1,14 @@
 package models
 
+import "time"
+
 type HeroSection struct {
-	ID            string `json:"id" bson:"_id,omitempty"`
-	HeadingText   string `json:"heading_text" bson:"heading_text"`
-	SubHeadingText string `json:"sub_heading_text" bson:"sub_heading_text"`
-	ToolTipName   string `json:"tool_tip_name" bson:"tool_tip_name"`
-	Image         string `json:"image" bson:"image"`
-	Designation   string `json:"designation" bson:"designation"`
-}
\ No newline at end of file
+	ID             string    `json:"id" bson:"_id,omitempty"`
+	HeadingText    string    `json:"heading_text" bson:"heading_text"`
+	SubHeadingText string    `json:"sub_heading_text" bson:"sub_heading_text"`
+	ToolTipName    string    `json:"tool_tip_name" bson:"tool_tip_name"`
+	Image          string    `json:"image" bson:"image"`
+	Designation    string    `json:"designation" bson:"designation"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" bson:"updated_at"`
+}
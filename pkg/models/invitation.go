@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Invitation lets a new user sign up with a given email and role. The token
+// is single-use and only its hash is stored.
+type Invitation struct {
+	ID             string     `json:"id" bson:"_id"`
+	Email          string     `json:"email" bson:"email"`
+	Role           Role       `json:"role" bson:"role"`
+	TokenHash      string     `json:"-" bson:"token_hash"`
+	CreatedBy      string     `json:"created_by" bson:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at" bson:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	AcceptedUserID string     `json:"accepted_user_id,omitempty" bson:"accepted_user_id,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Pending reports whether the invitation can still be accepted at the given
+// time.
+func (i *Invitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
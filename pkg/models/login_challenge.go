@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LoginChallenge is issued after a correct password for accounts with
+// two-factor authentication. It is exchanged for a session once the second
+// factor is verified.
+type LoginChallenge struct {
+	ID        string    `json:"id" bson:"_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	TokenHash string    `json:"-" bson:"token_hash"`
+	Attempts  int       `json:"attempts" bson:"attempts"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Kinds of login throttles. The password reset kinds count reset requests
+// rather than failed logins.
+const (
+	ThrottleKindAccount    = "account"
+	ThrottleKindIP         = "ip"
+	ThrottleKindResetEmail = "reset_email"
+	ThrottleKindResetIP    = "reset_ip"
+)
+
+// LoginThrottle counts recent failed logins for an account or a client IP.
+// Its ID is "<kind>:<subject>", e.g. "ip:203.0.113.7".
+type LoginThrottle struct {
+	ID            string     `json:"id" bson:"_id"`
+	Kind          string     `json:"kind" bson:"kind"`
+	Subject       string     `json:"subject" bson:"subject"`
+	Failures      int        `json:"failures" bson:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at" bson:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	// ExpiresAt is when the record can be forgotten.
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// LoginThrottleID returns the ID of the throttle for a subject.
+func LoginThrottleID(kind, subject string) string {
+	return kind + ":" + subject
+}
+
+// Locked reports whether logins are blocked at the given time.
+func (t *LoginThrottle) Locked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// OIDCLogin is a sign-in with the identity provider that has not come back
+// yet. The state parameter sent to the provider names it; the nonce and
+// the PKCE code verifier never leave the server.
+type OIDCLogin struct {
+	ID           string    `json:"id" bson:"_id"`
+	StateHash    string    `json:"-" bson:"state_hash"`
+	Nonce        string    `json:"-" bson:"nonce"`
+	CodeVerifier string    `json:"-" bson:"code_verifier"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" bson:"expires_at"`
+}
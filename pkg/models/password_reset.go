@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PasswordReset is a request to reset the password of a user. The token is
+// mailed to the user and only its hash is stored.
+type PasswordReset struct {
+	ID        string     `json:"id" bson:"_id"`
+	UserID    string     `json:"user_id" bson:"user_id"`
+	TokenHash string     `json:"-" bson:"token_hash"`
+	IP        string     `json:"ip" bson:"ip"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" bson:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
+
+// Usable reports whether the reset can still be used at the given time.
+func (r *PasswordReset) Usable(now time.Time) bool {
+	return r.UsedAt == nil && now.Before(r.ExpiresAt)
+}
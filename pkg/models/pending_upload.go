@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PendingUpload is a direct video upload the server issued presigned URLs
+// for and that has not been completed or aborted yet. ID is the object key.
+// Completing or aborting the upload consumes it, so an object can only be
+// registered once and only by the user it was issued to.
+type PendingUpload struct {
+	ID         string    `json:"id" bson:"_id"`
+	UploadID   string    `json:"upload_id,omitempty" bson:"upload_id,omitempty"`
+	UploaderID string    `json:"uploader_id" bson:"uploader_id"`
+	Size       int64     `json:"size" bson:"size"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`
+}
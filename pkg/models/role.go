@@ -0,0 +1,81 @@
+package models
+
+// Role groups the permissions granted to a user.
+type Role string
+
+const (
+	// RoleAdmin can do everything, including managing users.
+	RoleAdmin Role = "admin"
+	// RoleEditor manages all site content and publishes blogs.
+	RoleEditor Role = "editor"
+	// RoleAuthor writes blogs and can only change their own.
+	RoleAuthor Role = "author"
+	// RoleViewer has read-only access to the admin area.
+	RoleViewer Role = "viewer"
+)
+
+// Permission is an action on a resource, named "<resource>:<action>". The
+// ":own" suffix restricts the action to records the user created.
+type Permission string
+
+const (
+	PermBlogsCreate    Permission = "blogs:create"
+	PermBlogsUpdate    Permission = "blogs:update"
+	PermBlogsUpdateOwn Permission = "blogs:update:own"
+	PermBlogsDelete    Permission = "blogs:delete"
+	PermBlogsDeleteOwn Permission = "blogs:delete:own"
+	PermBlogsPublish   Permission = "blogs:publish"
+	PermVideosRead     Permission = "videos:read"
+	PermVideosWrite    Permission = "videos:write"
+	PermHeroWrite      Permission = "hero:write"
+	PermAboutWrite     Permission = "about:write"
+	PermServicesWrite  Permission = "services:write"
+	PermTaxonomyManage Permission = "taxonomy:manage"
+	PermUsersManage    Permission = "users:manage"
+	PermAuditRead      Permission = "audit:read"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermBlogsCreate, PermBlogsUpdate, PermBlogsUpdateOwn, PermBlogsDelete, PermBlogsDeleteOwn, PermBlogsPublish,
+		PermVideosRead, PermVideosWrite, PermHeroWrite, PermAboutWrite, PermServicesWrite, PermTaxonomyManage,
+		PermUsersManage, PermAuditRead,
+	},
+	RoleEditor: {
+		PermBlogsCreate, PermBlogsUpdate, PermBlogsUpdateOwn, PermBlogsDelete, PermBlogsDeleteOwn, PermBlogsPublish,
+		PermVideosRead, PermVideosWrite, PermHeroWrite, PermAboutWrite, PermServicesWrite, PermTaxonomyManage,
+	},
+	RoleAuthor: {
+		PermBlogsCreate, PermBlogsUpdateOwn, PermBlogsDeleteOwn,
+		PermVideosRead,
+	},
+	RoleViewer: {
+		PermVideosRead,
+	},
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants a permission.
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns the permissions granted by the role.
+func (r Role) Permissions() []Permission {
+	return append([]Permission(nil), rolePermissions[r]...)
+}
+
+// Valid reports whether p is a known permission.
+func (p Permission) Valid() bool {
+	return RoleAdmin.Can(p)
+}
@@ -2,8 +2,8 @@ package models
 
 type Service struct {
 	ID          string `json:"id" bson:"_id,omitempty"`
-	Image       string `json:"image"`
-	Name        string `json:"name"`
-	Location    string `json:"location"`
-	Description string `json:"description"`
-}
\ No newline at end of file
+	Image       string `json:"image" bson:"image"`
+	Name        string `json:"name" bson:"name"`
+	Location    string `json:"location" bson:"location"`
+	Description string `json:"description" bson:"description"`
+}
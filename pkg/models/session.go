@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Session is a login of a user. It owns the refresh token, which is rotated
+// on every refresh; the hashes of rotated tokens are kept so that replaying
+// one can be detected.
+type Session struct {
+	ID                  string     `json:"id" bson:"_id"`
+	UserID              string     `json:"user_id" bson:"user_id"`
+	RefreshTokenHash    string     `json:"-" bson:"refresh_token_hash"`
+	PreviousTokenHashes []string   `json:"-" bson:"previous_token_hashes"`
+	UserAgent           string     `json:"user_agent" bson:"user_agent"`
+	IP                  string     `json:"ip" bson:"ip"`
+	CreatedAt           time.Time  `json:"created_at" bson:"created_at"`
+	LastUsedAt          time.Time  `json:"last_used_at" bson:"last_used_at"`
+	ExpiresAt           time.Time  `json:"expires_at" bson:"expires_at"`
+	RevokedAt           *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	RevokedReason       string     `json:"revoked_reason,omitempty" bson:"revoked_reason,omitempty"`
+}
+
+// Active reports whether the session can still be used at the given time.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
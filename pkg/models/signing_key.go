@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SigningKey is a key pair for signing access tokens. Only the newest key
+// that is not retired signs; retired keys keep verifying until ExpiresAt so
+// tokens issued before a rotation stay valid.
+type SigningKey struct {
+	ID         string     `json:"id" bson:"_id"`
+	Algorithm  string     `json:"algorithm" bson:"algorithm"`
+	PrivateKey string     `json:"-" bson:"private_key"`
+	PublicKey  string     `json:"public_key" bson:"public_key"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty" bson:"retired_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+}
+
+// Verifies reports whether tokens signed with the key are accepted at the
+// given time.
+func (k *SigningKey) Verifies(now time.Time) bool {
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Category groups blogs by topic. Categories form a tree through ParentID.
+type Category struct {
+	ID          string    `json:"id" bson:"_id"`
+	Name        string    `json:"name" bson:"name"`
+	Slug        string    `json:"slug" bson:"slug"`
+	Description string    `json:"description" bson:"description"`
+	ParentID    string    `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Tag is a free-form label of blogs, created the first time it is used.
+type Tag struct {
+	ID        string    `json:"id" bson:"_id"`
+	Name      string    `json:"name" bson:"name"`
+	Slug      string    `json:"slug" bson:"slug"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
@@ -1,14 +1,64 @@
 package models
 
+import "time"
+
 type User struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	IsAdmin  bool   `json:"is_admin"`
+	ID       string `json:"id" bson:"_id"`
+	Name     string `json:"name" bson:"name"`
+	Email    string `json:"email" bson:"email"`
+	Password string `json:"password,omitempty" bson:"password"`
+	Role     Role   `json:"role" bson:"role"`
+	// IsAdmin predates roles. Users stored without a role are admins when it
+	// is set and viewers otherwise.
+	IsAdmin bool `json:"is_admin" bson:"is_admin"`
+	// DeactivatedAt is set while the account is deactivated; such users
+	// cannot log in.
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" bson:"deactivated_at,omitempty"`
+	// TOTPEnabled requires a second factor on login. The secret and the
+	// hashes of the unused recovery codes are never sent to clients.
+	TOTPEnabled        bool     `json:"totp_enabled" bson:"totp_enabled"`
+	TOTPSecret         string   `json:"-" bson:"totp_secret,omitempty"`
+	TOTPPendingSecret  string   `json:"-" bson:"totp_pending_secret,omitempty"`
+	TOTPLastCounter    int64    `json:"-" bson:"totp_last_counter,omitempty"`
+	RecoveryCodeHashes []string `json:"-" bson:"recovery_code_hashes,omitempty"`
+	// OIDCIssuer and OIDCSubject link the account to an identity provider
+	// account. Such users sign in through the provider and have no local
+	// password.
+	OIDCIssuer  string    `json:"oidc_issuer,omitempty" bson:"oidc_issuer,omitempty"`
+	OIDCSubject string    `json:"oidc_subject,omitempty" bson:"oidc_subject,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// EffectiveRole returns the role of the user, falling back to IsAdmin for
+// users created before roles existed.
+func (u *User) EffectiveRole() Role {
+	if u.Role.Valid() {
+		return u.Role
+	}
+	if u.IsAdmin {
+		return RoleAdmin
+	}
+	return RoleViewer
+}
+
+// Can reports whether the user's role grants a permission.
+func (u *User) Can(permission Permission) bool {
+	return u.EffectiveRole().Can(permission)
+}
+
+// Active reports whether the account is not deactivated.
+func (u *User) Active() bool {
+	return u.DeactivatedAt == nil
+}
+
+// Sanitized returns a copy of the user that is safe to send to clients.
+func (u User) Sanitized() User {
+	u.Password = ""
+	return u
 }
 
 type LoginUser struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
-}
\ No newline at end of file
+}
@@ -1,11 +1,14 @@
+package models
+
 import "time"
 
 type Video struct {
-	ID        int       `json:"id"`
-	Category  string    `json:"category"`
-	Title     string    `json:"title"`
-	VideoURL  string    `json:"video_url"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+	ID        string    `json:"id" bson:"_id"`
+	Category  string    `json:"category" bson:"category"`
+	Title     string    `json:"title" bson:"title"`
+	VideoURL  string    `json:"video_url" bson:"video_url"`
+	VideoKey  string    `json:"video_key" bson:"video_key"`
+	Content   string    `json:"content" bson:"content"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
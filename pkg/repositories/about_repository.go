@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// AboutRepository persists the about section.
+type AboutRepository interface {
+	Create(ctx context.Context, about *models.About) error
+	Update(ctx context.Context, about *models.About) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.About, error)
+	FindFirst(ctx context.Context) (*models.About, error)
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// APIKeyRepository persists API keys.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	Update(ctx context.Context, key *models.APIKey) error
+	FindByID(ctx context.Context, id string) (*models.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	// FindAll returns every key, newest first.
+	FindAll(ctx context.Context) ([]models.APIKey, error)
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// AuditFilter selects audit entries. Empty fields match everything.
+type AuditFilter struct {
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	// From and To bound the creation time; To is exclusive.
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// AuditRepository persists the audit log. Entries are never changed.
+type AuditRepository interface {
+	Create(ctx context.Context, entry *models.AuditEntry) error
+	// Find returns the matching entries, newest first.
+	Find(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error)
+}
+
+// matches reports whether an entry passes the filter.
+func (f AuditFilter) matches(entry *models.AuditEntry) bool {
+	return (f.ActorID == "" || entry.ActorID == f.ActorID) &&
+		(f.Action == "" || entry.Action == f.Action) &&
+		(f.ResourceType == "" || entry.ResourceType == f.ResourceType) &&
+		(f.ResourceID == "" || entry.ResourceID == f.ResourceID) &&
+		(f.From.IsZero() || !entry.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || entry.CreatedAt.Before(f.To))
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// BlogRepository persists blog posts.
+type BlogRepository interface {
+	Create(ctx context.Context, blog *models.Blog) error
+	Update(ctx context.Context, blog *models.Blog) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.Blog, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Blog, error)
+	FindAll(ctx context.Context) ([]models.Blog, error)
+	// List returns a page of the blogs matching the query.
+	List(ctx context.Context, query ListQuery) (*Page[models.Blog], error)
+	// Search returns the blogs matching a text search, best first. Only the
+	// filters and the limit of the query apply.
+	Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Blog], error)
+	// FindScheduled returns the scheduled blogs due at the given time.
+	FindScheduled(ctx context.Context, due time.Time) ([]models.Blog, error)
+	// ReplaceTaxonomySlug replaces a slug in the "tags" or "categories" of
+	// every blog in one update. An empty replacement removes the slug, and a
+	// blog that already has the replacement keeps it once.
+	ReplaceTaxonomySlug(ctx context.Context, field, from, to string) error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// BlogRevisionRepository persists blog revisions. Revisions are never
+// changed, only removed together with their blog.
+type BlogRevisionRepository interface {
+	// Create returns ErrDuplicateKey if the blog already has a revision with
+	// the same number.
+	Create(ctx context.Context, revision *models.BlogRevision) error
+	// FindByBlog returns the revisions of a blog, newest first.
+	FindByBlog(ctx context.Context, blogID string) ([]models.BlogRevision, error)
+	FindByNumber(ctx context.Context, blogID string, number int) (*models.BlogRevision, error)
+	// FindLatest returns ErrNotFound if the blog has no revisions.
+	FindLatest(ctx context.Context, blogID string) (*models.BlogRevision, error)
+	DeleteByBlog(ctx context.Context, blogID string) error
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// CategoryRepository persists blog categories. Slugs are unique.
+type CategoryRepository interface {
+	Create(ctx context.Context, category *models.Category) error
+	Update(ctx context.Context, category *models.Category) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.Category, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Category, error)
+	// FindAll returns every category, sorted by name.
+	FindAll(ctx context.Context) ([]models.Category, error)
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// HeroRepository persists the hero section of the landing page.
+type HeroRepository interface {
+	Create(ctx context.Context, hero *models.HeroSection) error
+	Update(ctx context.Context, hero *models.HeroSection) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.HeroSection, error)
+	FindFirst(ctx context.Context) (*models.HeroSection, error)
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// InvitationRepository persists invitations for new users.
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *models.Invitation) error
+	Update(ctx context.Context, invitation *models.Invitation) error
+	FindByID(ctx context.Context, id string) (*models.Invitation, error)
+	// FindAll returns every invitation, newest first.
+	FindAll(ctx context.Context) ([]models.Invitation, error)
+}
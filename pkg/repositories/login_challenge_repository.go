@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// LoginChallengeRepository persists pending two-factor logins.
+type LoginChallengeRepository interface {
+	Create(ctx context.Context, challenge *models.LoginChallenge) error
+	Update(ctx context.Context, challenge *models.LoginChallenge) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.LoginChallenge, error)
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// LoginThrottleRepository persists failed login counters. It is shared by
+// every instance of the API so limits hold across them.
+type LoginThrottleRepository interface {
+	// RecordFailure atomically counts a failed login and returns the updated
+	// throttle. Counting starts over when the last failure is older than
+	// window and the throttle is not locked.
+	RecordFailure(ctx context.Context, kind, subject string, now time.Time, window time.Duration) (*models.LoginThrottle, error)
+	Update(ctx context.Context, throttle *models.LoginThrottle) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.LoginThrottle, error)
+	// FindLocked returns the throttles locked at the given time.
+	FindLocked(ctx context.Context, now time.Time) ([]models.LoginThrottle, error)
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryAboutRepository struct {
+	store memoryStore[models.About]
+}
+
+// NewMemoryAboutRepository creates an AboutRepository kept in memory.
+func NewMemoryAboutRepository(db *MemoryDatabase) AboutRepository {
+	return &memoryAboutRepository{store: newMemoryStore[models.About](db, AboutsCollection)}
+}
+
+func (r *memoryAboutRepository) Create(ctx context.Context, about *models.About) error {
+	return r.store.insert(about.ID, about, nil)
+}
+
+func (r *memoryAboutRepository) Update(ctx context.Context, about *models.About) error {
+	return r.store.replace(about.ID, about, nil)
+}
+
+func (r *memoryAboutRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryAboutRepository) FindByID(ctx context.Context, id string) (*models.About, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryAboutRepository) FindFirst(ctx context.Context) (*models.About, error) {
+	return r.store.findOne(nil)
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryAPIKeyRepository struct {
+	store memoryStore[models.APIKey]
+}
+
+// NewMemoryAPIKeyRepository creates an APIKeyRepository kept in memory.
+func NewMemoryAPIKeyRepository(db *MemoryDatabase) APIKeyRepository {
+	return &memoryAPIKeyRepository{store: newMemoryStore[models.APIKey](db, APIKeysCollection)}
+}
+
+func sameAPIKeyPrefix(a, b *models.APIKey) bool {
+	return a.Prefix == b.Prefix
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return r.store.insert(key.ID, key, sameAPIKeyPrefix)
+}
+
+func (r *memoryAPIKeyRepository) Update(ctx context.Context, key *models.APIKey) error {
+	return r.store.replace(key.ID, key, sameAPIKeyPrefix)
+}
+
+func (r *memoryAPIKeyRepository) FindByID(ctx context.Context, id string) (*models.APIKey, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	return r.store.findOne(func(key *models.APIKey) bool { return key.Prefix == prefix })
+}
+
+func (r *memoryAPIKeyRepository) FindAll(ctx context.Context) ([]models.APIKey, error) {
+	keys, err := r.store.find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
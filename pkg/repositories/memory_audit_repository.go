@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryAuditRepository struct {
+	store memoryStore[models.AuditEntry]
+}
+
+// NewMemoryAuditRepository creates an AuditRepository kept in memory.
+func NewMemoryAuditRepository(db *MemoryDatabase) AuditRepository {
+	return &memoryAuditRepository{store: newMemoryStore[models.AuditEntry](db, AuditCollection)}
+}
+
+func (r *memoryAuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	return r.store.insert(entry.ID, entry, nil)
+}
+
+func (r *memoryAuditRepository) Find(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error) {
+	entries, err := r.store.find(filter.matches)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
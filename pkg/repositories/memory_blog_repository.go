@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryBlogRepository struct {
+	store memoryStore[models.Blog]
+	text  *memoryTextIndex
+}
+
+// NewMemoryBlogRepository creates a BlogRepository kept in memory.
+func NewMemoryBlogRepository(db *MemoryDatabase) BlogRepository {
+	return &memoryBlogRepository{
+		store: newMemoryStore[models.Blog](db, BlogsCollection),
+		text:  newMemoryTextIndex(blogTextWeights),
+	}
+}
+
+func sameBlogSlug(a, b *models.Blog) bool {
+	return a.Slug == b.Slug
+}
+
+func (r *memoryBlogRepository) Create(ctx context.Context, blog *models.Blog) error {
+	return r.store.insert(blog.ID, blog, sameBlogSlug)
+}
+
+func (r *memoryBlogRepository) Update(ctx context.Context, blog *models.Blog) error {
+	return r.store.replace(blog.ID, blog, sameBlogSlug)
+}
+
+func (r *memoryBlogRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryBlogRepository) FindByID(ctx context.Context, id string) (*models.Blog, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryBlogRepository) FindBySlug(ctx context.Context, slug string) (*models.Blog, error) {
+	return r.store.findOne(func(blog *models.Blog) bool { return blog.Slug == slug })
+}
+
+func (r *memoryBlogRepository) FindAll(ctx context.Context) ([]models.Blog, error) {
+	blogs, err := r.store.find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(blogs, func(i, j int) bool { return blogs[i].CreatedAt.After(blogs[j].CreatedAt) })
+	return blogs, nil
+}
+
+func (r *memoryBlogRepository) FindScheduled(ctx context.Context, due time.Time) ([]models.Blog, error) {
+	blogs, err := r.store.find(func(blog *models.Blog) bool {
+		return blog.Status == models.BlogScheduled && blog.PublishAt != nil && !blog.PublishAt.After(due)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(blogs, func(i, j int) bool { return blogs[i].PublishAt.Before(*blogs[j].PublishAt) })
+	return blogs, nil
+}
+
+func (r *memoryBlogRepository) List(ctx context.Context, query ListQuery) (*Page[models.Blog], error) {
+	return r.store.list(query)
+}
+
+func (r *memoryBlogRepository) Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Blog], error) {
+	return r.store.search(r.text, text, query)
+}
+
+func (r *memoryBlogRepository) ReplaceTaxonomySlug(ctx context.Context, field, from, to string) error {
+	var slugs func(blog *models.Blog) *[]string
+	switch field {
+	case "tags":
+		slugs = func(blog *models.Blog) *[]string { return &blog.Tags }
+	case "categories":
+		slugs = func(blog *models.Blog) *[]string { return &blog.Categories }
+	default:
+		return fmt.Errorf("unsupported taxonomy field %q", field)
+	}
+	return r.store.updateAll(func(blog *models.Blog) bool {
+		values := slugs(blog)
+		replaced := make([]string, 0, len(*values))
+		found := false
+		for _, slug := range *values {
+			if slug == from {
+				found, slug = true, to
+			}
+			if slug != "" && !containsString(replaced, slug) {
+				replaced = append(replaced, slug)
+			}
+		}
+		if found {
+			*values = replaced
+		}
+		return found
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryBlogRevisionRepository struct {
+	store memoryStore[models.BlogRevision]
+}
+
+// NewMemoryBlogRevisionRepository creates a BlogRevisionRepository kept in
+// memory.
+func NewMemoryBlogRevisionRepository(db *MemoryDatabase) BlogRevisionRepository {
+	return &memoryBlogRevisionRepository{store: newMemoryStore[models.BlogRevision](db, BlogRevisionsCollection)}
+}
+
+func sameBlogRevisionNumber(a, b *models.BlogRevision) bool {
+	return a.BlogID == b.BlogID && a.Number == b.Number
+}
+
+func (r *memoryBlogRevisionRepository) Create(ctx context.Context, revision *models.BlogRevision) error {
+	return r.store.insert(revision.ID, revision, sameBlogRevisionNumber)
+}
+
+func (r *memoryBlogRevisionRepository) FindByBlog(ctx context.Context, blogID string) ([]models.BlogRevision, error) {
+	revisions, err := r.store.find(func(revision *models.BlogRevision) bool { return revision.BlogID == blogID })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(revisions, func(i, j int) bool { return revisions[i].Number > revisions[j].Number })
+	return revisions, nil
+}
+
+func (r *memoryBlogRevisionRepository) FindByNumber(ctx context.Context, blogID string, number int) (*models.BlogRevision, error) {
+	return r.store.findOne(func(revision *models.BlogRevision) bool {
+		return revision.BlogID == blogID && revision.Number == number
+	})
+}
+
+func (r *memoryBlogRevisionRepository) FindLatest(ctx context.Context, blogID string) (*models.BlogRevision, error) {
+	revisions, err := r.FindByBlog(ctx, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
+	}
+	return &revisions[0], nil
+}
+
+func (r *memoryBlogRevisionRepository) DeleteByBlog(ctx context.Context, blogID string) error {
+	revisions, err := r.store.find(func(revision *models.BlogRevision) bool { return revision.BlogID == blogID })
+	if err != nil {
+		return err
+	}
+	for i := range revisions {
+		if err := r.store.delete(revisions[i].ID); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
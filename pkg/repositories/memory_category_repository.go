@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryCategoryRepository struct {
+	store memoryStore[models.Category]
+}
+
+// NewMemoryCategoryRepository creates a CategoryRepository kept in memory.
+func NewMemoryCategoryRepository(db *MemoryDatabase) CategoryRepository {
+	return &memoryCategoryRepository{store: newMemoryStore[models.Category](db, CategoriesCollection)}
+}
+
+func sameCategorySlug(a, b *models.Category) bool {
+	return a.Slug == b.Slug
+}
+
+func (r *memoryCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return r.store.insert(category.ID, category, sameCategorySlug)
+}
+
+func (r *memoryCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return r.store.replace(category.ID, category, sameCategorySlug)
+}
+
+func (r *memoryCategoryRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryCategoryRepository) FindByID(ctx context.Context, id string) (*models.Category, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryCategoryRepository) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	return r.store.findOne(func(category *models.Category) bool { return category.Slug == slug })
+}
+
+func (r *memoryCategoryRepository) FindAll(ctx context.Context) ([]models.Category, error) {
+	docs, err := r.store.find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs, nil
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryHeroRepository struct {
+	store memoryStore[models.HeroSection]
+}
+
+// NewMemoryHeroRepository creates a HeroRepository kept in memory.
+func NewMemoryHeroRepository(db *MemoryDatabase) HeroRepository {
+	return &memoryHeroRepository{store: newMemoryStore[models.HeroSection](db, HeroesCollection)}
+}
+
+func (r *memoryHeroRepository) Create(ctx context.Context, hero *models.HeroSection) error {
+	return r.store.insert(hero.ID, hero, nil)
+}
+
+func (r *memoryHeroRepository) Update(ctx context.Context, hero *models.HeroSection) error {
+	return r.store.replace(hero.ID, hero, nil)
+}
+
+func (r *memoryHeroRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryHeroRepository) FindByID(ctx context.Context, id string) (*models.HeroSection, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryHeroRepository) FindFirst(ctx context.Context) (*models.HeroSection, error) {
+	return r.store.findOne(nil)
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryInvitationRepository struct {
+	store memoryStore[models.Invitation]
+}
+
+// NewMemoryInvitationRepository creates an InvitationRepository kept in
+// memory.
+func NewMemoryInvitationRepository(db *MemoryDatabase) InvitationRepository {
+	return &memoryInvitationRepository{store: newMemoryStore[models.Invitation](db, InvitationsCollection)}
+}
+
+func (r *memoryInvitationRepository) Create(ctx context.Context, invitation *models.Invitation) error {
+	return r.store.insert(invitation.ID, invitation, nil)
+}
+
+func (r *memoryInvitationRepository) Update(ctx context.Context, invitation *models.Invitation) error {
+	return r.store.replace(invitation.ID, invitation, nil)
+}
+
+func (r *memoryInvitationRepository) FindByID(ctx context.Context, id string) (*models.Invitation, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryInvitationRepository) FindAll(ctx context.Context) ([]models.Invitation, error) {
+	invitations, err := r.store.find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+	return invitations, nil
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryLoginChallengeRepository struct {
+	store memoryStore[models.LoginChallenge]
+}
+
+// NewMemoryLoginChallengeRepository creates a LoginChallengeRepository kept
+// in memory.
+func NewMemoryLoginChallengeRepository(db *MemoryDatabase) LoginChallengeRepository {
+	return &memoryLoginChallengeRepository{store: newMemoryStore[models.LoginChallenge](db, LoginChallengesCollection)}
+}
+
+func (r *memoryLoginChallengeRepository) Create(ctx context.Context, challenge *models.LoginChallenge) error {
+	return r.store.insert(challenge.ID, challenge, nil)
+}
+
+func (r *memoryLoginChallengeRepository) Update(ctx context.Context, challenge *models.LoginChallenge) error {
+	return r.store.replace(challenge.ID, challenge, nil)
+}
+
+func (r *memoryLoginChallengeRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryLoginChallengeRepository) FindByID(ctx context.Context, id string) (*models.LoginChallenge, error) {
+	return r.store.get(id)
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryLoginThrottleRepository struct {
+	store memoryStore[models.LoginThrottle]
+}
+
+// NewMemoryLoginThrottleRepository creates a LoginThrottleRepository kept
+// in memory.
+func NewMemoryLoginThrottleRepository(db *MemoryDatabase) LoginThrottleRepository {
+	return &memoryLoginThrottleRepository{store: newMemoryStore[models.LoginThrottle](db, LoginThrottlesCollection)}
+}
+
+func (r *memoryLoginThrottleRepository) RecordFailure(ctx context.Context, kind, subject string, now time.Time, window time.Duration) (*models.LoginThrottle, error) {
+	id := models.LoginThrottleID(kind, subject)
+	return r.store.upsert(id, func(throttle *models.LoginThrottle) *models.LoginThrottle {
+		if throttle == nil || (throttle.LastFailureAt.Before(now.Add(-window)) && !throttle.Locked(now)) {
+			throttle = &models.LoginThrottle{ID: id, Kind: kind, Subject: subject}
+		}
+		throttle.Failures++
+		throttle.LastFailureAt = now
+		throttle.ExpiresAt = now.Add(window)
+		return throttle
+	})
+}
+
+func (r *memoryLoginThrottleRepository) Update(ctx context.Context, throttle *models.LoginThrottle) error {
+	return r.store.replace(throttle.ID, throttle, nil)
+}
+
+func (r *memoryLoginThrottleRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryLoginThrottleRepository) FindByID(ctx context.Context, id string) (*models.LoginThrottle, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryLoginThrottleRepository) FindLocked(ctx context.Context, now time.Time) ([]models.LoginThrottle, error) {
+	throttles, err := r.store.find(func(throttle *models.LoginThrottle) bool { return throttle.Locked(now) })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(throttles, func(i, j int) bool {
+		return throttles[i].LockedUntil.After(*throttles[j].LockedUntil)
+	})
+	return throttles, nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryOIDCLoginRepository struct {
+	store memoryStore[models.OIDCLogin]
+}
+
+// NewMemoryOIDCLoginRepository creates an OIDCLoginRepository kept in
+// memory.
+func NewMemoryOIDCLoginRepository(db *MemoryDatabase) OIDCLoginRepository {
+	return &memoryOIDCLoginRepository{store: newMemoryStore[models.OIDCLogin](db, OIDCLoginsCollection)}
+}
+
+func (r *memoryOIDCLoginRepository) Create(ctx context.Context, login *models.OIDCLogin) error {
+	return r.store.insert(login.ID, login, nil)
+}
+
+func (r *memoryOIDCLoginRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryOIDCLoginRepository) FindByID(ctx context.Context, id string) (*models.OIDCLogin, error) {
+	return r.store.get(id)
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryPasswordResetRepository struct {
+	store memoryStore[models.PasswordReset]
+}
+
+// NewMemoryPasswordResetRepository creates a PasswordResetRepository kept in
+// memory.
+func NewMemoryPasswordResetRepository(db *MemoryDatabase) PasswordResetRepository {
+	return &memoryPasswordResetRepository{store: newMemoryStore[models.PasswordReset](db, PasswordResetsCollection)}
+}
+
+func (r *memoryPasswordResetRepository) Create(ctx context.Context, reset *models.PasswordReset) error {
+	return r.store.insert(reset.ID, reset, nil)
+}
+
+func (r *memoryPasswordResetRepository) Update(ctx context.Context, reset *models.PasswordReset) error {
+	return r.store.replace(reset.ID, reset, nil)
+}
+
+func (r *memoryPasswordResetRepository) FindByID(ctx context.Context, id string) (*models.PasswordReset, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryPasswordResetRepository) MarkAllUsedForUser(ctx context.Context, userID string, usedAt time.Time) error {
+	resets, err := r.store.find(func(reset *models.PasswordReset) bool {
+		return reset.UserID == userID && reset.UsedAt == nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range resets {
+		resets[i].UsedAt = &usedAt
+		if err := r.store.replace(resets[i].ID, &resets[i], nil); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
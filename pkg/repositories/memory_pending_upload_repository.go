@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryPendingUploadRepository struct {
+	store memoryStore[models.PendingUpload]
+}
+
+// NewMemoryPendingUploadRepository creates a PendingUploadRepository kept in
+// memory.
+func NewMemoryPendingUploadRepository(db *MemoryDatabase) PendingUploadRepository {
+	return &memoryPendingUploadRepository{store: newMemoryStore[models.PendingUpload](db, PendingUploadsCollection)}
+}
+
+func (r *memoryPendingUploadRepository) Create(ctx context.Context, upload *models.PendingUpload) error {
+	return r.store.insert(upload.ID, upload, nil)
+}
+
+func (r *memoryPendingUploadRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryPendingUploadRepository) FindByID(ctx context.Context, id string) (*models.PendingUpload, error) {
+	return r.store.get(id)
+}
@@ -0,0 +1,246 @@
+package repositories
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// list returns a page of the documents matching the query. Filters and
+// sorting follow MongoDB semantics for the types the repositories store.
+func (s memoryStore[T]) list(q ListQuery) (*Page[T], error) {
+	after, err := decodeCursor(q.Cursor, q.Sort.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	s.db.mu.RLock()
+	var raws []bson.Raw
+	if coll, ok := s.db.collections[s.name]; ok {
+		for _, id := range coll.ids {
+			raws = append(raws, coll.docs[id])
+		}
+	}
+	s.db.mu.RUnlock()
+
+	// compare orders documents by the sort field, then by ID.
+	compare := func(a, b bson.Raw) int {
+		c := compareValues(lookupField(a, q.Sort.Field), lookupField(b, q.Sort.Field))
+		if c == 0 {
+			c = compareValues(lookupField(a, "_id"), lookupField(b, "_id"))
+		}
+		if q.Sort.Desc {
+			return -c
+		}
+		return c
+	}
+	matching := make([]bson.Raw, 0, len(raws))
+	for _, raw := range raws {
+		ok, err := matchesQuery(raw, q)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if after != nil {
+			c := compareValues(lookupField(raw, q.Sort.Field), after.Value)
+			if c == 0 {
+				c = compareValues(lookupField(raw, "_id"), after.ID)
+			}
+			if q.Sort.Desc {
+				c = -c
+			}
+			if c <= 0 {
+				continue
+			}
+		}
+		matching = append(matching, raw)
+	}
+	sort.SliceStable(matching, func(i, j int) bool { return compare(matching[i], matching[j]) < 0 })
+	return newPage[T](matching, q.Sort.Field, q.limit())
+}
+
+func matchesQuery(doc bson.Raw, q ListQuery) (bool, error) {
+	ok, err := matchesAll(doc, q.Filters)
+	if err != nil || !ok {
+		return false, err
+	}
+	if len(q.AnyOf) == 0 {
+		return true, nil
+	}
+	for _, group := range q.AnyOf {
+		ok, err := matchesAll(doc, group)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func matchesAll(doc bson.Raw, filters []Filter) (bool, error) {
+	for _, f := range filters {
+		ok, err := matchesFilter(doc, f)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchesFilter(doc bson.Raw, f Filter) (bool, error) {
+	field := lookupField(doc, f.Field)
+	var candidates []interface{}
+	if f.Op == OpIn {
+		values, ok := f.Value.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("filter on %s: %s needs a []interface{}", f.Field, f.Op)
+		}
+		candidates = values
+	} else {
+		candidates = []interface{}{f.Value}
+	}
+
+	for _, candidate := range candidates {
+		value, err := rawValue(candidate)
+		if err != nil {
+			return false, err
+		}
+		for _, element := range fieldElements(field) {
+			// Range filters only match values of the same type, as in MongoDB.
+			if f.Op != OpEq && f.Op != OpIn && typeOrder(element.Type) != typeOrder(value.Type) {
+				continue
+			}
+			c := compareValues(element, value)
+			var ok bool
+			switch f.Op {
+			case OpEq, OpIn:
+				ok = c == 0
+			case OpGt:
+				ok = c > 0
+			case OpGte:
+				ok = c >= 0
+			case OpLt:
+				ok = c < 0
+			case OpLte:
+				ok = c <= 0
+			default:
+				return false, fmt.Errorf("unsupported filter operator %q", f.Op)
+			}
+			if ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// rawValue encodes a filter value. nil stands for null.
+func rawValue(v interface{}) (bson.RawValue, error) {
+	if v == nil {
+		return bson.RawValue{Type: bsontype.Null}, nil
+	}
+	t, data, err := bson.MarshalValue(v)
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+	return bson.RawValue{Type: t, Value: data}, nil
+}
+
+// fieldElements returns the elements of an array field, or the field itself.
+func fieldElements(field bson.RawValue) []bson.RawValue {
+	if field.Type != bsontype.Array {
+		return []bson.RawValue{field}
+	}
+	values, err := field.Array().Values()
+	if err != nil {
+		return nil
+	}
+	return values
+}
+
+// typeOrder returns the rank of a type in the MongoDB sort order.
+func typeOrder(t bsontype.Type) int {
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		return 1
+	case bsontype.Int32, bsontype.Int64, bsontype.Double:
+		return 2
+	case bsontype.String:
+		return 3
+	case bsontype.EmbeddedDocument:
+		return 4
+	case bsontype.Array:
+		return 5
+	case bsontype.Binary:
+		return 6
+	case bsontype.ObjectID:
+		return 7
+	case bsontype.Boolean:
+		return 8
+	case bsontype.DateTime:
+		return 9
+	case bsontype.Timestamp:
+		return 10
+	default:
+		return 11
+	}
+}
+
+// compareValues orders two values by type, then by value.
+func compareValues(a, b bson.RawValue) int {
+	if oa, ob := typeOrder(a.Type), typeOrder(b.Type); oa != ob {
+		return oa - ob
+	}
+	switch typeOrder(a.Type) {
+	case 1:
+		return 0
+	case 2:
+		x, y := numberValue(a), numberValue(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case 3:
+		return strings.Compare(a.StringValue(), b.StringValue())
+	case 8:
+		x, y := a.Boolean(), b.Boolean()
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case 9:
+		x, y := a.DateTime(), b.DateTime()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	default:
+		return bytes.Compare(a.Value, b.Value)
+	}
+}
+
+func numberValue(v bson.RawValue) float64 {
+	switch v.Type {
+	case bsontype.Int32:
+		return float64(v.Int32())
+	case bsontype.Int64:
+		return float64(v.Int64())
+	case bsontype.Double:
+		return v.Double()
+	}
+	return 0
+}
@@ -0,0 +1,416 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MemoryDatabase keeps every collection in process memory. Documents are
+// stored BSON encoded, exactly as they would be written to MongoDB, so the
+// in-memory repositories never share pointers with their callers. When a
+// snapshot path is set, the whole database is written to that JSON file after
+// every change and loaded from it on startup.
+type MemoryDatabase struct {
+	mu           sync.RWMutex
+	snapshotPath string
+	collections  map[string]*memoryCollection
+}
+
+type memoryCollection struct {
+	ids  []string
+	docs map[string]bson.Raw
+	// version changes with every write, telling text indexes to rebuild.
+	version uint64
+}
+
+// NewMemoryDatabase creates an empty in-memory database. If snapshotPath is
+// not empty and the file exists, its contents are loaded.
+func NewMemoryDatabase(snapshotPath string) (*MemoryDatabase, error) {
+	db := &MemoryDatabase{
+		snapshotPath: snapshotPath,
+		collections:  map[string]*memoryCollection{},
+	}
+	if snapshotPath == "" {
+		return db, nil
+	}
+	if err := db.load(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewMemoryRepositories builds every repository on top of an in-memory database.
+func NewMemoryRepositories(db *MemoryDatabase) *Repositories {
+	return &Repositories{
+		Users:           NewMemoryUserRepository(db),
+		Blogs:           NewMemoryBlogRepository(db),
+		BlogRevisions:   NewMemoryBlogRevisionRepository(db),
+		Tags:            NewMemoryTagRepository(db),
+		Categories:      NewMemoryCategoryRepository(db),
+		Videos:          NewMemoryVideoRepository(db),
+		PendingUploads:  NewMemoryPendingUploadRepository(db),
+		Heroes:          NewMemoryHeroRepository(db),
+		Abouts:          NewMemoryAboutRepository(db),
+		Services:        NewMemoryServiceRepository(db),
+		Sessions:        NewMemorySessionRepository(db),
+		Invitations:     NewMemoryInvitationRepository(db),
+		PasswordResets:  NewMemoryPasswordResetRepository(db),
+		LoginChallenges: NewMemoryLoginChallengeRepository(db),
+		LoginThrottles:  NewMemoryLoginThrottleRepository(db),
+		Audit:           NewMemoryAuditRepository(db),
+		SigningKeys:     NewMemorySigningKeyRepository(db),
+		APIKeys:         NewMemoryAPIKeyRepository(db),
+		OIDCLogins:      NewMemoryOIDCLoginRepository(db),
+	}
+}
+
+func (db *MemoryDatabase) collection(name string) *memoryCollection {
+	coll, ok := db.collections[name]
+	if !ok {
+		coll = &memoryCollection{docs: map[string]bson.Raw{}}
+		db.collections[name] = coll
+	}
+	return coll
+}
+
+func (db *MemoryDatabase) load() error {
+	data, err := os.ReadFile(db.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot map[string][]json.RawMessage
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	for name, docs := range snapshot {
+		coll := db.collection(name)
+		for _, doc := range docs {
+			var d bson.D
+			if err := bson.UnmarshalExtJSON(doc, false, &d); err != nil {
+				return fmt.Errorf("failed to parse snapshot document in %s: %w", name, err)
+			}
+			raw, err := bson.Marshal(d)
+			if err != nil {
+				return err
+			}
+			id, ok := bson.Raw(raw).Lookup("_id").StringValueOK()
+			if !ok {
+				return fmt.Errorf("snapshot document in %s has no string _id", name)
+			}
+			coll.ids = append(coll.ids, id)
+			coll.docs[id] = raw
+		}
+	}
+	return nil
+}
+
+// save writes the snapshot file. The caller must hold the write lock; writes
+// go through commit.
+func (db *MemoryDatabase) save() error {
+	if db.snapshotPath == "" {
+		return nil
+	}
+
+	snapshot := map[string][]json.RawMessage{}
+	for name, coll := range db.collections {
+		docs := make([]json.RawMessage, 0, len(coll.ids))
+		for _, id := range coll.ids {
+			doc, err := bson.MarshalExtJSON(coll.docs[id], false, false)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+		}
+		snapshot[name] = docs
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(db.snapshotPath), ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), db.snapshotPath); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// commit applies change to coll and writes the snapshot. If the snapshot
+// cannot be written the change is undone, so memory never holds a write the
+// caller was told failed. The caller must hold the write lock.
+func (db *MemoryDatabase) commit(coll *memoryCollection, change func()) error {
+	if db.snapshotPath == "" {
+		change()
+		coll.version++
+		return nil
+	}
+
+	ids := append([]string(nil), coll.ids...)
+	docs := make(map[string]bson.Raw, len(coll.docs))
+	for id, raw := range coll.docs {
+		docs[id] = raw
+	}
+	version := coll.version
+	change()
+	coll.version++
+	if err := db.save(); err != nil {
+		coll.ids, coll.docs, coll.version = ids, docs, version
+		return err
+	}
+	return nil
+}
+
+// memoryStore implements the CRUD operations shared by every in-memory
+// repository. conflict functions report whether two documents violate a
+// unique constraint.
+type memoryStore[T any] struct {
+	db   *MemoryDatabase
+	name string
+}
+
+func newMemoryStore[T any](db *MemoryDatabase, name string) memoryStore[T] {
+	return memoryStore[T]{db: db, name: name}
+}
+
+func (s memoryStore[T]) decode(raw bson.Raw) (*T, error) {
+	var doc T
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// checkConflicts must be called with the lock held.
+func (s memoryStore[T]) checkConflicts(coll *memoryCollection, id string, doc *T, conflict func(a, b *T) bool) error {
+	if conflict == nil {
+		return nil
+	}
+	for _, otherID := range coll.ids {
+		if otherID == id {
+			continue
+		}
+		other, err := s.decode(coll.docs[otherID])
+		if err != nil {
+			return err
+		}
+		if conflict(doc, other) {
+			return ErrDuplicateKey
+		}
+	}
+	return nil
+}
+
+func (s memoryStore[T]) insert(id string, doc *T, conflict func(a, b *T) bool) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	coll := s.db.collection(s.name)
+	if _, ok := coll.docs[id]; ok {
+		return ErrDuplicateKey
+	}
+	if err := s.checkConflicts(coll, id, doc, conflict); err != nil {
+		return err
+	}
+	return s.db.commit(coll, func() {
+		coll.ids = append(coll.ids, id)
+		coll.docs[id] = raw
+	})
+}
+
+func (s memoryStore[T]) replace(id string, doc *T, conflict func(a, b *T) bool) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	coll := s.db.collection(s.name)
+	if _, ok := coll.docs[id]; !ok {
+		return ErrNotFound
+	}
+	if err := s.checkConflicts(coll, id, doc, conflict); err != nil {
+		return err
+	}
+	return s.db.commit(coll, func() { coll.docs[id] = raw })
+}
+
+// upsert atomically replaces the document with the result of modify, which
+// receives nil if the document does not exist yet.
+func (s memoryStore[T]) upsert(id string, modify func(doc *T) *T) (*T, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	coll := s.db.collection(s.name)
+	var existing *T
+	if raw, ok := coll.docs[id]; ok {
+		doc, err := s.decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		existing = doc
+	}
+	doc := modify(existing)
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.commit(coll, func() {
+		if existing == nil {
+			coll.ids = append(coll.ids, id)
+		}
+		coll.docs[id] = raw
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// update atomically rewrites a document with modify, which reports whether
+// it changed the document. It returns ErrNotFound if the document does not
+// exist or modify leaves it unchanged.
+func (s memoryStore[T]) update(id string, modify func(doc *T) bool) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	coll := s.db.collection(s.name)
+	raw, ok := coll.docs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	doc, err := s.decode(raw)
+	if err != nil {
+		return err
+	}
+	if !modify(doc) {
+		return ErrNotFound
+	}
+	if raw, err = bson.Marshal(doc); err != nil {
+		return err
+	}
+	return s.db.commit(coll, func() { coll.docs[id] = raw })
+}
+
+// updateAll atomically rewrites every document that modify changes. modify
+// reports whether it changed the document.
+func (s memoryStore[T]) updateAll(modify func(doc *T) bool) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	coll := s.db.collection(s.name)
+	updated := map[string]bson.Raw{}
+	for _, id := range coll.ids {
+		doc, err := s.decode(coll.docs[id])
+		if err != nil {
+			return err
+		}
+		if !modify(doc) {
+			continue
+		}
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		updated[id] = raw
+	}
+	if len(updated) == 0 {
+		return nil
+	}
+	return s.db.commit(coll, func() {
+		for id, raw := range updated {
+			coll.docs[id] = raw
+		}
+	})
+}
+
+func (s memoryStore[T]) delete(id string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	coll := s.db.collection(s.name)
+	if _, ok := coll.docs[id]; !ok {
+		return ErrNotFound
+	}
+	return s.db.commit(coll, func() {
+		delete(coll.docs, id)
+		for i, existing := range coll.ids {
+			if existing == id {
+				coll.ids = append(coll.ids[:i], coll.ids[i+1:]...)
+				break
+			}
+		}
+	})
+}
+
+func (s memoryStore[T]) get(id string) (*T, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	coll, ok := s.db.collections[s.name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	raw, ok := coll.docs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.decode(raw)
+}
+
+// findOne returns the first document, in insertion order, for which match
+// returns true. A nil match accepts every document.
+func (s memoryStore[T]) findOne(match func(*T) bool) (*T, error) {
+	docs, err := s.find(match)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, ErrNotFound
+	}
+	return &docs[0], nil
+}
+
+// find returns every document, in insertion order, for which match returns
+// true. A nil match accepts every document.
+func (s memoryStore[T]) find(match func(*T) bool) ([]T, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	docs := []T{}
+	coll, ok := s.db.collections[s.name]
+	if !ok {
+		return docs, nil
+	}
+	for _, id := range coll.ids {
+		doc, err := s.decode(coll.docs[id])
+		if err != nil {
+			return nil, err
+		}
+		if match == nil || match(doc) {
+			docs = append(docs, *doc)
+		}
+	}
+	return docs, nil
+}
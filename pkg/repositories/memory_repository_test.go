@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func TestMemoryStoreUndoesFailedSave(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(s memoryStore[models.Tag]) error
+	}{
+		{name: "insert", write: func(s memoryStore[models.Tag]) error {
+			return s.insert("c", &models.Tag{ID: "c", Slug: "c"}, nil)
+		}},
+		{name: "replace", write: func(s memoryStore[models.Tag]) error {
+			return s.replace("a", &models.Tag{ID: "a", Slug: "changed"}, nil)
+		}},
+		{name: "upsert new", write: func(s memoryStore[models.Tag]) error {
+			_, err := s.upsert("c", func(*models.Tag) *models.Tag { return &models.Tag{ID: "c", Slug: "c"} })
+			return err
+		}},
+		{name: "upsert existing", write: func(s memoryStore[models.Tag]) error {
+			_, err := s.upsert("a", func(tag *models.Tag) *models.Tag { tag.Slug = "changed"; return tag })
+			return err
+		}},
+		{name: "update", write: func(s memoryStore[models.Tag]) error {
+			return s.update("a", func(tag *models.Tag) bool { tag.Slug = "changed"; return true })
+		}},
+		{name: "update all", write: func(s memoryStore[models.Tag]) error {
+			return s.updateAll(func(tag *models.Tag) bool { tag.Slug = "changed"; return true })
+		}},
+		{name: "delete", write: func(s memoryStore[models.Tag]) error {
+			return s.delete("a")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "data")
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatalf("Mkdir: %v", err)
+			}
+			db, err := NewMemoryDatabase(filepath.Join(dir, "snapshot.json"))
+			if err != nil {
+				t.Fatalf("NewMemoryDatabase: %v", err)
+			}
+			s := newMemoryStore[models.Tag](db, TagsCollection)
+			for _, id := range []string{"a", "b"} {
+				if err := s.insert(id, &models.Tag{ID: id, Slug: id}, nil); err != nil {
+					t.Fatalf("insert: %v", err)
+				}
+			}
+			want, err := s.find(nil)
+			if err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			version := db.collections[TagsCollection].version
+
+			// Without its directory the snapshot cannot be written.
+			if err := os.RemoveAll(dir); err != nil {
+				t.Fatalf("RemoveAll: %v", err)
+			}
+			if err := tt.write(s); err == nil {
+				t.Fatal("write: got no error")
+			}
+			got, err := s.find(nil)
+			if err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("tags: got %v, want %v", got, want)
+			}
+			if v := db.collections[TagsCollection].version; v != version {
+				t.Errorf("version: got %d, want %d", v, version)
+			}
+		})
+	}
+}
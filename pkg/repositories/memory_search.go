@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// memoryTextIndex is an inverted index over the text fields of a memory
+// collection, standing in for a MongoDB text index. It is rebuilt on the
+// first search after the collection changes.
+type memoryTextIndex struct {
+	// weights maps the indexed fields to their weight, as in MongoDB.
+	weights map[string]float64
+
+	mu       sync.Mutex
+	built    bool
+	version  uint64
+	postings map[string]map[string]float64 // term → document ID → weighted count
+}
+
+func newMemoryTextIndex(weights map[string]float64) *memoryTextIndex {
+	return &memoryTextIndex{weights: weights}
+}
+
+// scores returns the relevance of every document containing one of terms.
+// The caller must hold the read lock of the database.
+func (idx *memoryTextIndex) scores(coll *memoryCollection, terms []string) map[string]float64 {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if !idx.built || idx.version != coll.version {
+		idx.postings = map[string]map[string]float64{}
+		for _, id := range coll.ids {
+			for field, weight := range idx.weights {
+				text, _ := lookupField(coll.docs[id], field).StringValueOK()
+				for _, term := range utils.SearchTokens(text) {
+					if idx.postings[term] == nil {
+						idx.postings[term] = map[string]float64{}
+					}
+					idx.postings[term][id] += weight
+				}
+			}
+		}
+		idx.built, idx.version = true, coll.version
+	}
+
+	// Terms found in fewer documents count more.
+	scores := map[string]float64{}
+	for _, term := range terms {
+		postings := idx.postings[term]
+		idf := math.Log(1 + float64(len(coll.ids))/float64(len(postings)+1))
+		for id, count := range postings {
+			scores[id] += count * idf
+		}
+	}
+	return scores
+}
+
+// search returns the documents matching any of the terms of a text search,
+// best first. Only the filters and the limit of the query apply.
+func (s memoryStore[T]) search(index *memoryTextIndex, text string, q ListQuery) ([]SearchHit[T], error) {
+	hits := []SearchHit[T]{}
+	terms := utils.SearchTerms(text)
+	if len(terms) == 0 {
+		return hits, nil
+	}
+
+	s.db.mu.RLock()
+	coll, ok := s.db.collections[s.name]
+	if !ok {
+		s.db.mu.RUnlock()
+		return hits, nil
+	}
+	scores := index.scores(coll, terms)
+	raws := make(map[string]bson.Raw, len(scores))
+	for id := range scores {
+		raws[id] = coll.docs[id]
+	}
+	s.db.mu.RUnlock()
+
+	ids := make([]string, 0, len(scores))
+	for id, raw := range raws {
+		ok, err := matchesQuery(raw, q)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > q.limit() {
+		ids = ids[:q.limit()]
+	}
+	for _, id := range ids {
+		doc, err := s.decode(raws[id])
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, SearchHit[T]{Item: *doc, Score: scores[id]})
+	}
+	return hits, nil
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryServiceRepository struct {
+	store memoryStore[models.Service]
+	text  *memoryTextIndex
+}
+
+// NewMemoryServiceRepository creates a ServiceRepository kept in memory.
+func NewMemoryServiceRepository(db *MemoryDatabase) ServiceRepository {
+	return &memoryServiceRepository{
+		store: newMemoryStore[models.Service](db, ServicesCollection),
+		text:  newMemoryTextIndex(serviceTextWeights),
+	}
+}
+
+func (r *memoryServiceRepository) Create(ctx context.Context, service *models.Service) error {
+	return r.store.insert(service.ID, service, nil)
+}
+
+func (r *memoryServiceRepository) Update(ctx context.Context, service *models.Service) error {
+	return r.store.replace(service.ID, service, nil)
+}
+
+func (r *memoryServiceRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryServiceRepository) FindByID(ctx context.Context, id string) (*models.Service, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryServiceRepository) FindAll(ctx context.Context) ([]models.Service, error) {
+	return r.store.find(nil)
+}
+
+func (r *memoryServiceRepository) List(ctx context.Context, query ListQuery) (*Page[models.Service], error) {
+	return r.store.list(query)
+}
+
+func (r *memoryServiceRepository) Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Service], error) {
+	return r.store.search(r.text, text, query)
+}
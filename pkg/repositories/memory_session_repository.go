@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memorySessionRepository struct {
+	store memoryStore[models.Session]
+}
+
+// NewMemorySessionRepository creates a SessionRepository kept in memory.
+func NewMemorySessionRepository(db *MemoryDatabase) SessionRepository {
+	return &memorySessionRepository{store: newMemoryStore[models.Session](db, SessionsCollection)}
+}
+
+func (r *memorySessionRepository) Create(ctx context.Context, session *models.Session) error {
+	return r.store.insert(session.ID, session, nil)
+}
+
+func (r *memorySessionRepository) Rotate(ctx context.Context, session *models.Session, previousHash string) error {
+	return r.store.update(session.ID, func(existing *models.Session) bool {
+		if existing.RefreshTokenHash != previousHash || existing.RevokedAt != nil {
+			return false
+		}
+		*existing = *session
+		return true
+	})
+}
+
+func (r *memorySessionRepository) Revoke(ctx context.Context, id string, revokedAt time.Time, reason string) error {
+	return r.store.update(id, func(session *models.Session) bool {
+		if session.RevokedAt != nil {
+			return false
+		}
+		session.RevokedAt, session.RevokedReason = &revokedAt, reason
+		return true
+	})
+}
+
+func (r *memorySessionRepository) FindByID(ctx context.Context, id string) (*models.Session, error) {
+	return r.store.get(id)
+}
+
+func (r *memorySessionRepository) RevokeAllForUser(ctx context.Context, userID string, revokedAt time.Time, reason string) error {
+	return r.store.updateAll(func(session *models.Session) bool {
+		if session.UserID != userID || session.RevokedAt != nil {
+			return false
+		}
+		session.RevokedAt, session.RevokedReason = &revokedAt, reason
+		return true
+	})
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memorySigningKeyRepository struct {
+	store memoryStore[models.SigningKey]
+}
+
+// NewMemorySigningKeyRepository creates a SigningKeyRepository kept in
+// memory.
+func NewMemorySigningKeyRepository(db *MemoryDatabase) SigningKeyRepository {
+	return &memorySigningKeyRepository{store: newMemoryStore[models.SigningKey](db, SigningKeysCollection)}
+}
+
+func (r *memorySigningKeyRepository) Create(ctx context.Context, key *models.SigningKey) error {
+	return r.store.insert(key.ID, key, nil)
+}
+
+func (r *memorySigningKeyRepository) Update(ctx context.Context, key *models.SigningKey) error {
+	return r.store.replace(key.ID, key, nil)
+}
+
+func (r *memorySigningKeyRepository) FindAll(ctx context.Context) ([]models.SigningKey, error) {
+	keys, err := r.store.find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryTagRepository struct {
+	store memoryStore[models.Tag]
+}
+
+// NewMemoryTagRepository creates a TagRepository kept in memory.
+func NewMemoryTagRepository(db *MemoryDatabase) TagRepository {
+	return &memoryTagRepository{store: newMemoryStore[models.Tag](db, TagsCollection)}
+}
+
+func sameTagSlug(a, b *models.Tag) bool {
+	return a.Slug == b.Slug
+}
+
+func (r *memoryTagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	return r.store.insert(tag.ID, tag, sameTagSlug)
+}
+
+func (r *memoryTagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	return r.store.replace(tag.ID, tag, sameTagSlug)
+}
+
+func (r *memoryTagRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryTagRepository) FindByID(ctx context.Context, id string) (*models.Tag, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryTagRepository) FindBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	return r.store.findOne(func(tag *models.Tag) bool { return tag.Slug == slug })
+}
+
+func (r *memoryTagRepository) FindAll(ctx context.Context) ([]models.Tag, error) {
+	docs, err := r.store.find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs, nil
+}
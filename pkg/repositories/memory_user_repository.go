@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryUserRepository struct {
+	store memoryStore[models.User]
+}
+
+// NewMemoryUserRepository creates a UserRepository kept in memory.
+func NewMemoryUserRepository(db *MemoryDatabase) UserRepository {
+	return &memoryUserRepository{store: newMemoryStore[models.User](db, UsersCollection)}
+}
+
+func sameUserEmail(a, b *models.User) bool {
+	return a.Email == b.Email
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.store.insert(user.ID, user, sameUserEmail)
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *models.User) error {
+	return r.store.replace(user.ID, user, sameUserEmail)
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.store.findOne(func(user *models.User) bool { return user.Email == email })
+}
+
+func (r *memoryUserRepository) FindByOIDCSubject(ctx context.Context, issuer, subject string) (*models.User, error) {
+	return r.store.findOne(func(user *models.User) bool {
+		return user.OIDCSubject == subject && user.OIDCIssuer == issuer
+	})
+}
+
+func (r *memoryUserRepository) FindAll(ctx context.Context) ([]models.User, error) {
+	users, err := r.store.find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	return users, nil
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type memoryVideoRepository struct {
+	store memoryStore[models.Video]
+	text  *memoryTextIndex
+}
+
+// NewMemoryVideoRepository creates a VideoRepository kept in memory.
+func NewMemoryVideoRepository(db *MemoryDatabase) VideoRepository {
+	return &memoryVideoRepository{
+		store: newMemoryStore[models.Video](db, VideosCollection),
+		text:  newMemoryTextIndex(videoTextWeights),
+	}
+}
+
+func (r *memoryVideoRepository) Create(ctx context.Context, video *models.Video) error {
+	return r.store.insert(video.ID, video, nil)
+}
+
+func (r *memoryVideoRepository) Update(ctx context.Context, video *models.Video) error {
+	return r.store.replace(video.ID, video, nil)
+}
+
+func (r *memoryVideoRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(id)
+}
+
+func (r *memoryVideoRepository) FindByID(ctx context.Context, id string) (*models.Video, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryVideoRepository) FindAll(ctx context.Context) ([]models.Video, error) {
+	videos, err := r.store.find(nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(videos, func(i, j int) bool { return videos[i].CreatedAt.After(videos[j].CreatedAt) })
+	return videos, nil
+}
+
+func (r *memoryVideoRepository) List(ctx context.Context, query ListQuery) (*Page[models.Video], error) {
+	return r.store.list(query)
+}
+
+func (r *memoryVideoRepository) Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Video], error) {
+	return r.store.search(r.text, text, query)
+}
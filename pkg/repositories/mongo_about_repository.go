@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoAboutRepository struct {
+	store mongoStore[models.About]
+}
+
+// NewMongoAboutRepository creates an AboutRepository backed by MongoDB.
+func NewMongoAboutRepository(db *mongo.Database) AboutRepository {
+	return &mongoAboutRepository{store: newMongoStore[models.About](db, AboutsCollection)}
+}
+
+func (r *mongoAboutRepository) Create(ctx context.Context, about *models.About) error {
+	return r.store.insert(ctx, about)
+}
+
+func (r *mongoAboutRepository) Update(ctx context.Context, about *models.About) error {
+	return r.store.replace(ctx, about.ID, about)
+}
+
+func (r *mongoAboutRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoAboutRepository) FindByID(ctx context.Context, id string) (*models.About, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoAboutRepository) FindFirst(ctx context.Context) (*models.About, error) {
+	return r.store.findOne(ctx, bson.M{})
+}
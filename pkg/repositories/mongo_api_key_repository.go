@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoAPIKeyRepository struct {
+	store mongoStore[models.APIKey]
+}
+
+// NewMongoAPIKeyRepository creates an APIKeyRepository backed by MongoDB.
+func NewMongoAPIKeyRepository(db *mongo.Database) APIKeyRepository {
+	return &mongoAPIKeyRepository{store: newMongoStore[models.APIKey](db, APIKeysCollection)}
+}
+
+func (r *mongoAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return r.store.insert(ctx, key)
+}
+
+func (r *mongoAPIKeyRepository) Update(ctx context.Context, key *models.APIKey) error {
+	return r.store.replace(ctx, key.ID, key)
+}
+
+func (r *mongoAPIKeyRepository) FindByID(ctx context.Context, id string) (*models.APIKey, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	return r.store.findOne(ctx, bson.M{"prefix": prefix})
+}
+
+func (r *mongoAPIKeyRepository) FindAll(ctx context.Context) ([]models.APIKey, error) {
+	return r.store.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
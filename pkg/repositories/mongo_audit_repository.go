@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoAuditRepository struct {
+	store mongoStore[models.AuditEntry]
+}
+
+// NewMongoAuditRepository creates an AuditRepository backed by MongoDB.
+func NewMongoAuditRepository(db *mongo.Database) AuditRepository {
+	return &mongoAuditRepository{store: newMongoStore[models.AuditEntry](db, AuditCollection)}
+}
+
+func (r *mongoAuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	return r.store.insert(ctx, entry)
+}
+
+func (r *mongoAuditRepository) Find(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error) {
+	query := bson.M{}
+	for field, value := range map[string]string{
+		"actor_id":      filter.ActorID,
+		"action":        filter.Action,
+		"resource_type": filter.ResourceType,
+		"resource_id":   filter.ResourceID,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+	return r.store.find(ctx, query, opts)
+}
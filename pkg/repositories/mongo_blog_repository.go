@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoBlogRepository struct {
+	store mongoStore[models.Blog]
+}
+
+// NewMongoBlogRepository creates a BlogRepository backed by MongoDB.
+func NewMongoBlogRepository(db *mongo.Database) BlogRepository {
+	return &mongoBlogRepository{store: newMongoStore[models.Blog](db, BlogsCollection)}
+}
+
+func (r *mongoBlogRepository) Create(ctx context.Context, blog *models.Blog) error {
+	return r.store.insert(ctx, blog)
+}
+
+func (r *mongoBlogRepository) Update(ctx context.Context, blog *models.Blog) error {
+	return r.store.replace(ctx, blog.ID, blog)
+}
+
+func (r *mongoBlogRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoBlogRepository) FindByID(ctx context.Context, id string) (*models.Blog, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoBlogRepository) FindBySlug(ctx context.Context, slug string) (*models.Blog, error) {
+	return r.store.findOne(ctx, bson.M{"slug": slug})
+}
+
+func (r *mongoBlogRepository) FindAll(ctx context.Context) ([]models.Blog, error) {
+	return r.store.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
+
+func (r *mongoBlogRepository) FindScheduled(ctx context.Context, due time.Time) ([]models.Blog, error) {
+	filter := bson.M{"status": models.BlogScheduled, "publish_at": bson.M{"$lte": due}}
+	return r.store.find(ctx, filter, options.Find().SetSort(bson.D{{Key: "publish_at", Value: 1}}))
+}
+
+func (r *mongoBlogRepository) List(ctx context.Context, query ListQuery) (*Page[models.Blog], error) {
+	return r.store.list(ctx, query)
+}
+
+func (r *mongoBlogRepository) Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Blog], error) {
+	return r.store.search(ctx, text, query)
+}
+
+func (r *mongoBlogRepository) ReplaceTaxonomySlug(ctx context.Context, field, from, to string) error {
+	if field != "tags" && field != "categories" {
+		return fmt.Errorf("unsupported taxonomy field %q", field)
+	}
+	filter := bson.M{field: from}
+	if to == "" {
+		_, err := r.store.collection.UpdateMany(ctx, filter, bson.M{"$pull": bson.M{field: from}})
+		return err
+	}
+	// Rebuild the array in order, replacing the slug and skipping
+	// duplicates, so that each blog changes in a single write.
+	slug := bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$$this", bson.M{"$literal": from}}}, bson.M{"$literal": to}, "$$this"}}
+	update := bson.A{bson.M{"$set": bson.M{field: bson.M{"$reduce": bson.M{
+		"input":        "$" + field,
+		"initialValue": bson.A{},
+		"in": bson.M{"$let": bson.M{
+			"vars": bson.M{"slug": slug},
+			"in": bson.M{"$cond": bson.A{
+				bson.M{"$in": bson.A{"$$slug", "$$value"}},
+				"$$value",
+				bson.M{"$concatArrays": bson.A{"$$value", bson.A{"$$slug"}}},
+			}},
+		}},
+	}}}}}
+	_, err := r.store.collection.UpdateMany(ctx, filter, update)
+	return err
+}
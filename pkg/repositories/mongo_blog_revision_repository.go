@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoBlogRevisionRepository struct {
+	store mongoStore[models.BlogRevision]
+}
+
+// NewMongoBlogRevisionRepository creates a BlogRevisionRepository backed by
+// MongoDB.
+func NewMongoBlogRevisionRepository(db *mongo.Database) BlogRevisionRepository {
+	return &mongoBlogRevisionRepository{store: newMongoStore[models.BlogRevision](db, BlogRevisionsCollection)}
+}
+
+func (r *mongoBlogRevisionRepository) Create(ctx context.Context, revision *models.BlogRevision) error {
+	return r.store.insert(ctx, revision)
+}
+
+func (r *mongoBlogRevisionRepository) FindByBlog(ctx context.Context, blogID string) ([]models.BlogRevision, error) {
+	return r.store.find(ctx, bson.M{"blog_id": blogID}, options.Find().SetSort(bson.D{{Key: "number", Value: -1}}))
+}
+
+func (r *mongoBlogRevisionRepository) FindByNumber(ctx context.Context, blogID string, number int) (*models.BlogRevision, error) {
+	return r.store.findOne(ctx, bson.M{"blog_id": blogID, "number": number})
+}
+
+func (r *mongoBlogRevisionRepository) FindLatest(ctx context.Context, blogID string) (*models.BlogRevision, error) {
+	return r.store.findOne(ctx, bson.M{"blog_id": blogID}, options.FindOne().SetSort(bson.D{{Key: "number", Value: -1}}))
+}
+
+func (r *mongoBlogRevisionRepository) DeleteByBlog(ctx context.Context, blogID string) error {
+	_, err := r.store.collection.DeleteMany(ctx, bson.M{"blog_id": blogID})
+	return err
+}
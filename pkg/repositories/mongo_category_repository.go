@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoCategoryRepository struct {
+	store mongoStore[models.Category]
+}
+
+// NewMongoCategoryRepository creates a CategoryRepository backed by MongoDB.
+func NewMongoCategoryRepository(db *mongo.Database) CategoryRepository {
+	return &mongoCategoryRepository{store: newMongoStore[models.Category](db, CategoriesCollection)}
+}
+
+func (r *mongoCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return r.store.insert(ctx, category)
+}
+
+func (r *mongoCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return r.store.replace(ctx, category.ID, category)
+}
+
+func (r *mongoCategoryRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoCategoryRepository) FindByID(ctx context.Context, id string) (*models.Category, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoCategoryRepository) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	return r.store.findOne(ctx, bson.M{"slug": slug})
+}
+
+func (r *mongoCategoryRepository) FindAll(ctx context.Context) ([]models.Category, error) {
+	return r.store.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoHeroRepository struct {
+	store mongoStore[models.HeroSection]
+}
+
+// NewMongoHeroRepository creates a HeroRepository backed by MongoDB.
+func NewMongoHeroRepository(db *mongo.Database) HeroRepository {
+	return &mongoHeroRepository{store: newMongoStore[models.HeroSection](db, HeroesCollection)}
+}
+
+func (r *mongoHeroRepository) Create(ctx context.Context, hero *models.HeroSection) error {
+	return r.store.insert(ctx, hero)
+}
+
+func (r *mongoHeroRepository) Update(ctx context.Context, hero *models.HeroSection) error {
+	return r.store.replace(ctx, hero.ID, hero)
+}
+
+func (r *mongoHeroRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoHeroRepository) FindByID(ctx context.Context, id string) (*models.HeroSection, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoHeroRepository) FindFirst(ctx context.Context) (*models.HeroSection, error) {
+	return r.store.findOne(ctx, bson.M{})
+}
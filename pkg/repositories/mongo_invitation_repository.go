@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoInvitationRepository struct {
+	store mongoStore[models.Invitation]
+}
+
+// NewMongoInvitationRepository creates an InvitationRepository backed by
+// MongoDB.
+func NewMongoInvitationRepository(db *mongo.Database) InvitationRepository {
+	return &mongoInvitationRepository{store: newMongoStore[models.Invitation](db, InvitationsCollection)}
+}
+
+func (r *mongoInvitationRepository) Create(ctx context.Context, invitation *models.Invitation) error {
+	return r.store.insert(ctx, invitation)
+}
+
+func (r *mongoInvitationRepository) Update(ctx context.Context, invitation *models.Invitation) error {
+	return r.store.replace(ctx, invitation.ID, invitation)
+}
+
+func (r *mongoInvitationRepository) FindByID(ctx context.Context, id string) (*models.Invitation, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoInvitationRepository) FindAll(ctx context.Context) ([]models.Invitation, error) {
+	return r.store.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoLoginChallengeRepository struct {
+	store mongoStore[models.LoginChallenge]
+}
+
+// NewMongoLoginChallengeRepository creates a LoginChallengeRepository backed
+// by MongoDB.
+func NewMongoLoginChallengeRepository(db *mongo.Database) LoginChallengeRepository {
+	return &mongoLoginChallengeRepository{store: newMongoStore[models.LoginChallenge](db, LoginChallengesCollection)}
+}
+
+func (r *mongoLoginChallengeRepository) Create(ctx context.Context, challenge *models.LoginChallenge) error {
+	return r.store.insert(ctx, challenge)
+}
+
+func (r *mongoLoginChallengeRepository) Update(ctx context.Context, challenge *models.LoginChallenge) error {
+	return r.store.replace(ctx, challenge.ID, challenge)
+}
+
+func (r *mongoLoginChallengeRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoLoginChallengeRepository) FindByID(ctx context.Context, id string) (*models.LoginChallenge, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
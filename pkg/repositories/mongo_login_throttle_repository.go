@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoLoginThrottleRepository struct {
+	store mongoStore[models.LoginThrottle]
+}
+
+// NewMongoLoginThrottleRepository creates a LoginThrottleRepository backed
+// by MongoDB.
+func NewMongoLoginThrottleRepository(db *mongo.Database) LoginThrottleRepository {
+	return &mongoLoginThrottleRepository{store: newMongoStore[models.LoginThrottle](db, LoginThrottlesCollection)}
+}
+
+func (r *mongoLoginThrottleRepository) RecordFailure(ctx context.Context, kind, subject string, now time.Time, window time.Duration) (*models.LoginThrottle, error) {
+	id := models.LoginThrottleID(kind, subject)
+	// Forget stale counters first; MongoDB only removes expired documents
+	// about once a minute.
+	_, err := r.store.collection.DeleteOne(ctx, bson.M{
+		"_id":             id,
+		"last_failure_at": bson.M{"$lt": now.Add(-window)},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"$inc":         bson.M{"failures": 1},
+		"$set":         bson.M{"last_failure_at": now, "expires_at": now.Add(window)},
+		"$setOnInsert": bson.M{"kind": kind, "subject": subject},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var throttle models.LoginThrottle
+	err = r.store.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&throttle)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent upsert created the document first; update it.
+		err = r.store.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&throttle)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *mongoLoginThrottleRepository) Update(ctx context.Context, throttle *models.LoginThrottle) error {
+	return r.store.replace(ctx, throttle.ID, throttle)
+}
+
+func (r *mongoLoginThrottleRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoLoginThrottleRepository) FindByID(ctx context.Context, id string) (*models.LoginThrottle, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoLoginThrottleRepository) FindLocked(ctx context.Context, now time.Time) ([]models.LoginThrottle, error) {
+	return r.store.find(ctx, bson.M{"locked_until": bson.M{"$gt": now}}, options.Find().SetSort(bson.D{{Key: "locked_until", Value: -1}}))
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoOIDCLoginRepository struct {
+	store mongoStore[models.OIDCLogin]
+}
+
+// NewMongoOIDCLoginRepository creates an OIDCLoginRepository backed by
+// MongoDB.
+func NewMongoOIDCLoginRepository(db *mongo.Database) OIDCLoginRepository {
+	return &mongoOIDCLoginRepository{store: newMongoStore[models.OIDCLogin](db, OIDCLoginsCollection)}
+}
+
+func (r *mongoOIDCLoginRepository) Create(ctx context.Context, login *models.OIDCLogin) error {
+	return r.store.insert(ctx, login)
+}
+
+func (r *mongoOIDCLoginRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoOIDCLoginRepository) FindByID(ctx context.Context, id string) (*models.OIDCLogin, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoPasswordResetRepository struct {
+	store mongoStore[models.PasswordReset]
+}
+
+// NewMongoPasswordResetRepository creates a PasswordResetRepository backed
+// by MongoDB.
+func NewMongoPasswordResetRepository(db *mongo.Database) PasswordResetRepository {
+	return &mongoPasswordResetRepository{store: newMongoStore[models.PasswordReset](db, PasswordResetsCollection)}
+}
+
+func (r *mongoPasswordResetRepository) Create(ctx context.Context, reset *models.PasswordReset) error {
+	return r.store.insert(ctx, reset)
+}
+
+func (r *mongoPasswordResetRepository) Update(ctx context.Context, reset *models.PasswordReset) error {
+	return r.store.replace(ctx, reset.ID, reset)
+}
+
+func (r *mongoPasswordResetRepository) FindByID(ctx context.Context, id string) (*models.PasswordReset, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoPasswordResetRepository) MarkAllUsedForUser(ctx context.Context, userID string, usedAt time.Time) error {
+	_, err := r.store.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": usedAt}},
+	)
+	return err
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoPendingUploadRepository struct {
+	store mongoStore[models.PendingUpload]
+}
+
+// NewMongoPendingUploadRepository creates a PendingUploadRepository backed by
+// MongoDB.
+func NewMongoPendingUploadRepository(db *mongo.Database) PendingUploadRepository {
+	return &mongoPendingUploadRepository{store: newMongoStore[models.PendingUpload](db, PendingUploadsCollection)}
+}
+
+func (r *mongoPendingUploadRepository) Create(ctx context.Context, upload *models.PendingUpload) error {
+	return r.store.insert(ctx, upload)
+}
+
+func (r *mongoPendingUploadRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoPendingUploadRepository) FindByID(ctx context.Context, id string) (*models.PendingUpload, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
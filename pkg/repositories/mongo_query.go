@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// list returns a page of the documents matching the query.
+func (s mongoStore[T]) list(ctx context.Context, q ListQuery) (*Page[T], error) {
+	after, err := decodeCursor(q.Cursor, q.Sort.Field)
+	if err != nil {
+		return nil, err
+	}
+	direction := 1
+	if q.Sort.Desc {
+		direction = -1
+	}
+	limit := q.limit()
+	opts := options.Find().
+		SetSort(bson.D{{Key: q.Sort.Field, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := s.collection.Find(ctx, mongoQuery(q, after), opts)
+	if err != nil {
+		return nil, err
+	}
+	var raws []bson.Raw
+	if err := cursor.All(ctx, &raws); err != nil {
+		return nil, err
+	}
+	return newPage[T](raws, q.Sort.Field, limit)
+}
+
+// newPage decodes up to limit documents. Documents beyond the limit only
+// tell that there is a next page.
+func newPage[T any](raws []bson.Raw, sortField string, limit int) (*Page[T], error) {
+	page := &Page[T]{Items: []T{}}
+	for i, raw := range raws {
+		if i == limit {
+			next, err := encodeCursor(sortField, raws[limit-1])
+			if err != nil {
+				return nil, err
+			}
+			page.NextCursor = next
+			break
+		}
+		var doc T
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, doc)
+	}
+	return page, nil
+}
+
+// mongoQuery translates the filters of a query and the cursor position into
+// a MongoDB filter.
+func mongoQuery(q ListQuery, after *pageCursor) bson.M {
+	and := mongoFilters(q.Filters)
+	if len(q.AnyOf) > 0 {
+		or := make(bson.A, 0, len(q.AnyOf))
+		for _, group := range q.AnyOf {
+			if len(group) == 0 {
+				or = append(or, bson.M{})
+				continue
+			}
+			or = append(or, bson.M{"$and": mongoFilters(group)})
+		}
+		and = append(and, bson.M{"$or": or})
+	}
+	if after != nil {
+		op := "$gt"
+		if q.Sort.Desc {
+			op = "$lt"
+		}
+		and = append(and, bson.M{"$or": bson.A{
+			bson.M{q.Sort.Field: bson.M{op: after.Value}},
+			bson.M{q.Sort.Field: after.Value, "_id": bson.M{op: after.ID}},
+		}})
+	}
+	if len(and) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"$and": and}
+}
+
+func mongoFilters(filters []Filter) bson.A {
+	and := make(bson.A, 0, len(filters))
+	for _, f := range filters {
+		if f.Op == OpEq {
+			and = append(and, bson.M{f.Field: f.Value})
+			continue
+		}
+		and = append(and, bson.M{f.Field: bson.M{"$" + string(f.Op): f.Value}})
+	}
+	return and
+}
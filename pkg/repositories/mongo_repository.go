@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection names shared by the MongoDB repositories.
+const (
+	UsersCollection           = "users"
+	BlogsCollection           = "blogs"
+	BlogRevisionsCollection   = "blog_revisions"
+	TagsCollection            = "tags"
+	CategoriesCollection      = "categories"
+	VideosCollection          = "videos"
+	PendingUploadsCollection  = "pending_uploads"
+	HeroesCollection          = "heroes"
+	AboutsCollection          = "abouts"
+	ServicesCollection        = "services"
+	SessionsCollection        = "sessions"
+	InvitationsCollection     = "invitations"
+	PasswordResetsCollection  = "password_resets"
+	LoginChallengesCollection = "login_challenges"
+	LoginThrottlesCollection  = "login_throttles"
+	AuditCollection           = "audit_log"
+	SigningKeysCollection     = "signing_keys"
+	APIKeysCollection         = "api_keys"
+	OIDCLoginsCollection      = "oidc_logins"
+)
+
+// NewMongoRepositories builds every repository on top of a single database.
+func NewMongoRepositories(db *mongo.Database) *Repositories {
+	return &Repositories{
+		Users:           NewMongoUserRepository(db),
+		Blogs:           NewMongoBlogRepository(db),
+		BlogRevisions:   NewMongoBlogRevisionRepository(db),
+		Tags:            NewMongoTagRepository(db),
+		Categories:      NewMongoCategoryRepository(db),
+		Videos:          NewMongoVideoRepository(db),
+		PendingUploads:  NewMongoPendingUploadRepository(db),
+		Heroes:          NewMongoHeroRepository(db),
+		Abouts:          NewMongoAboutRepository(db),
+		Services:        NewMongoServiceRepository(db),
+		Sessions:        NewMongoSessionRepository(db),
+		Invitations:     NewMongoInvitationRepository(db),
+		PasswordResets:  NewMongoPasswordResetRepository(db),
+		LoginChallenges: NewMongoLoginChallengeRepository(db),
+		LoginThrottles:  NewMongoLoginThrottleRepository(db),
+		Audit:           NewMongoAuditRepository(db),
+		SigningKeys:     NewMongoSigningKeyRepository(db),
+		APIKeys:         NewMongoAPIKeyRepository(db),
+		OIDCLogins:      NewMongoOIDCLoginRepository(db),
+	}
+}
+
+// EnsureMongoIndexes creates the indexes the repositories rely on.
+func EnsureMongoIndexes(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		UsersCollection: {
+			{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{
+				Keys:    bson.D{{Key: "oidc_issuer", Value: 1}, {Key: "oidc_subject", Value: 1}},
+				Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"oidc_subject": bson.M{"$exists": true}}),
+			},
+		},
+		BlogsCollection: {
+			{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "publish_at", Value: 1}}},
+			{Keys: bson.D{{Key: "tags", Value: 1}}},
+			{Keys: bson.D{{Key: "categories", Value: 1}}},
+			textIndex(blogTextWeights),
+		},
+		VideosCollection: {
+			textIndex(videoTextWeights),
+		},
+		ServicesCollection: {
+			textIndex(serviceTextWeights),
+		},
+		BlogRevisionsCollection: {
+			{Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "number", Value: -1}}, Options: options.Index().SetUnique(true)},
+		},
+		TagsCollection: {
+			{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		CategoriesCollection: {
+			{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "parent_id", Value: 1}}},
+		},
+		SessionsCollection: {
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			// Expired sessions are removed by MongoDB.
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		PasswordResetsCollection: {
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		LoginChallengesCollection: {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		LoginThrottlesCollection: {
+			{Keys: bson.D{{Key: "locked_until", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		AuditCollection: {
+			{Keys: bson.D{{Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "resource_type", Value: 1}, {Key: "resource_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		SigningKeysCollection: {
+			// Keys are removed once they no longer verify.
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		APIKeysCollection: {
+			{Keys: bson.D{{Key: "prefix", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		PendingUploadsCollection: {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		OIDCLoginsCollection: {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+	}
+	for name, models := range indexes {
+		if _, err := db.Collection(name).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("failed to create indexes on %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// mongoStore implements the CRUD operations shared by every MongoDB repository.
+type mongoStore[T any] struct {
+	collection *mongo.Collection
+}
+
+func newMongoStore[T any](db *mongo.Database, name string) mongoStore[T] {
+	return mongoStore[T]{collection: db.Collection(name)}
+}
+
+func (s mongoStore[T]) insert(ctx context.Context, doc *T) error {
+	_, err := s.collection.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+func (s mongoStore[T]) replace(ctx context.Context, id string, doc *T) error {
+	result, err := s.collection.ReplaceOne(ctx, bson.M{"_id": id}, doc)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateKey
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s mongoStore[T]) delete(ctx context.Context, id string) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s mongoStore[T]) findOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) (*T, error) {
+	var doc T
+	err := s.collection.FindOne(ctx, filter, opts...).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (s mongoStore[T]) find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := s.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	docs := []T{}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
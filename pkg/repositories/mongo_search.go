@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// search returns the documents matching a text search, best first, using
+// the text index of the collection. Only the filters and the limit of the
+// query apply.
+func (s mongoStore[T]) search(ctx context.Context, text string, q ListQuery) ([]SearchHit[T], error) {
+	filter := mongoQuery(q, nil)
+	filter["$text"] = bson.M{"$search": text}
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"_score": score}).
+		SetSort(bson.D{{Key: "_score", Value: score}}).
+		SetLimit(int64(q.limit()))
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var raws []bson.Raw
+	if err := cursor.All(ctx, &raws); err != nil {
+		return nil, err
+	}
+	hits := make([]SearchHit[T], 0, len(raws))
+	for _, raw := range raws {
+		var doc T
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		score, _ := lookupField(raw, "_score").DoubleOK()
+		hits = append(hits, SearchHit[T]{Item: doc, Score: score})
+	}
+	return hits, nil
+}
+
+// textIndex returns the text index of a collection with the given field
+// weights. A collection has at most one text index.
+func textIndex(weights map[string]float64) mongo.IndexModel {
+	fields := make([]string, 0, len(weights))
+	for field := range weights {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	keys, indexWeights := bson.D{}, bson.D{}
+	for _, field := range fields {
+		keys = append(keys, bson.E{Key: field, Value: "text"})
+		indexWeights = append(indexWeights, bson.E{Key: field, Value: int(weights[field])})
+	}
+	return mongo.IndexModel{Keys: keys, Options: options.Index().SetName("text_search").SetWeights(indexWeights)}
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoServiceRepository struct {
+	store mongoStore[models.Service]
+}
+
+// NewMongoServiceRepository creates a ServiceRepository backed by MongoDB.
+func NewMongoServiceRepository(db *mongo.Database) ServiceRepository {
+	return &mongoServiceRepository{store: newMongoStore[models.Service](db, ServicesCollection)}
+}
+
+func (r *mongoServiceRepository) Create(ctx context.Context, service *models.Service) error {
+	return r.store.insert(ctx, service)
+}
+
+func (r *mongoServiceRepository) Update(ctx context.Context, service *models.Service) error {
+	return r.store.replace(ctx, service.ID, service)
+}
+
+func (r *mongoServiceRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoServiceRepository) FindByID(ctx context.Context, id string) (*models.Service, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoServiceRepository) FindAll(ctx context.Context) ([]models.Service, error) {
+	return r.store.find(ctx, bson.M{})
+}
+
+func (r *mongoServiceRepository) List(ctx context.Context, query ListQuery) (*Page[models.Service], error) {
+	return r.store.list(ctx, query)
+}
+
+func (r *mongoServiceRepository) Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Service], error) {
+	return r.store.search(ctx, text, query)
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoSessionRepository struct {
+	store mongoStore[models.Session]
+}
+
+// NewMongoSessionRepository creates a SessionRepository backed by MongoDB.
+func NewMongoSessionRepository(db *mongo.Database) SessionRepository {
+	return &mongoSessionRepository{store: newMongoStore[models.Session](db, SessionsCollection)}
+}
+
+func (r *mongoSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	return r.store.insert(ctx, session)
+}
+
+func (r *mongoSessionRepository) Rotate(ctx context.Context, session *models.Session, previousHash string) error {
+	result, err := r.store.collection.ReplaceOne(ctx, bson.M{
+		"_id":                session.ID,
+		"refresh_token_hash": previousHash,
+		"revoked_at":         bson.M{"$exists": false},
+	}, session)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoSessionRepository) Revoke(ctx context.Context, id string, revokedAt time.Time, reason string) error {
+	result, err := r.store.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": revokedAt, "revoked_reason": reason}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoSessionRepository) FindByID(ctx context.Context, id string) (*models.Session, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoSessionRepository) RevokeAllForUser(ctx context.Context, userID string, revokedAt time.Time, reason string) error {
+	_, err := r.store.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": revokedAt, "revoked_reason": reason}},
+	)
+	return err
+}
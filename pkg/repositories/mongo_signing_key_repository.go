@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoSigningKeyRepository struct {
+	store mongoStore[models.SigningKey]
+}
+
+// NewMongoSigningKeyRepository creates a SigningKeyRepository backed by
+// MongoDB.
+func NewMongoSigningKeyRepository(db *mongo.Database) SigningKeyRepository {
+	return &mongoSigningKeyRepository{store: newMongoStore[models.SigningKey](db, SigningKeysCollection)}
+}
+
+func (r *mongoSigningKeyRepository) Create(ctx context.Context, key *models.SigningKey) error {
+	return r.store.insert(ctx, key)
+}
+
+func (r *mongoSigningKeyRepository) Update(ctx context.Context, key *models.SigningKey) error {
+	return r.store.replace(ctx, key.ID, key)
+}
+
+func (r *mongoSigningKeyRepository) FindAll(ctx context.Context) ([]models.SigningKey, error) {
+	return r.store.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
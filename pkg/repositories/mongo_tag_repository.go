@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoTagRepository struct {
+	store mongoStore[models.Tag]
+}
+
+// NewMongoTagRepository creates a TagRepository backed by MongoDB.
+func NewMongoTagRepository(db *mongo.Database) TagRepository {
+	return &mongoTagRepository{store: newMongoStore[models.Tag](db, TagsCollection)}
+}
+
+func (r *mongoTagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	return r.store.insert(ctx, tag)
+}
+
+func (r *mongoTagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	return r.store.replace(ctx, tag.ID, tag)
+}
+
+func (r *mongoTagRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoTagRepository) FindByID(ctx context.Context, id string) (*models.Tag, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoTagRepository) FindBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	return r.store.findOne(ctx, bson.M{"slug": slug})
+}
+
+func (r *mongoTagRepository) FindAll(ctx context.Context) ([]models.Tag, error) {
+	return r.store.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+}
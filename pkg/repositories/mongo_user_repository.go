@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoUserRepository struct {
+	store mongoStore[models.User]
+}
+
+// NewMongoUserRepository creates a UserRepository backed by MongoDB.
+func NewMongoUserRepository(db *mongo.Database) UserRepository {
+	return &mongoUserRepository{store: newMongoStore[models.User](db, UsersCollection)}
+}
+
+func (r *mongoUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.store.insert(ctx, user)
+}
+
+func (r *mongoUserRepository) Update(ctx context.Context, user *models.User) error {
+	return r.store.replace(ctx, user.ID, user)
+}
+
+func (r *mongoUserRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoUserRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.store.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *mongoUserRepository) FindByOIDCSubject(ctx context.Context, issuer, subject string) (*models.User, error) {
+	return r.store.findOne(ctx, bson.M{"oidc_issuer": issuer, "oidc_subject": subject})
+}
+
+func (r *mongoUserRepository) FindAll(ctx context.Context) ([]models.User, error) {
+	return r.store.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "email", Value: 1}}))
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+type mongoVideoRepository struct {
+	store mongoStore[models.Video]
+}
+
+// NewMongoVideoRepository creates a VideoRepository backed by MongoDB.
+func NewMongoVideoRepository(db *mongo.Database) VideoRepository {
+	return &mongoVideoRepository{store: newMongoStore[models.Video](db, VideosCollection)}
+}
+
+func (r *mongoVideoRepository) Create(ctx context.Context, video *models.Video) error {
+	return r.store.insert(ctx, video)
+}
+
+func (r *mongoVideoRepository) Update(ctx context.Context, video *models.Video) error {
+	return r.store.replace(ctx, video.ID, video)
+}
+
+func (r *mongoVideoRepository) Delete(ctx context.Context, id string) error {
+	return r.store.delete(ctx, id)
+}
+
+func (r *mongoVideoRepository) FindByID(ctx context.Context, id string) (*models.Video, error) {
+	return r.store.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoVideoRepository) FindAll(ctx context.Context) ([]models.Video, error) {
+	return r.store.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
+
+func (r *mongoVideoRepository) List(ctx context.Context, query ListQuery) (*Page[models.Video], error) {
+	return r.store.list(ctx, query)
+}
+
+func (r *mongoVideoRepository) Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Video], error) {
+	return r.store.search(ctx, text, query)
+}
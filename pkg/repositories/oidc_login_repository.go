@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// OIDCLoginRepository persists pending identity provider sign-ins.
+type OIDCLoginRepository interface {
+	Create(ctx context.Context, login *models.OIDCLogin) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.OIDCLogin, error)
+}
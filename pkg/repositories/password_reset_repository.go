@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// PasswordResetRepository persists password reset requests.
+type PasswordResetRepository interface {
+	Create(ctx context.Context, reset *models.PasswordReset) error
+	Update(ctx context.Context, reset *models.PasswordReset) error
+	FindByID(ctx context.Context, id string) (*models.PasswordReset, error)
+	// MarkAllUsedForUser invalidates every unused reset of a user.
+	MarkAllUsedForUser(ctx context.Context, userID string, usedAt time.Time) error
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// PendingUploadRepository persists the direct uploads that have been issued
+// but not completed.
+type PendingUploadRepository interface {
+	Create(ctx context.Context, upload *models.PendingUpload) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.PendingUpload, error)
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const (
+	// DefaultPageSize is the page size of list queries without a limit.
+	DefaultPageSize = 20
+	// MaxPageSize is the largest page a list query returns.
+	MaxPageSize = 100
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed or were
+// issued for another sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// FilterOp compares a field with a value.
+type FilterOp string
+
+const (
+	// OpEq matches equal values. Array fields match if any element is equal.
+	OpEq FilterOp = "eq"
+	// OpIn matches any of the values in a []interface{}. A nil value matches
+	// missing fields.
+	OpIn  FilterOp = "in"
+	OpGt  FilterOp = "gt"
+	OpGte FilterOp = "gte"
+	OpLt  FilterOp = "lt"
+	OpLte FilterOp = "lte"
+)
+
+// Filter compares a field, given by its BSON name, with a value.
+type Filter struct {
+	Field string
+	Op    FilterOp
+	Value interface{}
+}
+
+// Sort orders a list by a field. Ties are broken by ID in the same
+// direction. The field must be present in every record.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery selects a page of records. Records match if they pass every
+// filter and, if AnyOf is set, every filter of at least one of its groups.
+type ListQuery struct {
+	Filters []Filter
+	AnyOf   [][]Filter
+	Sort    Sort
+	// Limit defaults to DefaultPageSize and is capped at MaxPageSize.
+	Limit int
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+}
+
+// Page is a page of records. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// limit returns the page size to use.
+func (q ListQuery) limit() int {
+	if q.Limit <= 0 {
+		return DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return q.Limit
+}
+
+// pageCursor is the position after the last record of a page.
+type pageCursor struct {
+	Field string        `bson:"f"`
+	Value bson.RawValue `bson:"v"`
+	ID    bson.RawValue `bson:"id"`
+}
+
+// encodeCursor returns the cursor of the page ending with doc.
+func encodeCursor(field string, doc bson.Raw) (string, error) {
+	raw, err := bson.Marshal(pageCursor{Field: field, Value: lookupField(doc, field), ID: lookupField(doc, "_id")})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor returns the position of a cursor, or nil for the first page.
+func decodeCursor(cursor, field string) (*pageCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var position pageCursor
+	if err := bson.Unmarshal(raw, &position); err != nil || position.Field != field {
+		return nil, ErrInvalidCursor
+	}
+	// Lists sort by string and time fields, and records have string IDs.
+	// Any other value, such as an operator document, did not come from
+	// encodeCursor and must not reach a query.
+	sortable := position.Value.Type == bsontype.String || position.Value.Type == bsontype.DateTime
+	if !sortable || position.ID.Type != bsontype.String || position.Value.Validate() != nil || position.ID.Validate() != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &position, nil
+}
+
+// lookupField returns a field of a document, or null if it is missing.
+func lookupField(doc bson.Raw, field string) bson.RawValue {
+	value, err := doc.LookupErr(field)
+	if err != nil {
+		return bson.RawValue{Type: bsontype.Null}
+	}
+	return value
+}
+
+// SearchHit is a record matching a text search. A higher score means a
+// better match.
+type SearchHit[T any] struct {
+	Item  T
+	Score float64
+}
+
+// Text search weights of the searchable collections, shared by the MongoDB
+// text indexes and their in-memory counterparts.
+var (
+	blogTextWeights    = map[string]float64{"title": 10, "content": 1}
+	videoTextWeights   = map[string]float64{"title": 10, "content": 1}
+	serviceTextWeights = map[string]float64{"name": 10, "location": 5, "description": 1}
+)
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// testBlogRepositories returns empty blog repositories of every backend:
+// in memory, and in a scratch MongoDB database when MONGO_TEST_URI is set.
+func testBlogRepositories(t *testing.T) map[string]BlogRepository {
+	t.Helper()
+	memory, err := NewMemoryDatabase("")
+	if err != nil {
+		t.Fatalf("NewMemoryDatabase: %v", err)
+	}
+	repos := map[string]BlogRepository{"memory": NewMemoryBlogRepository(memory)}
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Log("MONGO_TEST_URI is not set, skipping MongoDB")
+		return repos
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	db := client.Database(fmt.Sprintf("repositories_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		db.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	repos["mongo"] = NewMongoBlogRepository(db)
+	return repos
+}
+
+// testCursor encodes a cursor document the way encodeCursor does.
+func testCursor(t *testing.T, doc bson.M) string {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  string
+		field   string
+		wantErr bool
+	}{
+		{name: "first page", field: "title"},
+		{name: "string value", cursor: testCursor(t, bson.M{"f": "title", "v": "Post", "id": "b"}), field: "title"},
+		{name: "time value", cursor: testCursor(t, bson.M{"f": "created_at", "v": time.Now(), "id": "b"}), field: "created_at"},
+		{name: "other field", cursor: testCursor(t, bson.M{"f": "title", "v": "Post", "id": "b"}), field: "created_at", wantErr: true},
+		{name: "operator value", cursor: testCursor(t, bson.M{"f": "title", "v": bson.M{"$ne": nil}, "id": "b"}), field: "title", wantErr: true},
+		{name: "array value", cursor: testCursor(t, bson.M{"f": "title", "v": bson.A{"Post"}, "id": "b"}), field: "title", wantErr: true},
+		{name: "number value", cursor: testCursor(t, bson.M{"f": "title", "v": 1, "id": "b"}), field: "title", wantErr: true},
+		{name: "no value", cursor: testCursor(t, bson.M{"f": "title", "id": "b"}), field: "title", wantErr: true},
+		{name: "operator ID", cursor: testCursor(t, bson.M{"f": "title", "v": "Post", "id": bson.M{"$gt": ""}}), field: "title", wantErr: true},
+		{name: "number ID", cursor: testCursor(t, bson.M{"f": "title", "v": "Post", "id": 1}), field: "title", wantErr: true},
+		{name: "not base64", cursor: "!!!", field: "title", wantErr: true},
+		{name: "not BSON", cursor: base64.RawURLEncoding.EncodeToString([]byte("cursor")), field: "title", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeCursor(tt.cursor, tt.field)
+			if tt.wantErr && !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeCursor: got error %v, want %v", err, ErrInvalidCursor)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("decodeCursor: %v", err)
+			}
+		})
+	}
+}
+
+func TestListPagesAcrossEqualSortValues(t *testing.T) {
+	for backend, blogs := range testBlogRepositories(t) {
+		t.Run(backend, func(t *testing.T) {
+			ctx := context.Background()
+			// Blogs b to f share their creation time and title.
+			same := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			for _, blog := range []models.Blog{
+				{ID: "d", Title: "Post", CreatedAt: same},
+				{ID: "a", Title: "Alpha", CreatedAt: same.Add(-time.Hour)},
+				{ID: "f", Title: "Post", CreatedAt: same},
+				{ID: "b", Title: "Post", CreatedAt: same},
+				{ID: "g", Title: "Zulu", CreatedAt: same.Add(time.Hour)},
+				{ID: "e", Title: "Post", CreatedAt: same},
+				{ID: "c", Title: "Post", CreatedAt: same},
+			} {
+				blog.Slug = blog.ID
+				if err := blogs.Create(ctx, &blog); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			tests := []struct {
+				name string
+				sort Sort
+				want []string
+			}{
+				{name: "time ascending", sort: Sort{Field: "created_at"}, want: []string{"a", "b", "c", "d", "e", "f", "g"}},
+				{name: "time descending", sort: Sort{Field: "created_at", Desc: true}, want: []string{"g", "f", "e", "d", "c", "b", "a"}},
+				{name: "title ascending", sort: Sort{Field: "title"}, want: []string{"a", "b", "c", "d", "e", "f", "g"}},
+				{name: "title descending", sort: Sort{Field: "title", Desc: true}, want: []string{"g", "f", "e", "d", "c", "b", "a"}},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					query := ListQuery{Sort: tt.sort, Limit: 2}
+					var got []string
+					for {
+						page, err := blogs.List(ctx, query)
+						if err != nil {
+							t.Fatalf("List: %v", err)
+						}
+						if len(page.Items) > query.Limit {
+							t.Fatalf("page: got %d blogs, want at most %d", len(page.Items), query.Limit)
+						}
+						for _, blog := range page.Items {
+							got = append(got, blog.ID)
+						}
+						if page.NextCursor == "" {
+							break
+						}
+						query.Cursor = page.NextCursor
+					}
+					if !reflect.DeepEqual(got, tt.want) {
+						t.Errorf("blogs: got %v, want %v", got, tt.want)
+					}
+				})
+			}
+
+			t.Run("operator cursor", func(t *testing.T) {
+				cursor := testCursor(t, bson.M{"f": "title", "v": bson.M{"$ne": nil}, "id": bson.M{"$ne": nil}})
+				_, err := blogs.List(ctx, ListQuery{Sort: Sort{Field: "title"}, Cursor: cursor})
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Errorf("List: got error %v, want %v", err, ErrInvalidCursor)
+				}
+			})
+		})
+	}
+}
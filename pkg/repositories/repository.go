@@ -0,0 +1,33 @@
+package repositories
+
+import "errors"
+
+// ErrNotFound is returned when the requested record does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicateKey is returned when a record violates a unique constraint.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// Repositories groups the repositories for every content type so they can be
+// constructed together and handed to the controllers.
+type Repositories struct {
+	Users           UserRepository
+	Blogs           BlogRepository
+	BlogRevisions   BlogRevisionRepository
+	Tags            TagRepository
+	Categories      CategoryRepository
+	Videos          VideoRepository
+	PendingUploads  PendingUploadRepository
+	Heroes          HeroRepository
+	Abouts          AboutRepository
+	Services        ServiceRepository
+	Sessions        SessionRepository
+	Invitations     InvitationRepository
+	PasswordResets  PasswordResetRepository
+	LoginChallenges LoginChallengeRepository
+	LoginThrottles  LoginThrottleRepository
+	Audit           AuditRepository
+	SigningKeys     SigningKeyRepository
+	APIKeys         APIKeyRepository
+	OIDCLogins      OIDCLoginRepository
+}
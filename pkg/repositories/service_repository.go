@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// ServiceRepository persists the services offered on the site.
+type ServiceRepository interface {
+	Create(ctx context.Context, service *models.Service) error
+	Update(ctx context.Context, service *models.Service) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.Service, error)
+	FindAll(ctx context.Context) ([]models.Service, error)
+	// List returns a page of the services matching the query.
+	List(ctx context.Context, query ListQuery) (*Page[models.Service], error)
+	// Search returns the services matching a text search, best first. Only the
+	// filters and the limit of the query apply.
+	Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Service], error)
+}
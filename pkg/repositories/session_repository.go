@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// SessionRepository persists login sessions and their refresh tokens.
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	// Rotate replaces a session whose refresh token hash is still
+	// previousHash and which is not revoked. Otherwise, for example when a
+	// concurrent refresh rotated the token first, it returns ErrNotFound.
+	Rotate(ctx context.Context, session *models.Session, previousHash string) error
+	// Revoke revokes a session unless it is already revoked, in which case it
+	// returns ErrNotFound.
+	Revoke(ctx context.Context, id string, revokedAt time.Time, reason string) error
+	FindByID(ctx context.Context, id string) (*models.Session, error)
+	// RevokeAllForUser revokes every active session of a user.
+	RevokeAllForUser(ctx context.Context, userID string, revokedAt time.Time, reason string) error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// SigningKeyRepository persists the keys that sign access tokens so every
+// instance of the API uses the same ones.
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *models.SigningKey) error
+	Update(ctx context.Context, key *models.SigningKey) error
+	// FindAll returns every key, newest first.
+	FindAll(ctx context.Context) ([]models.SigningKey, error)
+}
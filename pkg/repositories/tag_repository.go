@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// TagRepository persists blog tags. Slugs are unique.
+type TagRepository interface {
+	Create(ctx context.Context, tag *models.Tag) error
+	Update(ctx context.Context, tag *models.Tag) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.Tag, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Tag, error)
+	// FindAll returns every tag, sorted by name.
+	FindAll(ctx context.Context) ([]models.Tag, error)
+}
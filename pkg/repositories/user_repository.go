@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// UserRepository persists user accounts.
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindByOIDCSubject returns the user linked to an identity provider
+	// account.
+	FindByOIDCSubject(ctx context.Context, issuer, subject string) (*models.User, error)
+	FindAll(ctx context.Context) ([]models.User, error)
+}
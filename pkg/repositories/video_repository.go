@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+// VideoRepository persists videos.
+type VideoRepository interface {
+	Create(ctx context.Context, video *models.Video) error
+	Update(ctx context.Context, video *models.Video) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.Video, error)
+	FindAll(ctx context.Context) ([]models.Video, error)
+	// List returns a page of the videos matching the query.
+	List(ctx context.Context, query ListQuery) (*Page[models.Video], error)
+	// Search returns the videos matching a text search, best first. Only the
+	// filters and the limit of the query apply.
+	Search(ctx context.Context, text string, query ListQuery) ([]SearchHit[models.Video], error)
+}
@@ -2,21 +2,24 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/jtonynet/api-alura-challenge-backend/pkg/controllers"
-	"github.com/jtonynet/api-alura-challenge-backend/pkg/middlewares"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
 )
 
-func AboutRoutes(router *gin.Engine, aboutController *controllers.AboutController) {
+func AboutRoutes(router *gin.Engine, aboutController *controllers.AboutController, authMiddleware gin.HandlerFunc) {
 	about := router.Group("/api/about")
 	{
 		about.GET("", aboutController.GetAbout)
 
 		aboutAuth := about.Group("")
-		aboutAuth.Use(middlewares.AuthMiddleware())
+		aboutAuth.Use(authMiddleware, middlewares.RequirePermission(models.PermAboutWrite))
 		{
 			aboutAuth.POST("", aboutController.CreateAbout)
 			aboutAuth.PUT("/:id", aboutController.UpdateAbout)
+			aboutAuth.POST("/:id/image", aboutController.UploadImage)
 			aboutAuth.DELETE("/:id", aboutController.DeleteAbout)
 		}
 	}
-}
\ No newline at end of file
+}
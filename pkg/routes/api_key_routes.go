@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func APIKeyRoutes(router *gin.Engine, apiKeyController *controllers.APIKeyController, authMiddleware gin.HandlerFunc) {
+	// API keys cannot manage API keys.
+	apiKeyGroup := router.Group("/api-keys")
+	apiKeyGroup.Use(authMiddleware, middlewares.RequireSession(), middlewares.RequirePermission(models.PermUsersManage))
+	{
+		apiKeyGroup.GET("", apiKeyController.GetAPIKeys)
+		apiKeyGroup.POST("", apiKeyController.CreateAPIKey)
+		apiKeyGroup.DELETE("/:id", apiKeyController.RevokeAPIKey)
+	}
+}
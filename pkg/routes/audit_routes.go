@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func AuditRoutes(router *gin.Engine, auditController *controllers.AuditController, authMiddleware gin.HandlerFunc) {
+	router.GET("/audit-log", authMiddleware, middlewares.RequirePermission(models.PermAuditRead), auditController.GetAuditLog)
+}
@@ -1,24 +1,39 @@
 package routes
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
-	"github.com/yourusername/yourproject/pkg/controllers"
-	"github.com/yourusername/yourproject/pkg/middlewares"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
 )
 
-func BlogRoutes(router *gin.Engine, blogController *controllers.BlogController) {
+func BlogRoutes(router *gin.Engine, blogController *controllers.BlogController, authMiddleware gin.HandlerFunc) {
 	blogGroup := router.Group("/api/blogs")
 	{
 		blogGroup.GET("", blogController.GetAllBlogs)
 		blogGroup.GET("/:slug", blogController.GetBlogBySlug)
 
-		adminBlogGroup := blogGroup.Group("", middlewares.AuthMiddleware())
+		adminBlogGroup := blogGroup.Group("", authMiddleware)
 		{
-			adminBlogGroup.POST("", blogController.CreateBlog)
-			adminBlogGroup.PUT("/:id", blogController.UpdateBlog)
-			adminBlogGroup.DELETE("/:id", blogController.DeleteBlog)
+			canUpdate := middlewares.RequireAnyPermission(models.PermBlogsUpdate, models.PermBlogsUpdateOwn)
+			canDelete := middlewares.RequireAnyPermission(models.PermBlogsDelete, models.PermBlogsDeleteOwn)
+			adminBlogGroup.POST("", middlewares.RequirePermission(models.PermBlogsCreate), blogController.CreateBlog)
+			adminBlogGroup.PUT("/:id", canUpdate, blogController.UpdateBlog)
+			adminBlogGroup.POST("/:id/image", canUpdate, blogController.UploadImage)
+			adminBlogGroup.POST("/:id/status", canUpdate, blogController.UpdateBlogStatus)
+			adminBlogGroup.POST("/:id/revisions/:number/restore", canUpdate, blogController.RestoreBlogRevision)
+			adminBlogGroup.DELETE("/:id", canDelete, blogController.DeleteBlog)
 		}
 	}
-}
\ No newline at end of file
+
+	// Admin reads see blogs of every status.
+	adminGroup := router.Group("/api/admin/blogs", authMiddleware, middlewares.RequireAnyPermission(models.PermBlogsUpdate, models.PermBlogsUpdateOwn))
+	{
+		adminGroup.GET("", blogController.GetAdminBlogs)
+		adminGroup.GET("/:id", blogController.GetAdminBlog)
+		adminGroup.GET("/:id/revisions", blogController.GetBlogRevisions)
+		adminGroup.GET("/:id/revisions/diff", blogController.DiffBlogRevisions)
+		adminGroup.GET("/:id/revisions/:number", blogController.GetBlogRevision)
+	}
+}
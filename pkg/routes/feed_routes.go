@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+)
+
+func FeedRoutes(router *gin.Engine, feedController *controllers.FeedController) {
+	feedGroup := router.Group("/api/feeds")
+	{
+		feedGroup.GET("/blogs/:format", feedController.GetBlogFeed)
+		feedGroup.GET("/videos/:category/:format", feedController.GetVideoFeed)
+	}
+}
@@ -1,53 +1,23 @@
 package routes
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
-	"your-module-path/pkg/controllers"
-	"your-module-path/pkg/middlewares"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
 )
 
-func HeroRoutes(router *gin.Engine, heroController controllers.HeroController) {
+func HeroRoutes(router *gin.Engine, heroController *controllers.HeroController, authMiddleware gin.HandlerFunc) {
 	heroGroup := router.Group("/hero")
 	{
-		heroGroup.GET("/", func(c *gin.Context) {
-			hero, err := heroController.GetHeroSection(c)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			c.JSON(http.StatusOK, hero)
-		})
+		heroGroup.GET("", heroController.GetHero)
 
-		adminGroup := heroGroup.Group("/", middlewares.AdminAuthMiddleware())
+		adminGroup := heroGroup.Group("", authMiddleware, middlewares.RequirePermission(models.PermHeroWrite))
 		{
-			adminGroup.POST("/", func(c *gin.Context) {
-				err := heroController.CreateHeroSection(c)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-					return
-				}
-				c.JSON(http.StatusCreated, gin.H{"message": "Hero section created successfully"})
-			})
-
-			adminGroup.DELETE("/:id", func(c *gin.Context) {
-				err := heroController.DeleteHeroSection(c)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-					return
-				}
-				c.JSON(http.StatusOK, gin.H{"message": "Hero section deleted successfully"})
-			})
-
-			adminGroup.PUT("/:id", func(c *gin.Context) {
-				err := heroController.UpdateHeroSection(c)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-					return
-				}
-				c.JSON(http.StatusOK, gin.H{"message": "Hero section updated successfully"})
-			})
+			adminGroup.POST("", heroController.CreateHero)
+			adminGroup.PUT("/:id", heroController.UpdateHero)
+			adminGroup.DELETE("/:id", heroController.DeleteHero)
 		}
 	}
-}
\ No newline at end of file
+}
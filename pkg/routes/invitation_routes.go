@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func InvitationRoutes(router *gin.Engine, invitationController *controllers.InvitationController, authMiddleware gin.HandlerFunc) {
+	invitationGroup := router.Group("/invitations")
+	invitationGroup.Use(authMiddleware, middlewares.RequirePermission(models.PermUsersManage))
+	{
+		invitationGroup.GET("", invitationController.GetInvitations)
+		invitationGroup.POST("", invitationController.CreateInvitation)
+		invitationGroup.DELETE("/:id", invitationController.RevokeInvitation)
+	}
+}
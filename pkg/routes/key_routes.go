@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func KeyRoutes(router *gin.Engine, keyController *controllers.KeyController, authMiddleware gin.HandlerFunc) {
+	router.GET("/.well-known/jwks.json", keyController.JWKS)
+
+	keyGroup := router.Group("/signing-keys")
+	keyGroup.Use(authMiddleware, middlewares.RequirePermission(models.PermUsersManage))
+	{
+		keyGroup.GET("", keyController.GetKeys)
+		keyGroup.POST("/rotate", keyController.RotateKeys)
+	}
+}
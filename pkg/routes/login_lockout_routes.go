@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func LoginLockoutRoutes(router *gin.Engine, loginLockoutController *controllers.LoginLockoutController, authMiddleware gin.HandlerFunc) {
+	canManage := middlewares.RequirePermission(models.PermUsersManage)
+	router.POST("/users/:id/unlock", authMiddleware, canManage, loginLockoutController.UnlockUser)
+
+	lockoutGroup := router.Group("/login-lockouts")
+	lockoutGroup.Use(authMiddleware, canManage)
+	{
+		lockoutGroup.GET("", loginLockoutController.GetLockouts)
+		lockoutGroup.DELETE("/:id", loginLockoutController.DeleteLockout)
+	}
+}
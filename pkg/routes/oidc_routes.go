@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+)
+
+func OIDCRoutes(router *gin.Engine, oidcController *controllers.OIDCController) {
+	oidcGroup := router.Group("/auth/oidc")
+	{
+		oidcGroup.GET("/login", oidcController.Login)
+		oidcGroup.GET("/callback", oidcController.Callback)
+	}
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+)
+
+func PasswordResetRoutes(router *gin.Engine, passwordResetController *controllers.PasswordResetController) {
+	resetGroup := router.Group("/users/password-reset")
+	{
+		resetGroup.POST("", passwordResetController.RequestReset)
+		resetGroup.POST("/confirm", passwordResetController.ConfirmReset)
+	}
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+)
+
+func SearchRoutes(router *gin.Engine, searchController *controllers.SearchController) {
+	router.GET("/api/search", searchController.Search)
+}
@@ -2,17 +2,20 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
-	"your_project_path/pkg/controllers"
-	"your_project_path/pkg/middlewares"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
 )
 
-func ServiceRoutes(router *gin.Engine, serviceController *controllers.ServiceController) {
+func ServiceRoutes(router *gin.Engine, serviceController *controllers.ServiceController, authMiddleware gin.HandlerFunc) {
 	serviceGroup := router.Group("/service")
 	{
-		serviceGroup.POST("", middlewares.AuthMiddleware, serviceController.CreateService)
+		canWrite := middlewares.RequirePermission(models.PermServicesWrite)
+		serviceGroup.POST("", authMiddleware, canWrite, serviceController.CreateService)
 		serviceGroup.GET("/:id", serviceController.GetService)
 		serviceGroup.GET("", serviceController.GetAllServices)
-		serviceGroup.DELETE("/:id", middlewares.AuthMiddleware, serviceController.DeleteService)
-		serviceGroup.PUT("/:id", middlewares.AuthMiddleware, serviceController.UpdateService)
+		serviceGroup.DELETE("/:id", authMiddleware, canWrite, serviceController.DeleteService)
+		serviceGroup.PUT("/:id", authMiddleware, canWrite, serviceController.UpdateService)
 	}
-}
\ No newline at end of file
+}
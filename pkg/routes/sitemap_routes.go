@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+)
+
+func SitemapRoutes(router *gin.Engine, sitemapController *controllers.SitemapController) {
+	router.GET("/sitemap.xml", sitemapController.GetSitemap)
+	router.GET("/sitemaps/:file", sitemapController.GetSitemapPart)
+	router.GET("/robots.txt", sitemapController.GetRobots)
+}
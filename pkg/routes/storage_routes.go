@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// StorageRoutes serves the files of a local storage backend from the path of
+// its base URL. Files are served as downloads the browser must not sniff, so
+// an uploaded file can never run as a page on the API origin; images and
+// videos embedded by the site still display.
+func StorageRoutes(router *gin.Engine, storage *utils.LocalStorage) error {
+	base, err := url.Parse(storage.BaseURL)
+	if err != nil {
+		return err
+	}
+	// Files served from the root would catch every route of the API.
+	path := strings.TrimSuffix(base.Path, "/")
+	if path == "" {
+		return fmt.Errorf("local storage URL %q must have a path other than /", storage.BaseURL)
+	}
+	files := router.Group(path, func(c *gin.Context) {
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("Content-Disposition", "attachment")
+		c.Next()
+	})
+	files.Static("/", storage.Dir)
+	return nil
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func TaxonomyRoutes(router *gin.Engine, taxonomyController *controllers.TaxonomyController, authMiddleware gin.HandlerFunc) {
+	canManage := middlewares.RequirePermission(models.PermTaxonomyManage)
+
+	tagGroup := router.Group("/api/tags")
+	{
+		tagGroup.GET("", taxonomyController.GetTags)
+		tagGroup.GET("/:slug/blogs", taxonomyController.GetTagBlogs)
+		tagGroup.PUT("/:id", authMiddleware, canManage, taxonomyController.RenameTag)
+		tagGroup.POST("/:id/merge", authMiddleware, canManage, taxonomyController.MergeTag)
+	}
+
+	categoryGroup := router.Group("/api/categories")
+	{
+		categoryGroup.GET("", taxonomyController.GetCategories)
+		categoryGroup.GET("/:slug/blogs", taxonomyController.GetCategoryBlogs)
+		categoryGroup.POST("", authMiddleware, canManage, taxonomyController.CreateCategory)
+		categoryGroup.PUT("/:id", authMiddleware, canManage, taxonomyController.UpdateCategory)
+		categoryGroup.DELETE("/:id", authMiddleware, canManage, taxonomyController.DeleteCategory)
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func TwoFactorRoutes(router *gin.Engine, twoFactorController *controllers.TwoFactorController, authMiddleware gin.HandlerFunc) {
+	router.POST("/users/login/verify", twoFactorController.VerifyLogin)
+
+	totpGroup := router.Group("/users/me/totp")
+	totpGroup.Use(authMiddleware, middlewares.RequireSession())
+	{
+		totpGroup.POST("", twoFactorController.BeginEnrollment)
+		totpGroup.POST("/confirm", twoFactorController.ConfirmEnrollment)
+		totpGroup.POST("/disable", twoFactorController.Disable)
+		totpGroup.POST("/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+	}
+
+	router.DELETE("/users/:id/totp", authMiddleware, middlewares.RequirePermission(models.PermUsersManage), twoFactorController.ResetUser)
+}
@@ -1,18 +1,39 @@
 package routes
 
 import (
-	"your_project_name/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-func UserRoutes(router *gin.Engine, userController *controllers.UserController) {
+func UserRoutes(router *gin.Engine, userController *controllers.UserController, authMiddleware gin.HandlerFunc) {
 	userGroup := router.Group("/users")
 	{
 		userGroup.POST("/signup", userController.Signup)
 		userGroup.POST("/login", userController.Login)
-		//userGroup.GET("/:id", userController.GetUser)
-		//userGroup.PUT("/:id", userController.UpdateUser)
-		//userGroup.DELETE("/:id", userController.DeleteUser)
+		userGroup.POST("/refresh", userController.Refresh)
+		userGroup.POST("/logout", userController.Logout)
 	}
-}
\ No newline at end of file
+
+	meGroup := router.Group("/users/me")
+	meGroup.Use(authMiddleware, middlewares.RequireSession())
+	{
+		meGroup.GET("", userController.GetMe)
+		meGroup.PUT("", userController.UpdateMe)
+		meGroup.PUT("/password", userController.ChangePassword)
+	}
+
+	adminGroup := router.Group("/users")
+	adminGroup.Use(authMiddleware, middlewares.RequirePermission(models.PermUsersManage))
+	{
+		adminGroup.GET("", userController.GetUsers)
+		adminGroup.GET("/:id", userController.GetUser)
+		adminGroup.PUT("/:id", userController.UpdateUser)
+		adminGroup.PUT("/:id/role", userController.UpdateUserRole)
+		adminGroup.POST("/:id/deactivate", userController.DeactivateUser)
+		adminGroup.POST("/:id/activate", userController.ActivateUser)
+		adminGroup.DELETE("/:id", userController.DeleteUser)
+	}
+}
@@ -1,25 +1,30 @@
 package routes
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
-	"github.com/yourusername/yourproject/pkg/controllers"
-	"github.com/yourusername/yourproject/pkg/middlewares"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/controllers"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/middlewares"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
 )
 
-func VideoRoutes(router *gin.Engine, videoController *controllers.VideoController) {
+func VideoRoutes(router *gin.Engine, videoController *controllers.VideoController, authMiddleware gin.HandlerFunc) {
 	videoGroup := router.Group("/videos")
 	{
 		videoGroup.GET("", videoController.GetAllPublicVideos)
 	}
 
 	adminVideoGroup := router.Group("/admin/videos")
-	adminVideoGroup.Use(middlewares.AuthMiddleware())
+	adminVideoGroup.Use(authMiddleware)
 	{
-		adminVideoGroup.POST("", videoController.CreateVideo)
-		adminVideoGroup.GET("/:id", videoController.GetVideo)
-		adminVideoGroup.PUT("/:id", videoController.UpdateVideo)
-		adminVideoGroup.DELETE("/:id", videoController.DeleteVideo)
+		canRead := middlewares.RequirePermission(models.PermVideosRead)
+		canWrite := middlewares.RequirePermission(models.PermVideosWrite)
+		adminVideoGroup.POST("", canWrite, videoController.CreateVideo)
+		adminVideoGroup.POST("/uploads", canWrite, videoController.StartDirectUpload)
+		adminVideoGroup.POST("/uploads/complete", canWrite, videoController.CompleteDirectUpload)
+		adminVideoGroup.POST("/uploads/abort", canWrite, videoController.AbortDirectUpload)
+		adminVideoGroup.GET("/:id", canRead, videoController.GetVideo)
+		adminVideoGroup.PUT("/:id", canWrite, videoController.UpdateVideo)
+		adminVideoGroup.DELETE("/:id", canWrite, videoController.DeleteVideo)
 	}
-}
\ No newline at end of file
+}
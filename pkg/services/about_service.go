@@ -1,64 +1,94 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"io"
+	"log"
 
-	"github.com/jinzhu/gorm"
-	"github.com/yourusername/yourproject/pkg/models"
-	"github.com/yourusername/yourproject/pkg/utils"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
 )
 
 // AboutService struct to hold dependencies
 type AboutService struct {
-	DB    *gorm.DB
-	S3Util *utils.S3Util
+	Abouts  repositories.AboutRepository
+	Storage utils.Storage
 }
 
 // NewAboutService creates a new AboutService instance
-func NewAboutService(db *gorm.DB, s3Util *utils.S3Util) *AboutService {
-	return &AboutService{DB: db, S3Util: s3Util}
+func NewAboutService(abouts repositories.AboutRepository, storage utils.Storage) *AboutService {
+	return &AboutService{Abouts: abouts, Storage: storage}
 }
 
 // CreateAbout creates a new about entry
-func (s *AboutService) CreateAbout(about *models.About) error {
-	result := s.DB.Create(about)
-	if result.Error != nil {
-		return fmt.Errorf("failed to create about: %w", result.Error)
+func (s *AboutService) CreateAbout(ctx context.Context, about *models.About) error {
+	// Only uploaded images have a key; a client supplied URL is hosted elsewhere.
+	about.ImageKey = ""
+	if err := s.Abouts.Create(ctx, about); err != nil {
+		return fmt.Errorf("failed to create about: %w", err)
 	}
 	return nil
 }
 
 // GetAbout returns the about entry
-func (s *AboutService) GetAbout() (*models.About, error) {
-	var abouts []models.About
-	result := s.DB.Find(&abouts)
-	if result.Error != nil {
-		return nil, fmt.Errorf("failed to get about: %w", result.Error)
+func (s *AboutService) GetAbout(ctx context.Context) (*models.About, error) {
+	about, err := s.Abouts.FindFirst(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get about: %w", err)
 	}
-	if len(abouts) == 0 {
-		return nil, errors.New("no about found")
-	}
-	return &abouts[0], nil
+	return about, nil
 }
 
 // UpdateAbout updates an existing about entry
-func (s *AboutService) UpdateAbout(about *models.About) error {
-	result := s.DB.Save(about)
-	if result.Error != nil {
-		return fmt.Errorf("failed to update about: %w", result.Error)
+func (s *AboutService) UpdateAbout(ctx context.Context, about *models.About) error {
+	existing, err := s.Abouts.FindByID(ctx, about.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find about: %w", err)
+	}
+	about.ImageKey = ""
+	if about.ImageURL == existing.ImageURL {
+		about.ImageKey = existing.ImageKey
+	}
+	if err := s.Abouts.Update(ctx, about); err != nil {
+		return fmt.Errorf("failed to update about: %w", err)
 	}
 	return nil
 }
 
+// UploadImage uploads a new image for an about entry, replacing the previous one
+func (s *AboutService) UploadImage(ctx context.Context, id string, file io.Reader, filename string) (*models.About, error) {
+	about, err := s.Abouts.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find about: %w", err)
+	}
+
+	oldKey, oldURL := about.ImageKey, about.ImageURL
+	about.ImageKey, about.ImageURL, err = storeFile(ctx, s.Storage, "about", file, filename, imageTypes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Abouts.Update(ctx, about); err != nil {
+		return nil, fmt.Errorf("failed to update about: %w", err)
+	}
+	if err := removeFile(ctx, s.Storage, oldKey); err != nil {
+		log.Printf("failed to remove replaced about image %s: %v", oldURL, err)
+	}
+	return about, nil
+}
+
 // DeleteAbout deletes an about entry by ID
-func (s *AboutService) DeleteAbout(id string) error {
-	result := s.DB.Delete(&models.About{}, "id = ?", id)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete about: %w", result.Error)
+func (s *AboutService) DeleteAbout(ctx context.Context, id string) error {
+	about, err := s.Abouts.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find about: %w", err)
+	}
+	if err := s.Abouts.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete about: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return errors.New("no about found with given id")
+	if err := removeFile(ctx, s.Storage, about.ImageKey); err != nil {
+		log.Printf("failed to remove about image %s: %v", about.ImageURL, err)
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+const (
+	// APIKeyPrefix starts every API key so they are easy to recognize in
+	// headers and secret scanners.
+	APIKeyPrefix = "tvwc_"
+	// apiKeyIDLength is the length of the random part of the key prefix.
+	apiKeyIDLength = 8
+	// apiKeyTouchInterval limits how often the last-used time is written.
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	// ErrInvalidAPIKey is returned for unknown, revoked or expired API keys
+	// and for keys whose owner can no longer log in.
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrInvalidScope is returned when creating a key with an unknown or
+	// missing scope.
+	ErrInvalidScope = errors.New("invalid scope")
+)
+
+// APIKeyRequest holds the fields of a new API key.
+type APIKeyRequest struct {
+	Name      string              `json:"name"`
+	Scopes    []models.Permission `json:"scopes"`
+	ExpiresAt *time.Time          `json:"expires_at"`
+}
+
+// APIKeyService manages API keys for machine clients.
+type APIKeyService struct {
+	Keys  repositories.APIKeyRepository
+	Users *UserService
+}
+
+// NewAPIKeyService creates a new APIKeyService.
+func NewAPIKeyService(keys repositories.APIKeyRepository, users *UserService) *APIKeyService {
+	return &APIKeyService{Keys: keys, Users: users}
+}
+
+// CreateKey creates an API key acting for createdBy and returns it together
+// with the key itself, which is only available here.
+func (s *APIKeyService) CreateKey(ctx context.Context, request APIKeyRequest, createdBy string) (*models.APIKey, string, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if len(request.Scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+	for _, scope := range request.Scopes {
+		if !scope.Valid() {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+	now := time.Now()
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return nil, "", fmt.Errorf("%w: expiry must be in the future", ErrInvalidAPIKey)
+	}
+
+	b := make([]byte, apiKeyIDLength/2)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	prefix := APIKeyPrefix + hex.EncodeToString(b)
+	token := prefix + "_" + secret
+
+	key := &models.APIKey{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(token),
+		Scopes:    request.Scopes,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: request.ExpiresAt,
+	}
+	if err := s.Keys.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+	return key, token, nil
+}
+
+// ListKeys returns every API key, newest first.
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	keys, err := s.Keys.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey stops an API key from being accepted.
+func (s *APIKeyService) RevokeKey(ctx context.Context, id string) (*models.APIKey, error) {
+	key, err := s.Keys.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	if err := s.Keys.Update(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return key, nil
+}
+
+// IsAPIKey reports whether a bearer token looks like an API key rather than
+// an access token.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// Authenticate returns the key and the user it acts for. The last-used time
+// is updated at most once per apiKeyTouchInterval.
+func (s *APIKeyService) Authenticate(ctx context.Context, token string) (*models.APIKey, *models.User, error) {
+	if len(token) <= len(APIKeyPrefix)+apiKeyIDLength+1 || token[len(APIKeyPrefix)+apiKeyIDLength] != '_' {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	key, err := s.Keys.FindByPrefix(ctx, token[:len(APIKeyPrefix)+apiKeyIDLength])
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(utils.HashToken(token))) != 1 || !key.Active(now) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.Users.GetUser(ctx, key.CreatedBy)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, err
+	}
+	if !user.Active() {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		key.LastUsedAt = &now
+		if err := s.Keys.Update(ctx, key); err != nil {
+			log.Println("Error updating API key last use:", err)
+		}
+	}
+	return key, user, nil
+}
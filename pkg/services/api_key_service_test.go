@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+func newTestAPIKeyService(t *testing.T) *APIKeyService {
+	t.Helper()
+	repos := newTestRepositories(t)
+	user := &models.User{ID: "user-1", Email: "user@example.com", Role: models.RoleEditor}
+	if err := repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return NewAPIKeyService(repos.APIKeys, NewUserService(repos.Users, nil))
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name    string
+		request APIKeyRequest
+		wantErr error
+	}{
+		{name: "valid", request: APIKeyRequest{Name: "ci", Scopes: []models.Permission{models.PermBlogsCreate}}},
+		{name: "expiry", request: APIKeyRequest{Name: "ci", Scopes: []models.Permission{models.PermBlogsCreate}, ExpiresAt: &future}},
+		{name: "no name", request: APIKeyRequest{Name: " ", Scopes: []models.Permission{models.PermBlogsCreate}}, wantErr: ErrInvalidAPIKey},
+		{name: "no scopes", request: APIKeyRequest{Name: "ci"}, wantErr: ErrInvalidScope},
+		{name: "unknown scope", request: APIKeyRequest{Name: "ci", Scopes: []models.Permission{"blogs:everything"}}, wantErr: ErrInvalidScope},
+		{name: "past expiry", request: APIKeyRequest{Name: "ci", Scopes: []models.Permission{models.PermBlogsCreate}, ExpiresAt: &past}, wantErr: ErrInvalidAPIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAPIKeyService(t)
+			key, token, err := s.CreateKey(context.Background(), tt.request, "user-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateKey: got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !IsAPIKey(token) || !strings.HasPrefix(token, key.Prefix+"_") {
+				t.Errorf("token %q does not start with the prefix %q", token, key.Prefix)
+			}
+			if len(key.Prefix) != len(APIKeyPrefix)+apiKeyIDLength {
+				t.Errorf("prefix %q: got length %d, want %d", key.Prefix, len(key.Prefix), len(APIKeyPrefix)+apiKeyIDLength)
+			}
+			// Only the hash of the key is stored.
+			stored, err := s.Keys.FindByID(context.Background(), key.ID)
+			if err != nil {
+				t.Fatalf("FindByID: %v", err)
+			}
+			if stored.KeyHash != utils.HashToken(token) || strings.Contains(stored.KeyHash, token[len(key.Prefix)+1:]) {
+				t.Errorf("stored hash %q does not match the key", stored.KeyHash)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	tests := []struct {
+		name string
+		// present returns the token to authenticate with, given the key
+		// issued for the test.
+		present func(token string) string
+		// modify changes the stored key or its owner before authenticating.
+		modify  func(t *testing.T, s *APIKeyService, key *models.APIKey)
+		wantErr error
+	}{
+		{name: "valid", present: func(token string) string { return token }},
+		{name: "wrong secret", present: func(token string) string { return token[:len(token)-4] + "AAAA" }, wantErr: ErrInvalidAPIKey},
+		{name: "prefix only", present: func(token string) string { return token[:len(APIKeyPrefix)+apiKeyIDLength] }, wantErr: ErrInvalidAPIKey},
+		{name: "unknown prefix", present: func(token string) string { return APIKeyPrefix + "00000000" + token[len(APIKeyPrefix)+apiKeyIDLength:] }, wantErr: ErrInvalidAPIKey},
+		{name: "no separator", present: func(token string) string { return strings.Replace(token, "_", "-", 2) }, wantErr: ErrInvalidAPIKey},
+		{
+			name:    "revoked",
+			present: func(token string) string { return token },
+			modify: func(t *testing.T, s *APIKeyService, key *models.APIKey) {
+				if _, err := s.RevokeKey(context.Background(), key.ID); err != nil {
+					t.Fatalf("RevokeKey: %v", err)
+				}
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:    "expired",
+			present: func(token string) string { return token },
+			modify: func(t *testing.T, s *APIKeyService, key *models.APIKey) {
+				expired := time.Now().Add(-time.Second)
+				key.ExpiresAt = &expired
+				if err := s.Keys.Update(context.Background(), key); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name:    "deactivated owner",
+			present: func(token string) string { return token },
+			modify: func(t *testing.T, s *APIKeyService, key *models.APIKey) {
+				user, err := s.Users.Users.FindByID(context.Background(), key.CreatedBy)
+				if err != nil {
+					t.Fatalf("FindByID: %v", err)
+				}
+				now := time.Now()
+				user.DeactivatedAt = &now
+				if err := s.Users.Users.Update(context.Background(), user); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestAPIKeyService(t)
+			key, token, err := s.CreateKey(ctx, APIKeyRequest{Name: "ci", Scopes: []models.Permission{models.PermBlogsCreate}}, "user-1")
+			if err != nil {
+				t.Fatalf("CreateKey: %v", err)
+			}
+			if tt.modify != nil {
+				tt.modify(t, s, key)
+			}
+
+			got, user, err := s.Authenticate(ctx, tt.present(token))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate: got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.ID != key.ID || user.ID != "user-1" {
+				t.Errorf("Authenticate: got key %q of user %q", got.ID, user.ID)
+			}
+			if got.LastUsedAt == nil {
+				t.Error("Authenticate did not record the last use")
+			}
+		})
+	}
+}
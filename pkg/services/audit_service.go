@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+)
+
+// Audit actions. Changes to records are logged as "<resource>.create",
+// "<resource>.update" and "<resource>.delete".
+const (
+	AuditLoginLockout = "login.lockout"
+	AuditLoginUnlock  = "login.unlock"
+	AuditBlogTaxonomy = "blog.replace_taxonomy"
+)
+
+// MaxAuditEntries is the most entries a query returns.
+const MaxAuditEntries = 500
+
+// auditIgnoredFields change on every save and are left out of diffs.
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// auditRedactedFields are secrets whose values are never logged. The diff
+// still shows that they changed.
+var auditRedactedFields = map[string]bool{"password": true}
+
+var redactedAuditValue = models.AuditValue(`"[redacted]"`)
+
+// AuditActor is who makes the changes of a request. AuthMiddleware adds it
+// to the request context.
+type AuditActor struct {
+	UserID   string
+	APIKeyID string
+	IP       string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context whose changes are attributed to actor.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFrom returns the actor of a context. Changes made without one,
+// e.g. on signup or by background jobs, are logged without an actor.
+func AuditActorFrom(ctx context.Context) AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor
+}
+
+// AuditService writes and queries the audit log.
+type AuditService struct {
+	Entries repositories.AuditRepository
+}
+
+// NewAuditService creates a new AuditService.
+func NewAuditService(entries repositories.AuditRepository) *AuditService {
+	return &AuditService{Entries: entries}
+}
+
+// Record stores an audit entry, filling in its ID and timestamp.
+func (s *AuditService) Record(ctx context.Context, entry models.AuditEntry) error {
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = time.Now()
+	if err := s.Entries.Create(ctx, &entry); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// RecordChange logs a change to a record by the actor of ctx. before is nil
+// for created records and after for deleted ones. Updates that change
+// nothing are not logged.
+func (s *AuditService) RecordChange(ctx context.Context, action, resourceType, resourceID string, before, after interface{}) error {
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s %s: %w", resourceType, resourceID, err)
+	}
+	if len(changes) == 0 && !isNilRecord(before) && !isNilRecord(after) {
+		return nil
+	}
+	actor := AuditActorFrom(ctx)
+	return s.Record(ctx, models.AuditEntry{
+		ActorID:      actor.UserID,
+		APIKeyID:     actor.APIKeyID,
+		Action:       resourceType + "." + action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		IP:           actor.IP,
+		Changes:      changes,
+	})
+}
+
+// Find returns the matching entries, newest first. The limit defaults to
+// and is capped at MaxAuditEntries.
+func (s *AuditService) Find(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditEntry, error) {
+	if filter.Limit <= 0 || filter.Limit > MaxAuditEntries {
+		filter.Limit = MaxAuditEntries
+	}
+	entries, err := s.Entries.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+// auditDiff compares the JSON fields of two records. Fields hidden from
+// JSON are never part of the diff.
+func auditDiff(before, after interface{}) (map[string]models.AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.AuditChange{}
+	for name := range mergeKeys(beforeFields, afterFields) {
+		if auditIgnoredFields[name] {
+			continue
+		}
+		oldValue, hadOld := beforeFields[name]
+		newValue, hasNew := afterFields[name]
+		if hadOld && hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		var change models.AuditChange
+		if hadOld {
+			if change.Before, err = auditValue(name, oldValue); err != nil {
+				return nil, err
+			}
+		}
+		if hasNew {
+			if change.After, err = auditValue(name, newValue); err != nil {
+				return nil, err
+			}
+		}
+		changes[name] = change
+	}
+	return changes, nil
+}
+
+func auditFields(record interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if isNilRecord(record) {
+		return fields, nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func isNilRecord(record interface{}) bool {
+	if record == nil {
+		return true
+	}
+	v := reflect.ValueOf(record)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+func auditValue(name string, value interface{}) (models.AuditValue, error) {
+	if auditRedactedFields[name] {
+		return redactedAuditValue, nil
+	}
+	data, err := json.Marshal(value)
+	return models.AuditValue(data), err
+}
+
+func mergeKeys(a, b map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	return keys
+}
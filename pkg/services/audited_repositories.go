@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+)
+
+// Audited returns a copy of repos whose content, taxonomy and user
+// repositories log every create, update and delete with a diff of the
+// record. Bulk rewrites of blog tags and categories are logged once each.
+func (s *AuditService) Audited(repos *repositories.Repositories) *repositories.Repositories {
+	audited := *repos
+	audited.Blogs = &auditedBlogRepository{
+		BlogRepository: repos.Blogs,
+		log:            auditLog[models.Blog]{audit: s, resourceType: "blog", find: repos.Blogs.FindByID, id: func(b *models.Blog) string { return b.ID }},
+	}
+	audited.Videos = &auditedVideoRepository{
+		VideoRepository: repos.Videos,
+		log:             auditLog[models.Video]{audit: s, resourceType: "video", find: repos.Videos.FindByID, id: func(v *models.Video) string { return v.ID }},
+	}
+	audited.Heroes = &auditedHeroRepository{
+		HeroRepository: repos.Heroes,
+		log:            auditLog[models.HeroSection]{audit: s, resourceType: "hero", find: repos.Heroes.FindByID, id: func(h *models.HeroSection) string { return h.ID }},
+	}
+	audited.Abouts = &auditedAboutRepository{
+		AboutRepository: repos.Abouts,
+		log:             auditLog[models.About]{audit: s, resourceType: "about", find: repos.Abouts.FindByID, id: func(a *models.About) string { return a.ID }},
+	}
+	audited.Services = &auditedServiceRepository{
+		ServiceRepository: repos.Services,
+		log:               auditLog[models.Service]{audit: s, resourceType: "service", find: repos.Services.FindByID, id: func(sv *models.Service) string { return sv.ID }},
+	}
+	audited.Tags = &auditedTagRepository{
+		TagRepository: repos.Tags,
+		log:           auditLog[models.Tag]{audit: s, resourceType: "tag", find: repos.Tags.FindByID, id: func(t *models.Tag) string { return t.ID }},
+	}
+	audited.Categories = &auditedCategoryRepository{
+		CategoryRepository: repos.Categories,
+		log:                auditLog[models.Category]{audit: s, resourceType: "category", find: repos.Categories.FindByID, id: func(c *models.Category) string { return c.ID }},
+	}
+	audited.Users = &auditedUserRepository{
+		UserRepository: repos.Users,
+		log:            auditLog[models.User]{audit: s, resourceType: "user", find: repos.Users.FindByID, id: func(u *models.User) string { return u.ID }},
+	}
+	return &audited
+}
+
+// auditLog records the changes made through one repository. Failing to log
+// a change is reported but does not undo it.
+type auditLog[T any] struct {
+	audit        *AuditService
+	resourceType string
+	find         func(ctx context.Context, id string) (*T, error)
+	id           func(*T) string
+}
+
+func (l auditLog[T]) create(ctx context.Context, doc *T, create func() error) error {
+	if err := create(); err != nil {
+		return err
+	}
+	l.record(ctx, "create", l.id(doc), nil, doc)
+	return nil
+}
+
+func (l auditLog[T]) update(ctx context.Context, doc *T, update func() error) error {
+	// A record that cannot be read is logged without its old values.
+	before, _ := l.find(ctx, l.id(doc))
+	if err := update(); err != nil {
+		return err
+	}
+	l.record(ctx, "update", l.id(doc), before, doc)
+	return nil
+}
+
+func (l auditLog[T]) delete(ctx context.Context, id string, del func() error) error {
+	// A record that cannot be read is logged without its old values.
+	before, _ := l.find(ctx, id)
+	if err := del(); err != nil {
+		return err
+	}
+	l.record(ctx, "delete", id, before, nil)
+	return nil
+}
+
+func (l auditLog[T]) record(ctx context.Context, action, id string, before, after *T) {
+	if err := l.audit.RecordChange(ctx, action, l.resourceType, id, before, after); err != nil {
+		log.Println("Error recording audit entry:", err)
+	}
+}
+
+type auditedBlogRepository struct {
+	repositories.BlogRepository
+	log auditLog[models.Blog]
+}
+
+func (r *auditedBlogRepository) Create(ctx context.Context, blog *models.Blog) error {
+	return r.log.create(ctx, blog, func() error { return r.BlogRepository.Create(ctx, blog) })
+}
+
+func (r *auditedBlogRepository) Update(ctx context.Context, blog *models.Blog) error {
+	return r.log.update(ctx, blog, func() error { return r.BlogRepository.Update(ctx, blog) })
+}
+
+func (r *auditedBlogRepository) Delete(ctx context.Context, id string) error {
+	return r.log.delete(ctx, id, func() error { return r.BlogRepository.Delete(ctx, id) })
+}
+
+// ReplaceTaxonomySlug logs the rewrite as a whole since the blogs it
+// changes are not known.
+func (r *auditedBlogRepository) ReplaceTaxonomySlug(ctx context.Context, field, from, to string) error {
+	if err := r.BlogRepository.ReplaceTaxonomySlug(ctx, field, from, to); err != nil {
+		return err
+	}
+	actor := AuditActorFrom(ctx)
+	err := r.log.audit.Record(ctx, models.AuditEntry{
+		ActorID:      actor.UserID,
+		APIKeyID:     actor.APIKeyID,
+		Action:       AuditBlogTaxonomy,
+		ResourceType: r.log.resourceType,
+		IP:           actor.IP,
+		Details:      map[string]string{"field": field, "from": from, "to": to},
+	})
+	if err != nil {
+		log.Println("Error recording audit entry:", err)
+	}
+	return nil
+}
+
+type auditedVideoRepository struct {
+	repositories.VideoRepository
+	log auditLog[models.Video]
+}
+
+func (r *auditedVideoRepository) Create(ctx context.Context, video *models.Video) error {
+	return r.log.create(ctx, video, func() error { return r.VideoRepository.Create(ctx, video) })
+}
+
+func (r *auditedVideoRepository) Update(ctx context.Context, video *models.Video) error {
+	return r.log.update(ctx, video, func() error { return r.VideoRepository.Update(ctx, video) })
+}
+
+func (r *auditedVideoRepository) Delete(ctx context.Context, id string) error {
+	return r.log.delete(ctx, id, func() error { return r.VideoRepository.Delete(ctx, id) })
+}
+
+type auditedHeroRepository struct {
+	repositories.HeroRepository
+	log auditLog[models.HeroSection]
+}
+
+func (r *auditedHeroRepository) Create(ctx context.Context, hero *models.HeroSection) error {
+	return r.log.create(ctx, hero, func() error { return r.HeroRepository.Create(ctx, hero) })
+}
+
+func (r *auditedHeroRepository) Update(ctx context.Context, hero *models.HeroSection) error {
+	return r.log.update(ctx, hero, func() error { return r.HeroRepository.Update(ctx, hero) })
+}
+
+func (r *auditedHeroRepository) Delete(ctx context.Context, id string) error {
+	return r.log.delete(ctx, id, func() error { return r.HeroRepository.Delete(ctx, id) })
+}
+
+type auditedAboutRepository struct {
+	repositories.AboutRepository
+	log auditLog[models.About]
+}
+
+func (r *auditedAboutRepository) Create(ctx context.Context, about *models.About) error {
+	return r.log.create(ctx, about, func() error { return r.AboutRepository.Create(ctx, about) })
+}
+
+func (r *auditedAboutRepository) Update(ctx context.Context, about *models.About) error {
+	return r.log.update(ctx, about, func() error { return r.AboutRepository.Update(ctx, about) })
+}
+
+func (r *auditedAboutRepository) Delete(ctx context.Context, id string) error {
+	return r.log.delete(ctx, id, func() error { return r.AboutRepository.Delete(ctx, id) })
+}
+
+type auditedServiceRepository struct {
+	repositories.ServiceRepository
+	log auditLog[models.Service]
+}
+
+func (r *auditedServiceRepository) Create(ctx context.Context, service *models.Service) error {
+	return r.log.create(ctx, service, func() error { return r.ServiceRepository.Create(ctx, service) })
+}
+
+func (r *auditedServiceRepository) Update(ctx context.Context, service *models.Service) error {
+	return r.log.update(ctx, service, func() error { return r.ServiceRepository.Update(ctx, service) })
+}
+
+func (r *auditedServiceRepository) Delete(ctx context.Context, id string) error {
+	return r.log.delete(ctx, id, func() error { return r.ServiceRepository.Delete(ctx, id) })
+}
+
+type auditedTagRepository struct {
+	repositories.TagRepository
+	log auditLog[models.Tag]
+}
+
+func (r *auditedTagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	return r.log.create(ctx, tag, func() error { return r.TagRepository.Create(ctx, tag) })
+}
+
+func (r *auditedTagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	return r.log.update(ctx, tag, func() error { return r.TagRepository.Update(ctx, tag) })
+}
+
+func (r *auditedTagRepository) Delete(ctx context.Context, id string) error {
+	return r.log.delete(ctx, id, func() error { return r.TagRepository.Delete(ctx, id) })
+}
+
+type auditedCategoryRepository struct {
+	repositories.CategoryRepository
+	log auditLog[models.Category]
+}
+
+func (r *auditedCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return r.log.create(ctx, category, func() error { return r.CategoryRepository.Create(ctx, category) })
+}
+
+func (r *auditedCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return r.log.update(ctx, category, func() error { return r.CategoryRepository.Update(ctx, category) })
+}
+
+func (r *auditedCategoryRepository) Delete(ctx context.Context, id string) error {
+	return r.log.delete(ctx, id, func() error { return r.CategoryRepository.Delete(ctx, id) })
+}
+
+type auditedUserRepository struct {
+	repositories.UserRepository
+	log auditLog[models.User]
+}
+
+func (r *auditedUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.log.create(ctx, user, func() error { return r.UserRepository.Create(ctx, user) })
+}
+
+func (r *auditedUserRepository) Update(ctx context.Context, user *models.User) error {
+	return r.log.update(ctx, user, func() error { return r.UserRepository.Update(ctx, user) })
+}
+
+func (r *auditedUserRepository) Delete(ctx context.Context, id string) error {
+	return r.log.delete(ctx, id, func() error { return r.UserRepository.Delete(ctx, id) })
+}
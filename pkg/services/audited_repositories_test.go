@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+)
+
+func TestAuditedTaxonomyChanges(t *testing.T) {
+	ctx := WithAuditActor(context.Background(), AuditActor{UserID: "admin"})
+	repos := newTestRepositories(t)
+	audit := NewAuditService(repos.Audit)
+	audited := audit.Audited(repos)
+	s := NewTaxonomyService(audited.Tags, audited.Categories, NewBlogService(audited.Blogs, audited.BlogRevisions, nil))
+	blog := &models.Blog{ID: "one", Slug: "one", Tags: []string{"web"}}
+	if err := s.AssignTaxonomy(ctx, blog); err != nil {
+		t.Fatalf("AssignTaxonomy: %v", err)
+	}
+	if err := repos.Blogs.Create(ctx, blog); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	tag, err := s.Tags.FindBySlug(ctx, "web")
+	if err != nil {
+		t.Fatalf("FindBySlug: %v", err)
+	}
+	if _, err := s.RenameTag(ctx, tag.ID, "Frontend"); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+
+	tests := []struct {
+		action      string
+		wantID      string
+		wantDetails map[string]string
+	}{
+		{action: "tag.create", wantID: tag.ID},
+		{action: "tag.update", wantID: tag.ID},
+		{action: AuditBlogTaxonomy, wantDetails: map[string]string{"field": "tags", "from": "web", "to": "frontend"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			entries, err := audit.Find(ctx, repositories.AuditFilter{Action: tt.action})
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("entries: got %d, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.ResourceID != tt.wantID || entry.ActorID != "admin" {
+				t.Errorf("entry: got resource %q by %q, want %q by admin", entry.ResourceID, entry.ActorID, tt.wantID)
+			}
+			if tt.wantDetails != nil && !reflect.DeepEqual(entry.Details, tt.wantDetails) {
+				t.Errorf("details: got %v, want %v", entry.Details, tt.wantDetails)
+			}
+		})
+	}
+}
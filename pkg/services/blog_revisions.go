@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// revisionNumberAttempts is how often a revision is numbered again when a
+// concurrent save took its number.
+const revisionNumberAttempts = 3
+
+// BlogRevisionDiff is the line diff of the content of two revisions.
+type BlogRevisionDiff struct {
+	BlogID  string           `json:"blog_id"`
+	From    int              `json:"from"`
+	To      int              `json:"to"`
+	Title   [2]string        `json:"title"`
+	Added   int              `json:"added"`
+	Removed int              `json:"removed"`
+	Lines   []utils.DiffLine `json:"lines"`
+}
+
+// ListRevisions returns the revisions of a blog, newest first.
+func (s *BlogService) ListRevisions(ctx context.Context, blogID string) ([]models.BlogRevision, error) {
+	revisions, err := s.Revisions.FindByBlog(ctx, blogID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blog revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetRevision returns a revision of a blog by its number.
+func (s *BlogService) GetRevision(ctx context.Context, blogID string, number int) (*models.BlogRevision, error) {
+	revision, err := s.Revisions.FindByNumber(ctx, blogID, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blog revision %d: %w", number, err)
+	}
+	return revision, nil
+}
+
+// DiffRevisions compares the content of two revisions of a blog.
+func (s *BlogService) DiffRevisions(ctx context.Context, blogID string, from, to int) (*BlogRevisionDiff, error) {
+	before, err := s.GetRevision(ctx, blogID, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.GetRevision(ctx, blogID, to)
+	if err != nil {
+		return nil, err
+	}
+	diff := &BlogRevisionDiff{
+		BlogID: blogID,
+		From:   from,
+		To:     to,
+		Title:  [2]string{before.Title, after.Title},
+		Lines:  utils.DiffLines(before.Content, after.Content),
+	}
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case utils.DiffInsert:
+			diff.Added++
+		case utils.DiffDelete:
+			diff.Removed++
+		}
+	}
+	return diff, nil
+}
+
+// RestoreRevision makes the content of an old revision the current version
+// of the blog. The restore is saved as a new revision, so the history stays
+// intact. The slug, status and image are kept.
+func (s *BlogService) RestoreRevision(ctx context.Context, blogID string, number int) (*models.Blog, error) {
+	revision, err := s.GetRevision(ctx, blogID, number)
+	if err != nil {
+		return nil, err
+	}
+	blog, err := s.Blogs.FindByID(ctx, blogID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blog: %w", err)
+	}
+	blog.Title = revision.Title
+	blog.Content = revision.Content
+	blog.Author = revision.Author
+	blog.AuthorImageURL = revision.AuthorImageURL
+	blog.UpdatedAt = time.Now()
+	if err := s.Blogs.Update(ctx, blog); err != nil {
+		return nil, fmt.Errorf("failed to update blog: %w", err)
+	}
+	s.recordRevision(ctx, blog, number)
+	return blog, nil
+}
+
+// recordBaseline saves the current content of a blog as its first revision
+// if it has none, as for blogs created before revisions were kept.
+func (s *BlogService) recordBaseline(ctx context.Context, blog *models.Blog) {
+	_, err := s.Revisions.FindLatest(ctx, blog.ID)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		log.Println("Error getting blog revisions:", err)
+		return
+	}
+	// Who wrote the existing content is not known.
+	revision := newBlogRevision(ctx, blog, 1, 0)
+	revision.EditorID = ""
+	revision.CreatedAt = blog.UpdatedAt
+	if err := s.Revisions.Create(ctx, revision); err != nil && !errors.Is(err, repositories.ErrDuplicateKey) {
+		log.Println("Error recording blog revision:", err)
+	}
+}
+
+// recordRevision saves the content of a blog as its next revision. Failing
+// to do so is logged but does not undo the save.
+func (s *BlogService) recordRevision(ctx context.Context, blog *models.Blog, restoredFrom int) {
+	for attempt := 0; attempt < revisionNumberAttempts; attempt++ {
+		number := 1
+		latest, err := s.Revisions.FindLatest(ctx, blog.ID)
+		switch {
+		case err == nil:
+			number = latest.Number + 1
+		case !errors.Is(err, repositories.ErrNotFound):
+			log.Println("Error getting blog revisions:", err)
+			return
+		}
+		err = s.Revisions.Create(ctx, newBlogRevision(ctx, blog, number, restoredFrom))
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, repositories.ErrDuplicateKey) {
+			log.Println("Error recording blog revision:", err)
+			return
+		}
+	}
+	log.Printf("Error recording blog revision: no free revision number for blog %s", blog.ID)
+}
+
+func newBlogRevision(ctx context.Context, blog *models.Blog, number, restoredFrom int) *models.BlogRevision {
+	return &models.BlogRevision{
+		ID:             uuid.New().String(),
+		BlogID:         blog.ID,
+		Number:         number,
+		Title:          blog.Title,
+		Content:        blog.Content,
+		Author:         blog.Author,
+		AuthorImageURL: blog.AuthorImageURL,
+		EditorID:       AuditActorFrom(ctx).UserID,
+		RestoredFrom:   restoredFrom,
+		CreatedAt:      time.Now(),
+	}
+}
@@ -1,44 +1,67 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 
-	"awesomeProject/pkg/models"
-	"awesomeProject/pkg/utils"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
 )
 
+// blogSchedulerInterval is how often scheduled blogs are published.
+const blogSchedulerInterval = time.Minute
+
+// ErrInvalidBlogStatus is returned for unknown statuses and schedules
+// without a publish time in the future.
+var ErrInvalidBlogStatus = errors.New("invalid blog status")
+
 type BlogService struct {
-	DB      *gorm.DB
-	S3Utils utils.S3Utils
+	Blogs     repositories.BlogRepository
+	Revisions repositories.BlogRevisionRepository
+	Storage   utils.Storage
 }
 
-func NewBlogService(db *gorm.DB, s3Utils utils.S3Utils) *BlogService {
+func NewBlogService(blogs repositories.BlogRepository, revisions repositories.BlogRevisionRepository, storage utils.Storage) *BlogService {
 	return &BlogService{
-		DB:      db,
-		S3Utils: s3Utils,
+		Blogs:     blogs,
+		Revisions: revisions,
+		Storage:   storage,
 	}
 }
 
-func (s *BlogService) CreateBlog(blog *models.Blog) error {
+func (s *BlogService) CreateBlog(ctx context.Context, blog *models.Blog) error {
 	if blog.Slug == "" {
 		blog.Slug = generateSlug(blog.Title)
 	}
-	if err := s.DB.Create(blog).Error; err != nil {
+	// Only uploaded images have a key; a client supplied URL is hosted elsewhere.
+	blog.ImageKey = ""
+	blog.CreatedAt = time.Now()
+	blog.UpdatedAt = blog.CreatedAt
+	status := blog.Status
+	if status == "" {
+		status = models.BlogDraft
+	}
+	blog.Status, blog.PublishedAt = "", nil
+	if err := setBlogStatus(blog, status, blog.PublishAt, blog.CreatedAt); err != nil {
+		return err
+	}
+	if err := s.Blogs.Create(ctx, blog); err != nil {
 		return fmt.Errorf("failed to create blog: %w", err)
 	}
+	s.recordRevision(ctx, blog, 0)
 	return nil
 }
 
-func (s *BlogService) UpdateBlog(id string, blog *models.Blog) error {
-	var existingBlog models.Blog
-	if err := s.DB.First(&existingBlog, "id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("blog not found")
-		}
+func (s *BlogService) UpdateBlog(ctx context.Context, id string, blog *models.Blog) error {
+	existingBlog, err := s.Blogs.FindByID(ctx, id)
+	if err != nil {
 		return fmt.Errorf("failed to find blog: %w", err)
 	}
 	if blog.Slug == "" {
@@ -46,58 +69,236 @@ func (s *BlogService) UpdateBlog(id string, blog *models.Blog) error {
 	}
 
 	blog.ID = existingBlog.ID
+	blog.AuthorID = existingBlog.AuthorID
+	blog.ImageKey = ""
+	if blog.ImageURL == existingBlog.ImageURL {
+		blog.ImageKey = existingBlog.ImageKey
+	}
+	// The status only changes through SetStatus.
+	blog.Status = existingBlog.Status
+	blog.PublishAt = existingBlog.PublishAt
+	blog.PublishedAt = existingBlog.PublishedAt
+	blog.CreatedAt = existingBlog.CreatedAt
+	blog.UpdatedAt = time.Now()
 
-	if err := s.DB.Save(blog).Error; err != nil {
+	s.recordBaseline(ctx, existingBlog)
+	if err := s.Blogs.Update(ctx, blog); err != nil {
 		return fmt.Errorf("failed to update blog: %w", err)
 	}
+	s.recordRevision(ctx, blog, 0)
 	return nil
 }
 
-func (s *BlogService) DeleteBlog(id string) error {
-	var blog models.Blog
-	if err := s.DB.First(&blog, "id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("blog not found")
-		}
-		return fmt.Errorf("failed to find blog: %w", err)
+// UploadImage uploads a new cover image for a blog, replacing the previous one.
+func (s *BlogService) UploadImage(ctx context.Context, id string, file io.Reader, filename string) (*models.Blog, error) {
+	blog, err := s.Blogs.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blog: %w", err)
+	}
+
+	oldKey, oldURL := blog.ImageKey, blog.ImageURL
+	blog.ImageKey, blog.ImageURL, err = storeFile(ctx, s.Storage, "blogs", file, filename, imageTypes)
+	if err != nil {
+		return nil, err
+	}
+	blog.UpdatedAt = time.Now()
+	if err := s.Blogs.Update(ctx, blog); err != nil {
+		return nil, fmt.Errorf("failed to update blog: %w", err)
 	}
+	if err := removeFile(ctx, s.Storage, oldKey); err != nil {
+		log.Printf("failed to remove replaced blog image %s: %v", oldURL, err)
+	}
+	return blog, nil
+}
 
-	if err := s.DB.Delete(&blog).Error; err != nil {
+func (s *BlogService) DeleteBlog(ctx context.Context, id string) error {
+	blog, err := s.Blogs.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find blog: %w", err)
+	}
+	if err := s.Blogs.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete blog: %w", err)
 	}
+	if err := s.Revisions.DeleteByBlog(ctx, id); err != nil {
+		log.Printf("failed to remove revisions of blog %s: %v", id, err)
+	}
+	if err := removeFile(ctx, s.Storage, blog.ImageKey); err != nil {
+		log.Printf("failed to remove blog image %s: %v", blog.ImageURL, err)
+	}
 	return nil
 }
 
-func (s *BlogService) GetBlog(id string) (*models.Blog, error) {
-	var blog models.Blog
-	if err := s.DB.First(&blog, "id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("blog not found")
-		}
+func (s *BlogService) GetBlog(ctx context.Context, id string) (*models.Blog, error) {
+	blog, err := s.Blogs.FindByID(ctx, id)
+	if err != nil {
 		return nil, fmt.Errorf("failed to find blog: %w", err)
 	}
-	return &blog, nil
+	return blog, nil
 }
 
-func (s *BlogService) GetAllBlogs() ([]models.Blog, error) {
-	var blogs []models.Blog
-	if err := s.DB.Find(&blogs).Error; err != nil {
+// GetAllBlogs returns every blog whatever its status.
+func (s *BlogService) GetAllBlogs(ctx context.Context) ([]models.Blog, error) {
+	blogs, err := s.Blogs.FindAll(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get blogs: %w", err)
 	}
 	return blogs, nil
 }
 
-func (s *BlogService) GetBlogBySlug(slug string) (*models.Blog, error) {
-	var blog models.Blog
-	if err := s.DB.Where("slug = ?", slug).First(&blog).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("blog with slug %s not found", slug)
+// GetPublishedBlogs returns the blogs the public may see.
+func (s *BlogService) GetPublishedBlogs(ctx context.Context) ([]models.Blog, error) {
+	blogs, err := s.GetAllBlogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	published := make([]models.Blog, 0, len(blogs))
+	for i := range blogs {
+		if blogs[i].Visible(now) {
+			published = append(published, blogs[i])
+		}
+	}
+	return published, nil
+}
+
+// ListBlogs returns a page of blogs whatever their status.
+func (s *BlogService) ListBlogs(ctx context.Context, query repositories.ListQuery) (*repositories.Page[models.Blog], error) {
+	page, err := s.Blogs.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blogs: %w", err)
+	}
+	return page, nil
+}
+
+// ListPublishedBlogs returns a page of the blogs the public may see.
+func (s *BlogService) ListPublishedBlogs(ctx context.Context, query repositories.ListQuery) (*repositories.Page[models.Blog], error) {
+	query.AnyOf = visibleBlogFilters(time.Now())
+	return s.ListBlogs(ctx, query)
+}
+
+// visibleBlogFilters matches the blogs for which Blog.Visible is true.
+func visibleBlogFilters(now time.Time) [][]repositories.Filter {
+	return [][]repositories.Filter{
+		{{Field: "status", Op: repositories.OpIn, Value: []interface{}{models.BlogPublished, nil}}},
+		{
+			{Field: "status", Op: repositories.OpEq, Value: models.BlogScheduled},
+			{Field: "publish_at", Op: repositories.OpLte, Value: now},
+		},
+	}
+}
+
+// GetBlogBySlug returns a blog the public may see together with its
+// rendered content. Other blogs are reported as repositories.ErrNotFound.
+func (s *BlogService) GetBlogBySlug(ctx context.Context, slug string) (*RenderedBlog, error) {
+	blog, err := s.Blogs.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blog by slug %s: %w", slug, err)
+	}
+	if !blog.Visible(time.Now()) {
+		return nil, fmt.Errorf("failed to get blog by slug %s: %w", slug, repositories.ErrNotFound)
+	}
+	return RenderBlog(blog)
+}
+
+// RenderedBlog is a blog with its Markdown content rendered to sanitized
+// HTML. Content keeps the Markdown source.
+type RenderedBlog struct {
+	models.Blog
+	ContentHTML string           `json:"content_html"`
+	TOC         []utils.TOCEntry `json:"toc"`
+}
+
+// RenderBlog renders the content of a blog.
+func RenderBlog(blog *models.Blog) (*RenderedBlog, error) {
+	rendered, err := utils.RenderMarkdown(blog.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render blog %s: %w", blog.ID, err)
+	}
+	return &RenderedBlog{Blog: *blog, ContentHTML: rendered.HTML, TOC: rendered.TOC}, nil
+}
+
+// SetStatus moves a blog to another stage of the workflow. Scheduling
+// requires publishAt in the future.
+func (s *BlogService) SetStatus(ctx context.Context, id string, status models.BlogStatus, publishAt *time.Time) (*models.Blog, error) {
+	blog, err := s.Blogs.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blog: %w", err)
+	}
+	now := time.Now()
+	if err := setBlogStatus(blog, status, publishAt, now); err != nil {
+		return nil, err
+	}
+	blog.UpdatedAt = now
+	if err := s.Blogs.Update(ctx, blog); err != nil {
+		return nil, fmt.Errorf("failed to update blog: %w", err)
+	}
+	return blog, nil
+}
+
+// PublishDue publishes the scheduled blogs whose time has come.
+func (s *BlogService) PublishDue(ctx context.Context) error {
+	now := time.Now()
+	blogs, err := s.Blogs.FindScheduled(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to get scheduled blogs: %w", err)
+	}
+	for i := range blogs {
+		blog := &blogs[i]
+		publishAt := *blog.PublishAt
+		if err := setBlogStatus(blog, models.BlogPublished, nil, now); err != nil {
+			return err
+		}
+		// Published at the scheduled time, not when the scheduler noticed.
+		blog.PublishedAt = &publishAt
+		blog.UpdatedAt = now
+		if err := s.Blogs.Update(ctx, blog); err != nil {
+			return fmt.Errorf("failed to publish blog %s: %w", blog.ID, err)
+		}
+		log.Printf("published scheduled blog %s", blog.ID)
+	}
+	return nil
+}
+
+// RunScheduler publishes scheduled blogs until ctx is done.
+func (s *BlogService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(blogSchedulerInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.PublishDue(ctx); err != nil {
+			log.Println("Error publishing scheduled blogs:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		return nil, fmt.Errorf("failed to get blog by slug: %w", err)
 	}
-	return &blog, nil
+}
+
+// setBlogStatus changes the status and the publishing times that go with
+// it.
+func setBlogStatus(blog *models.Blog, status models.BlogStatus, publishAt *time.Time, now time.Time) error {
+	if !status.Valid() {
+		return fmt.Errorf("%w: %q", ErrInvalidBlogStatus, status)
+	}
+	switch status {
+	case models.BlogScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return fmt.Errorf("%w: publish_at must be in the future to schedule a blog", ErrInvalidBlogStatus)
+		}
+		blog.PublishAt = publishAt
+	case models.BlogPublished:
+		if blog.EffectiveStatus() != models.BlogPublished || blog.PublishedAt == nil {
+			blog.PublishedAt = &now
+		}
+		blog.PublishAt = nil
+	default:
+		blog.PublishAt = nil
+	}
+	blog.Status = status
+	return nil
 }
 
 func generateSlug(title string) string {
 	return fmt.Sprintf("%s-%s", utils.Slugify(title), uuid.New().String()[:8])
-}
\ No newline at end of file
+}
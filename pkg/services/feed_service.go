@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// feedSize is the number of newest items a feed holds.
+const feedSize = 50
+
+// feedSummaryLength is the length of item summaries in characters.
+const feedSummaryLength = 300
+
+// FeedLinks holds the base URLs of feed links. Site is the public website,
+// which has a page per blog at /blogs/<slug> and per video at /videos/<id>.
+// Origin is the server of the API, which relative media URLs such as local
+// uploads resolve against.
+type FeedLinks struct {
+	Site   string
+	Origin string
+}
+
+// FeedService builds the RSS and Atom feeds of published blogs and videos.
+type FeedService struct {
+	Blogs    *BlogService
+	Videos   *VideoService
+	SiteName string
+}
+
+// NewFeedService creates a new FeedService. siteName is the title of the
+// feeds.
+func NewFeedService(blogs *BlogService, videos *VideoService, siteName string) *FeedService {
+	return &FeedService{Blogs: blogs, Videos: videos, SiteName: siteName}
+}
+
+// BlogFeed returns the most recently published blogs, with their rendered
+// content and images as enclosures.
+func (s *FeedService) BlogFeed(ctx context.Context, links FeedLinks) (*utils.Feed, error) {
+	blogs, err := s.Blogs.GetPublishedBlogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// A draft written long ago goes into the feed when it is published.
+	sort.SliceStable(blogs, func(i, j int) bool {
+		return blogPublished(&blogs[i]).After(blogPublished(&blogs[j]))
+	})
+	if len(blogs) > feedSize {
+		blogs = blogs[:feedSize]
+	}
+
+	feed := s.newFeed("Blog", links.Site+"/blogs", links)
+	for i := range blogs {
+		blog := &blogs[i]
+		link := links.Site + "/blogs/" + url.PathEscape(blog.Slug)
+		item := utils.FeedItem{
+			ID:         feedItemID(blog.ID, link),
+			Title:      blog.Title,
+			Link:       link,
+			Author:     blog.Author,
+			Categories: append(append([]string{}, blog.Categories...), blog.Tags...),
+			Published:  blogPublished(blog),
+			Updated:    blog.UpdatedAt,
+			Enclosure:  feedEnclosure(blog.ImageURL, links),
+		}
+		rendered, err := RenderBlog(blog)
+		if err != nil {
+			log.Println("Error rendering blog for feed:", err)
+		} else {
+			item.Content = rendered.ContentHTML
+		}
+		if text, err := utils.MarkdownText(blog.Content); err == nil {
+			item.Summary = utils.HighlightSnippet(text, nil, feedSummaryLength)
+		}
+		addFeedItem(feed, item)
+	}
+	return feed, nil
+}
+
+// VideoFeed returns the newest videos of a category, with the video files
+// as enclosures.
+func (s *FeedService) VideoFeed(ctx context.Context, category string, links FeedLinks) (*utils.Feed, error) {
+	page, err := s.Videos.GetAllPublicVideos(ctx, repositories.ListQuery{
+		Filters: []repositories.Filter{{Field: "category", Op: repositories.OpEq, Value: category}},
+		Sort:    repositories.Sort{Field: "created_at", Desc: true},
+		Limit:   feedSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	feed := s.newFeed("Videos: "+category, links.Site+"/videos", links)
+	for _, video := range page.Items {
+		link := links.Site + "/videos/" + url.PathEscape(video.ID)
+		addFeedItem(feed, utils.FeedItem{
+			ID:         feedItemID(video.ID, link),
+			Title:      video.Title,
+			Link:       link,
+			Summary:    utils.HighlightSnippet(video.Content, nil, feedSummaryLength),
+			Categories: []string{video.Category},
+			Published:  video.CreatedAt,
+			Updated:    video.UpdatedAt,
+			Enclosure:  feedEnclosure(video.VideoURL, links),
+		})
+	}
+	return feed, nil
+}
+
+func (s *FeedService) newFeed(title, link string, links FeedLinks) *utils.Feed {
+	if s.SiteName != "" {
+		title = fmt.Sprintf("%s %s", s.SiteName, title)
+	}
+	return &utils.Feed{Title: title, Link: link, Description: title, Author: s.SiteName}
+}
+
+// addFeedItem appends an item and moves the update time of the feed to the
+// latest change of its items. A blog going live counts as a change.
+func addFeedItem(feed *utils.Feed, item utils.FeedItem) {
+	if item.Updated.Before(item.Published) {
+		item.Updated = item.Published
+	}
+	if item.Updated.After(feed.Updated) {
+		feed.Updated = item.Updated
+	}
+	feed.Items = append(feed.Items, item)
+}
+
+// blogPublished returns when a blog went live: when it was last published,
+// when it was scheduled for, or for blogs older than the workflow, when it
+// was created.
+func blogPublished(blog *models.Blog) time.Time {
+	switch {
+	case blog.PublishedAt != nil:
+		return *blog.PublishedAt
+	case blog.PublishAt != nil:
+		return *blog.PublishAt
+	}
+	return blog.CreatedAt
+}
+
+// feedItemID returns a permanent ID for an item: a UUID URN for the IDs
+// this service generates, the link otherwise.
+func feedItemID(id, link string) string {
+	if parsed, err := uuid.Parse(id); err == nil {
+		return parsed.URN()
+	}
+	return link
+}
+
+// feedEnclosure returns the enclosure of a media file, or nil if there is
+// none. The type is guessed from the file extension.
+func feedEnclosure(mediaURL string, links FeedLinks) *utils.FeedEnclosure {
+	if mediaURL == "" {
+		return nil
+	}
+	if strings.HasPrefix(mediaURL, "/") && !strings.HasPrefix(mediaURL, "//") {
+		mediaURL = links.Origin + mediaURL
+	}
+	mediaType := "application/octet-stream"
+	if parsed, err := url.Parse(mediaURL); err == nil {
+		if t := mime.TypeByExtension(path.Ext(parsed.Path)); t != "" {
+			mediaType = t
+		}
+	}
+	return &utils.FeedEnclosure{URL: mediaURL, Type: mediaType}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func TestBlogFeedOrdersByPublication(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepositories(t)
+	feeds := NewFeedService(NewBlogService(repos.Blogs, repos.BlogRevisions, nil), nil, "TVWC")
+	now := time.Now()
+	// A full feed of blogs published when they were created, and a draft
+	// from long ago that was published last.
+	for i := 0; i < feedSize; i++ {
+		created := now.Add(-time.Duration(i+1) * time.Hour)
+		blog := &models.Blog{ID: fmt.Sprintf("blog-%d", i), Slug: fmt.Sprintf("blog-%d", i), Status: models.BlogPublished, PublishedAt: &created, CreatedAt: created, UpdatedAt: created}
+		if err := repos.Blogs.Create(ctx, blog); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	created, published := now.AddDate(-1, 0, 0), now.Add(-time.Minute)
+	old := &models.Blog{ID: "old-draft", Slug: "old-draft", Status: models.BlogPublished, PublishedAt: &published, CreatedAt: created, UpdatedAt: published}
+	if err := repos.Blogs.Create(ctx, old); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	feed, err := feeds.BlogFeed(ctx, FeedLinks{Site: "https://example.com", Origin: "https://api.example.com"})
+	if err != nil {
+		t.Fatalf("BlogFeed: %v", err)
+	}
+	if len(feed.Items) != feedSize {
+		t.Fatalf("items: got %d, want %d", len(feed.Items), feedSize)
+	}
+	if want := "https://example.com/blogs/old-draft"; feed.Items[0].Link != want {
+		t.Errorf("first item: got %s, want %s", feed.Items[0].Link, want)
+	}
+	for i := 1; i < len(feed.Items); i++ {
+		if feed.Items[i].Published.After(feed.Items[i-1].Published) {
+			t.Fatalf("item %d published after item %d", i, i-1)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// InvitationTTL is how long an invitation can be accepted.
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrInvalidInvitation is returned for unknown, expired, revoked or
+	// already accepted invitation tokens.
+	ErrInvalidInvitation = errors.New("invalid invitation")
+	// ErrUserExists is returned when inviting an email that already has an
+	// account.
+	ErrUserExists = errors.New("user with this email already exists")
+)
+
+// InvitationService invites new users and lets them sign up.
+type InvitationService struct {
+	Invitations repositories.InvitationRepository
+	Users       *UserService
+}
+
+// NewInvitationService creates a new InvitationService.
+func NewInvitationService(invitations repositories.InvitationRepository, users *UserService) *InvitationService {
+	return &InvitationService{Invitations: invitations, Users: users}
+}
+
+// CreateInvitation invites an email with a role and returns the invitation
+// together with its token. The token is only available here.
+func (s *InvitationService) CreateInvitation(ctx context.Context, email string, role models.Role, createdBy string) (*models.Invitation, string, error) {
+	if !role.Valid() {
+		return nil, "", ErrInvalidRole
+	}
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, "", fmt.Errorf("%w: email is required", ErrInvalidUser)
+	}
+	_, err := s.Users.Users.FindByEmail(ctx, email)
+	if err == nil {
+		return nil, "", ErrUserExists
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	now := time.Now()
+	invitation := &models.Invitation{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Role:      role,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(InvitationTTL),
+	}
+	token := invitation.ID + "." + secret
+	invitation.TokenHash = utils.HashToken(token)
+	if err := s.Invitations.Create(ctx, invitation); err != nil {
+		return nil, "", fmt.Errorf("failed to create invitation: %w", err)
+	}
+	return invitation, token, nil
+}
+
+// ListInvitations returns every invitation, newest first.
+func (s *InvitationService) ListInvitations(ctx context.Context) ([]models.Invitation, error) {
+	invitations, err := s.Invitations.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation prevents a pending invitation from being accepted.
+func (s *InvitationService) RevokeInvitation(ctx context.Context, id string) (*models.Invitation, error) {
+	invitation, err := s.Invitations.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return invitation, nil
+	}
+	now := time.Now()
+	invitation.RevokedAt = &now
+	if err := s.Invitations.Update(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation redeems an invitation token and creates the invited user
+// with the chosen name and password.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, token, name, password string) (*models.User, error) {
+	id, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidInvitation
+	}
+	invitation, err := s.Invitations.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation.TokenHash != utils.HashToken(token) || !invitation.Pending(time.Now()) {
+		return nil, ErrInvalidInvitation
+	}
+
+	user, err := s.Users.CreateUser(ctx, name, invitation.Email, password, invitation.Role)
+	if err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation.AcceptedAt = &now
+	invitation.AcceptedUserID = user.ID
+	if err := s.Invitations.Update(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	return user, nil
+}
+
+// BootstrapAdmin invites the first admin when there are no users yet. It
+// returns an empty token if users already exist.
+func (s *InvitationService) BootstrapAdmin(ctx context.Context, email string) (string, error) {
+	users, err := s.Users.ListUsers(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(users) > 0 {
+		return "", nil
+	}
+	_, token, err := s.CreateInvitation(ctx, email, models.RoleAdmin, "")
+	return token, err
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+const (
+	// DefaultKeyRotationInterval is how long a signing key is used before a
+	// new one replaces it.
+	DefaultKeyRotationInterval = 30 * 24 * time.Hour
+	// keyReloadInterval is how often keys rotated by other instances are
+	// picked up.
+	keyReloadInterval = time.Minute
+	// retiredKeyLifetime is how long a retired key keeps verifying. It covers
+	// the tokens it signed, plus the time other instances may still sign with
+	// it before they reload.
+	retiredKeyLifetime = utils.AccessTokenTTL + 2*keyReloadInterval
+	// unknownKeyReloadInterval limits reloads triggered by tokens with an
+	// unknown key ID.
+	unknownKeyReloadInterval = 5 * time.Second
+)
+
+// ErrUnknownKey is returned for tokens signed with a key that does not
+// exist or no longer verifies.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeyService manages the keys that sign access tokens. Keys are shared
+// through the repository and cached in memory; Run rotates them on
+// schedule.
+type KeyService struct {
+	Keys             repositories.SigningKeyRepository
+	Algorithm        string
+	RotationInterval time.Duration
+
+	mu       sync.RWMutex
+	records  []models.SigningKey
+	parsed   map[string]*utils.JWTKey
+	loadedAt time.Time
+}
+
+// NewKeyService creates a new KeyService. Call Init before issuing tokens.
+func NewKeyService(keys repositories.SigningKeyRepository, algorithm string, rotationInterval time.Duration) *KeyService {
+	if rotationInterval <= 0 {
+		rotationInterval = DefaultKeyRotationInterval
+	}
+	return &KeyService{Keys: keys, Algorithm: algorithm, RotationInterval: rotationInterval}
+}
+
+// Init loads the keys and creates the first one if needed.
+func (s *KeyService) Init(ctx context.Context) error {
+	if s.Algorithm != utils.AlgorithmRS256 && s.Algorithm != utils.AlgorithmEdDSA {
+		return fmt.Errorf("unsupported signing algorithm %q", s.Algorithm)
+	}
+	if err := s.reload(ctx); err != nil {
+		return err
+	}
+	return s.RotateIfDue(ctx)
+}
+
+// Run reloads the keys and rotates them when due until ctx is done.
+func (s *KeyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(keyReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.reload(ctx); err != nil {
+				log.Println("Error reloading signing keys:", err)
+				continue
+			}
+			if err := s.RotateIfDue(ctx); err != nil {
+				log.Println("Error rotating signing keys:", err)
+			}
+		}
+	}
+}
+
+// RotateIfDue rotates the keys if there is no signing key for the configured
+// algorithm younger than the rotation interval.
+func (s *KeyService) RotateIfDue(ctx context.Context) error {
+	s.mu.RLock()
+	due := true
+	for i := range s.records {
+		record := &s.records[i]
+		if record.RetiredAt == nil && record.Algorithm == s.Algorithm && time.Since(record.CreatedAt) < s.RotationInterval {
+			due = false
+			break
+		}
+	}
+	s.mu.RUnlock()
+	if !due {
+		return nil
+	}
+	_, err := s.Rotate(ctx)
+	return err
+}
+
+// Rotate creates a new signing key and retires the previous ones. Retired
+// keys keep verifying the tokens they signed until those expire.
+func (s *KeyService) Rotate(ctx context.Context) (*models.SigningKey, error) {
+	key, err := utils.GenerateJWTKey(uuid.New().String(), s.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	privateKey, err := key.EncodePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := key.EncodePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	record := &models.SigningKey{
+		ID:         key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.Keys.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	existing, err := s.Keys.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing keys: %w", err)
+	}
+	now := time.Now()
+	expiresAt := now.Add(retiredKeyLifetime)
+	for i := range existing {
+		old := &existing[i]
+		if old.ID == record.ID || old.RetiredAt != nil {
+			continue
+		}
+		old.RetiredAt = &now
+		old.ExpiresAt = &expiresAt
+		if err := s.Keys.Update(ctx, old); err != nil {
+			return nil, fmt.Errorf("failed to retire signing key: %w", err)
+		}
+	}
+	log.Printf("rotated signing keys, new key %s", record.ID)
+
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// ListKeys returns every key that still verifies, newest first.
+func (s *KeyService) ListKeys(ctx context.Context) ([]models.SigningKey, error) {
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.SigningKey(nil), s.records...), nil
+}
+
+// SigningKey returns the newest key that is not retired. It implements
+// utils.JWTKeySet.
+func (s *KeyService) SigningKey() (*utils.JWTKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	// Records are sorted newest first. Fall back to a retired key if another
+	// instance rotated and this one has not reloaded yet.
+	for i := range s.records {
+		if s.records[i].RetiredAt == nil {
+			return s.parsed[s.records[i].ID], nil
+		}
+	}
+	if len(s.records) > 0 {
+		return s.parsed[s.records[0].ID], nil
+	}
+	return nil, errors.New("no signing key available")
+}
+
+// VerificationKey returns the key with the given ID. Unknown IDs trigger a
+// reload so that keys created by other instances are found. It implements
+// utils.JWTKeySet.
+func (s *KeyService) VerificationKey(kid string) (*utils.JWTKey, error) {
+	if key, ok := s.cachedKey(kid); ok {
+		return key, nil
+	}
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > unknownKeyReloadInterval
+	s.mu.RUnlock()
+	if stale {
+		if err := s.reload(context.Background()); err != nil {
+			return nil, err
+		}
+		if key, ok := s.cachedKey(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+// JWKS returns the public keys that currently verify tokens.
+func (s *KeyService) JWKS() ([]utils.JWK, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jwks := []utils.JWK{}
+	now := time.Now()
+	for i := range s.records {
+		if !s.records[i].Verifies(now) {
+			continue
+		}
+		jwk, err := s.parsed[s.records[i].ID].JWK()
+		if err != nil {
+			return nil, err
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks, nil
+}
+
+func (s *KeyService) cachedKey(kid string) (*utils.JWTKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.records {
+		if s.records[i].ID == kid && s.records[i].Verifies(time.Now()) {
+			return s.parsed[kid], true
+		}
+	}
+	return nil, false
+}
+
+// reload replaces the cached keys with the ones in the repository.
+func (s *KeyService) reload(ctx context.Context) error {
+	all, err := s.Keys.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get signing keys: %w", err)
+	}
+	now := time.Now()
+	records := make([]models.SigningKey, 0, len(all))
+	parsed := make(map[string]*utils.JWTKey, len(all))
+	for _, record := range all {
+		if !record.Verifies(now) {
+			continue
+		}
+		key, err := utils.ParseJWTKey(record.ID, record.Algorithm, record.PrivateKey, record.PublicKey)
+		if err != nil {
+			return fmt.Errorf("failed to parse signing key %s: %w", record.ID, err)
+		}
+		records = append(records, record)
+		parsed[record.ID] = key
+	}
+
+	s.mu.Lock()
+	s.records = records
+	s.parsed = parsed
+	s.loadedAt = now
+	s.mu.Unlock()
+	return nil
+}
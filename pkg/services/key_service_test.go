@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+func newTestKeyService(t *testing.T, algorithm string) *KeyService {
+	t.Helper()
+	keys := NewKeyService(newTestRepositories(t).SigningKeys, algorithm, 0)
+	if err := keys.Init(context.Background()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	return keys
+}
+
+func jwkIDs(t *testing.T, keys *KeyService) []string {
+	t.Helper()
+	jwks, err := keys.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS: %v", err)
+	}
+	ids := make([]string, len(jwks))
+	for i, jwk := range jwks {
+		ids[i] = jwk.KeyID
+	}
+	return ids
+}
+
+func findSigningKey(ctx context.Context, keys *KeyService, id string) (*models.SigningKey, error) {
+	all, err := keys.Keys.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range all {
+		if all[i].ID == id {
+			return &all[i], nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func TestKeyServiceRotation(t *testing.T) {
+	for _, algorithm := range []string{utils.AlgorithmRS256, utils.AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			ctx := context.Background()
+			keys := newTestKeyService(t, algorithm)
+			first, err := keys.SigningKey()
+			if err != nil {
+				t.Fatalf("SigningKey: %v", err)
+			}
+			if first.Algorithm != algorithm {
+				t.Errorf("algorithm: got %q, want %q", first.Algorithm, algorithm)
+			}
+			oldToken, err := utils.GenerateJWT(keys, "", "user-1", "session-1")
+			if err != nil {
+				t.Fatalf("GenerateJWT: %v", err)
+			}
+
+			rotated, err := keys.Rotate(ctx)
+			if err != nil {
+				t.Fatalf("Rotate: %v", err)
+			}
+			current, err := keys.SigningKey()
+			if err != nil {
+				t.Fatalf("SigningKey: %v", err)
+			}
+			if current.ID != rotated.ID || current.ID == first.ID {
+				t.Errorf("signing key after rotation: got %q, want %q", current.ID, rotated.ID)
+			}
+
+			// Tokens of the retired key keep verifying until they expire.
+			if _, err := keys.VerificationKey(first.ID); err != nil {
+				t.Errorf("VerificationKey of the retired key: %v", err)
+			}
+			if _, err := utils.VerifyJWT(keys, "", oldToken); err != nil {
+				t.Errorf("VerifyJWT of a token of the retired key: %v", err)
+			}
+			newToken, err := utils.GenerateJWT(keys, "", "user-1", "session-1")
+			if err != nil {
+				t.Fatalf("GenerateJWT: %v", err)
+			}
+			if _, err := utils.VerifyJWT(keys, "", newToken); err != nil {
+				t.Errorf("VerifyJWT of a token of the new key: %v", err)
+			}
+
+			// Both keys are published.
+			ids := jwkIDs(t, keys)
+			sort.Strings(ids)
+			want := []string{rotated.ID, first.ID}
+			sort.Strings(want)
+			if !reflect.DeepEqual(ids, want) {
+				t.Errorf("JWKS: got %v, want %v", ids, want)
+			}
+		})
+	}
+}
+
+func TestKeyServiceRetiredKeyExpiry(t *testing.T) {
+	ctx := context.Background()
+	keys := newTestKeyService(t, utils.AlgorithmEdDSA)
+	first, err := keys.SigningKey()
+	if err != nil {
+		t.Fatalf("SigningKey: %v", err)
+	}
+	token, err := utils.GenerateJWT(keys, "", "user-1", "session-1")
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	rotated, err := keys.Rotate(ctx)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	// Let the retired key expire.
+	record, err := findSigningKey(ctx, keys, first.ID)
+	if err != nil {
+		t.Fatalf("findSigningKey: %v", err)
+	}
+	if record.RetiredAt == nil || record.ExpiresAt == nil {
+		t.Fatalf("retired key: got retired %v, expires %v", record.RetiredAt, record.ExpiresAt)
+	}
+	expired := time.Now().Add(-time.Second)
+	record.ExpiresAt = &expired
+	if err := keys.Keys.Update(ctx, record); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := keys.ListKeys(ctx); err != nil {
+		t.Fatalf("ListKeys: %v", err)
+	}
+
+	if _, err := keys.VerificationKey(first.ID); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("VerificationKey of the expired key: got error %v, want %v", err, ErrUnknownKey)
+	}
+	if _, err := utils.VerifyJWT(keys, "", token); err == nil {
+		t.Error("VerifyJWT of a token of the expired key: got no error")
+	}
+	if ids := jwkIDs(t, keys); len(ids) != 1 || ids[0] != rotated.ID {
+		t.Errorf("JWKS: got %v, want [%s]", ids, rotated.ID)
+	}
+}
+
+func TestKeyServiceRotateIfDue(t *testing.T) {
+	tests := []struct {
+		name string
+		// age is how old the signing key is.
+		age         time.Duration
+		wantRotated bool
+	}{
+		{name: "fresh key", age: time.Hour},
+		{name: "due key", age: DefaultKeyRotationInterval + time.Hour, wantRotated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			keys := newTestKeyService(t, utils.AlgorithmEdDSA)
+			first, err := keys.SigningKey()
+			if err != nil {
+				t.Fatalf("SigningKey: %v", err)
+			}
+			record, err := findSigningKey(ctx, keys, first.ID)
+			if err != nil {
+				t.Fatalf("findSigningKey: %v", err)
+			}
+			record.CreatedAt = time.Now().Add(-tt.age)
+			if err := keys.Keys.Update(ctx, record); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			if _, err := keys.ListKeys(ctx); err != nil {
+				t.Fatalf("ListKeys: %v", err)
+			}
+
+			if err := keys.RotateIfDue(ctx); err != nil {
+				t.Fatalf("RotateIfDue: %v", err)
+			}
+			current, err := keys.SigningKey()
+			if err != nil {
+				t.Fatalf("SigningKey: %v", err)
+			}
+			if rotated := current.ID != first.ID; rotated != tt.wantRotated {
+				t.Errorf("rotated: got %v, want %v", rotated, tt.wantRotated)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+)
+
+// loginFailureWindow is how long failed logins are remembered.
+const loginFailureWindow = time.Hour
+
+// throttlePolicy decides how failed logins of one kind slow down further
+// attempts. After backoffAfter failures every attempt has to wait twice as
+// long as the previous one, up to maxBackoff. After lockoutAfter failures
+// logins are blocked for lockout, doubling with every further failure up to
+// maxLockout.
+type throttlePolicy struct {
+	backoffAfter int
+	maxBackoff   time.Duration
+	lockoutAfter int
+	lockout      time.Duration
+	maxLockout   time.Duration
+}
+
+var (
+	accountThrottlePolicy = throttlePolicy{
+		backoffAfter: 3,
+		maxBackoff:   time.Minute,
+		lockoutAfter: 10,
+		lockout:      15 * time.Minute,
+		maxLockout:   24 * time.Hour,
+	}
+	// IPs get more room since several users may share one.
+	ipThrottlePolicy = throttlePolicy{
+		backoffAfter: 10,
+		maxBackoff:   time.Minute,
+		lockoutAfter: 50,
+		lockout:      15 * time.Minute,
+		maxLockout:   24 * time.Hour,
+	}
+)
+
+// ThrottledError is returned when a login has to wait before it may be
+// attempted again.
+type ThrottledError struct {
+	Wait time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return "too many failed login attempts"
+}
+
+// LoginThrottleService tracks failed logins per account and per IP.
+type LoginThrottleService struct {
+	Throttles repositories.LoginThrottleRepository
+	Audit     *AuditService
+}
+
+// NewLoginThrottleService creates a new LoginThrottleService.
+func NewLoginThrottleService(throttles repositories.LoginThrottleRepository, audit *AuditService) *LoginThrottleService {
+	return &LoginThrottleService{Throttles: throttles, Audit: audit}
+}
+
+// Check returns how long a login for email from ip has to wait. Zero means
+// the attempt may proceed.
+func (s *LoginThrottleService) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range throttleKeys(email, ip) {
+		throttle, err := s.Throttles.FindByID(ctx, models.LoginThrottleID(key.kind, key.subject))
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				continue
+			}
+			return 0, fmt.Errorf("failed to get login throttle: %w", err)
+		}
+		if w := key.policy.wait(throttle, now); w > wait {
+			wait = w
+		}
+	}
+	return wait, nil
+}
+
+// RecordFailure counts a failed login and locks the account or IP once
+// their policy says so. userID is empty for unknown emails.
+func (s *LoginThrottleService) RecordFailure(ctx context.Context, email, ip, userID string) error {
+	now := time.Now()
+	for _, key := range throttleKeys(email, ip) {
+		throttle, err := s.Throttles.RecordFailure(ctx, key.kind, key.subject, now, loginFailureWindow)
+		if err != nil {
+			return fmt.Errorf("failed to record login failure: %w", err)
+		}
+		if throttle.Failures < key.policy.lockoutAfter {
+			continue
+		}
+
+		lockedUntil := now.Add(key.policy.lockoutDuration(throttle.Failures))
+		throttle.LockedUntil = &lockedUntil
+		throttle.ExpiresAt = lockedUntil.Add(loginFailureWindow)
+		if err := s.Throttles.Update(ctx, throttle); err != nil {
+			return fmt.Errorf("failed to lock login: %w", err)
+		}
+
+		entry := models.AuditEntry{
+			Action:       AuditLoginLockout,
+			ResourceType: key.kind,
+			ResourceID:   key.subject,
+			IP:           ip,
+			Details: map[string]string{
+				"failures":     strconv.Itoa(throttle.Failures),
+				"locked_until": lockedUntil.UTC().Format(time.RFC3339),
+			},
+		}
+		if key.kind == models.ThrottleKindAccount && userID != "" {
+			entry.ResourceType = "user"
+			entry.ResourceID = userID
+			entry.Details["email"] = key.subject
+		}
+		if err := s.Audit.Record(ctx, entry); err != nil {
+			log.Println("Error recording lockout:", err)
+		}
+	}
+	return nil
+}
+
+// RecordSuccess clears the failed logins of an account. Failures of the IP
+// are kept so one valid account cannot be used to reset them.
+func (s *LoginThrottleService) RecordSuccess(ctx context.Context, email string) error {
+	err := s.Throttles.Delete(ctx, models.LoginThrottleID(models.ThrottleKindAccount, normalizeEmail(email)))
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return fmt.Errorf("failed to reset login throttle: %w", err)
+	}
+	return nil
+}
+
+// ListLocked returns the accounts and IPs that are currently locked.
+func (s *LoginThrottleService) ListLocked(ctx context.Context) ([]models.LoginThrottle, error) {
+	throttles, err := s.Throttles.FindLocked(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login lockouts: %w", err)
+	}
+	return throttles, nil
+}
+
+// Unlock removes a throttle by ID and records who did it.
+func (s *LoginThrottleService) Unlock(ctx context.Context, id, actorID, ip string) error {
+	if err := s.Throttles.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to unlock login: %w", err)
+	}
+	kind, subject, _ := strings.Cut(id, ":")
+	entry := models.AuditEntry{
+		ActorID:      actorID,
+		Action:       AuditLoginUnlock,
+		ResourceType: kind,
+		ResourceID:   subject,
+		IP:           ip,
+	}
+	if err := s.Audit.Record(ctx, entry); err != nil {
+		log.Println("Error recording unlock:", err)
+	}
+	return nil
+}
+
+// UnlockAccount clears the failed logins of a user.
+func (s *LoginThrottleService) UnlockAccount(ctx context.Context, user *models.User, actorID, ip string) error {
+	err := s.Unlock(ctx, models.LoginThrottleID(models.ThrottleKindAccount, normalizeEmail(user.Email)), actorID, ip)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+type throttleKey struct {
+	kind    string
+	subject string
+	policy  throttlePolicy
+}
+
+func throttleKeys(email, ip string) []throttleKey {
+	return []throttleKey{
+		{kind: models.ThrottleKindAccount, subject: normalizeEmail(email), policy: accountThrottlePolicy},
+		{kind: models.ThrottleKindIP, subject: ip, policy: ipThrottlePolicy},
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// wait returns how long the next attempt has to wait at the given time.
+func (p throttlePolicy) wait(throttle *models.LoginThrottle, now time.Time) time.Duration {
+	if throttle.Locked(now) {
+		return throttle.LockedUntil.Sub(now)
+	}
+	if throttle.Failures < p.backoffAfter || now.Sub(throttle.LastFailureAt) > loginFailureWindow {
+		return 0
+	}
+	backoff := doubled(time.Second, throttle.Failures-p.backoffAfter, p.maxBackoff)
+	if next := throttle.LastFailureAt.Add(backoff); now.Before(next) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+// lockoutDuration returns how long to lock after the given failure count.
+func (p throttlePolicy) lockoutDuration(failures int) time.Duration {
+	return doubled(p.lockout, failures-p.lockoutAfter, p.maxLockout)
+}
+
+// doubled returns base doubled n times, capped at max.
+func doubled(base time.Duration, n int, max time.Duration) time.Duration {
+	d := base
+	for i := 0; i < n && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// OIDCLoginTTL is how long a sign-in with the identity provider can take.
+const OIDCLoginTTL = 10 * time.Minute
+
+var (
+	// ErrInvalidOIDCState is returned for unknown, expired or reused state
+	// parameters.
+	ErrInvalidOIDCState = errors.New("invalid login state")
+	// ErrOIDCLogin is returned when the identity provider does not confirm
+	// the sign-in.
+	ErrOIDCLogin = errors.New("identity provider login failed")
+	// ErrOIDCNoRole is returned for provider accounts that no role is mapped
+	// to.
+	ErrOIDCNoRole = errors.New("no role is mapped to this account")
+)
+
+// rolePrecedence decides which role wins when an account matches several.
+var rolePrecedence = []models.Role{models.RoleAdmin, models.RoleEditor, models.RoleAuthor, models.RoleViewer}
+
+// OIDCService signs staff in with the company identity provider and creates
+// their accounts on first sign-in. Roles come from a claim of the ID token,
+// usually the groups, and are updated on every sign-in.
+type OIDCService struct {
+	Client *utils.OIDCClient
+	Logins repositories.OIDCLoginRepository
+	Users  *UserService
+	// RoleClaim names the claim holding the groups, e.g. "groups" or
+	// "realm_access.roles" for nested claims.
+	RoleClaim string
+	// RoleMapping maps claim values to roles.
+	RoleMapping map[string]models.Role
+	// DefaultRole is given to accounts that match no mapping. If empty they
+	// cannot sign in.
+	DefaultRole models.Role
+}
+
+// NewOIDCService creates a new OIDCService.
+func NewOIDCService(client *utils.OIDCClient, logins repositories.OIDCLoginRepository, users *UserService, roleClaim string, roleMapping map[string]models.Role, defaultRole models.Role) *OIDCService {
+	return &OIDCService{
+		Client:      client,
+		Logins:      logins,
+		Users:       users,
+		RoleClaim:   roleClaim,
+		RoleMapping: roleMapping,
+		DefaultRole: defaultRole,
+	}
+}
+
+// BeginLogin starts a sign-in and returns the provider URL to send the user
+// to.
+func (s *OIDCService) BeginLogin(ctx context.Context) (string, error) {
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate login state: %w", err)
+	}
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	now := time.Now()
+	login := &models.OIDCLogin{
+		ID:           uuid.New().String(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(OIDCLoginTTL),
+	}
+	state := login.ID + "." + secret
+	login.StateHash = utils.HashToken(state)
+
+	authURL, err := s.Client.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+	if err := s.Logins.Create(ctx, login); err != nil {
+		return "", fmt.Errorf("failed to create login: %w", err)
+	}
+	return authURL, nil
+}
+
+// CompleteLogin redeems the code the provider sent back and returns the
+// local user, creating or updating it from the ID token.
+func (s *OIDCService) CompleteLogin(ctx context.Context, state, code string) (*models.User, error) {
+	id, _, ok := strings.Cut(state, ".")
+	if !ok || code == "" {
+		return nil, ErrInvalidOIDCState
+	}
+	login, err := s.Logins.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, fmt.Errorf("failed to get login: %w", err)
+	}
+	if login.StateHash != utils.HashToken(state) {
+		return nil, ErrInvalidOIDCState
+	}
+	// The state can only be used once.
+	if err := s.Logins.Delete(ctx, login.ID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, fmt.Errorf("failed to delete login: %w", err)
+	}
+	if !time.Now().Before(login.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	rawToken, err := s.Client.Exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLogin, err)
+	}
+	idToken, err := s.Client.VerifyIDToken(ctx, rawToken, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLogin, err)
+	}
+
+	role := s.mapRole(idToken)
+	if role == "" {
+		return nil, ErrOIDCNoRole
+	}
+	return s.provision(ctx, idToken, role)
+}
+
+// provision finds the user linked to the provider account and updates it.
+// Unknown accounts are linked to an existing user with the same verified
+// email, or created.
+func (s *OIDCService) provision(ctx context.Context, idToken *utils.IDToken, role models.Role) (*models.User, error) {
+	user, err := s.Users.Users.FindByOIDCSubject(ctx, s.Client.Issuer, idToken.Subject)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	email := strings.TrimSpace(idToken.Email)
+	if user == nil {
+		if email == "" {
+			return nil, fmt.Errorf("%w: ID token has no email", ErrOIDCLogin)
+		}
+		user, err = s.Users.Users.FindByEmail(ctx, email)
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			return s.createUser(ctx, idToken, email, role)
+		case err != nil:
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		case user.OIDCSubject != "" || !idToken.EmailVerified:
+			// Only link accounts the provider vouches for.
+			return nil, ErrUserExists
+		}
+		user.OIDCIssuer = s.Client.Issuer
+		user.OIDCSubject = idToken.Subject
+		log.Printf("linked user %s to identity provider account %s", user.ID, idToken.Subject)
+	}
+
+	if name := strings.TrimSpace(idToken.Name); name != "" {
+		user.Name = name
+	}
+	if email != "" && idToken.EmailVerified {
+		user.Email = email
+	}
+	if user.EffectiveRole() != role {
+		if err := s.Users.ensureOtherAdmin(ctx, user); err != nil {
+			if !errors.Is(err, ErrLastAdmin) {
+				return nil, err
+			}
+			log.Printf("kept admin role of user %s since they are the last admin", user.ID)
+		} else {
+			user.Role = role
+			user.IsAdmin = role == models.RoleAdmin
+		}
+	}
+	if err := s.Users.save(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *OIDCService) createUser(ctx context.Context, idToken *utils.IDToken, email string, role models.Role) (*models.User, error) {
+	name := strings.TrimSpace(idToken.Name)
+	if name == "" {
+		name = email
+	}
+	now := time.Now()
+	user := &models.User{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Email:       email,
+		Role:        role,
+		IsAdmin:     role == models.RoleAdmin,
+		OIDCIssuer:  s.Client.Issuer,
+		OIDCSubject: idToken.Subject,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.Users.Users.Create(ctx, user); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrUserExists
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	log.Printf("created user %s for identity provider account %s", user.ID, idToken.Subject)
+	return user, nil
+}
+
+// mapRole returns the highest role mapped to a value of the role claim, or
+// the default role.
+func (s *OIDCService) mapRole(idToken *utils.IDToken) models.Role {
+	matched := map[models.Role]bool{}
+	for _, value := range claimValues(idToken.Claims, s.RoleClaim) {
+		if role, ok := s.RoleMapping[value]; ok {
+			matched[role] = true
+		}
+	}
+	for _, role := range rolePrecedence {
+		if matched[role] {
+			return role
+		}
+	}
+	return s.DefaultRole
+}
+
+// claimValues returns the strings of a claim that is either a string or a
+// list. Dots in the name select nested claims.
+func claimValues(claims map[string]interface{}, name string) []string {
+	var value interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// PasswordResetTTL is how long a password reset link can be used.
+const PasswordResetTTL = time.Hour
+
+const (
+	// resetRequestWindow is the period reset requests are counted over.
+	resetRequestWindow = time.Hour
+	// maxResetRequestsPerEmail is how many reset mails one address can be
+	// sent per window.
+	maxResetRequestsPerEmail = 3
+	// maxResetRequestsPerIP is how many resets one client can request per
+	// window.
+	maxResetRequestsPerIP = 20
+	// resetMailTimeout bounds sending a reset mail in the background.
+	resetMailTimeout = time.Minute
+)
+
+var (
+	// ErrInvalidResetToken is returned for unknown, expired or used password
+	// reset tokens.
+	ErrInvalidResetToken = errors.New("invalid password reset token")
+	// ErrTooManyResetRequests is returned when a client requested too many
+	// password resets.
+	ErrTooManyResetRequests = errors.New("too many password reset requests")
+)
+
+// PasswordResetService lets users who forgot their password set a new one.
+type PasswordResetService struct {
+	Resets repositories.PasswordResetRepository
+	// Throttles counts reset requests per email and per IP.
+	Throttles repositories.LoginThrottleRepository
+	Users     *UserService
+	Mailer    utils.Mailer
+	// ResetURL is the frontend page the token is appended to as the "token"
+	// query parameter. The bare token is mailed when it is empty.
+	ResetURL string
+}
+
+// NewPasswordResetService creates a new PasswordResetService.
+func NewPasswordResetService(resets repositories.PasswordResetRepository, throttles repositories.LoginThrottleRepository, users *UserService, mailer utils.Mailer, resetURL string) *PasswordResetService {
+	return &PasswordResetService{Resets: resets, Throttles: throttles, Users: users, Mailer: mailer, ResetURL: resetURL}
+}
+
+// RequestReset mails a reset token to the user with the given email in the
+// background. Unknown and deactivated accounts are silently ignored, and the
+// call returns before the mail is sent, so that callers cannot find out
+// which emails are registered. Requests beyond the limit of an email are
+// dropped just as silently; ErrTooManyResetRequests is only returned when
+// the IP exceeds its limit.
+func (s *PasswordResetService) RequestReset(ctx context.Context, email, ip string) error {
+	email = strings.TrimSpace(email)
+	over, err := s.countRequest(ctx, models.ThrottleKindResetIP, ip, maxResetRequestsPerIP)
+	if err != nil {
+		return err
+	}
+	if over {
+		return ErrTooManyResetRequests
+	}
+	over, err = s.countRequest(ctx, models.ThrottleKindResetEmail, normalizeEmail(email), maxResetRequestsPerEmail)
+	if err != nil {
+		return err
+	}
+	if over {
+		log.Printf("password reset limit reached for %s", email)
+		return nil
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), resetMailTimeout)
+		defer cancel()
+		if err := s.sendReset(ctx, email, ip); err != nil {
+			log.Println("Error sending password reset:", err)
+		}
+	}()
+	return nil
+}
+
+// countRequest counts a reset request against a subject and reports whether
+// it exceeds the limit of the window.
+func (s *PasswordResetService) countRequest(ctx context.Context, kind, subject string, limit int) (bool, error) {
+	throttle, err := s.Throttles.RecordFailure(ctx, kind, subject, time.Now(), resetRequestWindow)
+	if err != nil {
+		return false, fmt.Errorf("failed to count password reset request: %w", err)
+	}
+	return throttle.Failures > limit, nil
+}
+
+// sendReset creates a reset token for the user with the given email and
+// mails it.
+func (s *PasswordResetService) sendReset(ctx context.Context, email, ip string) error {
+	user, err := s.Users.Users.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	// Users of the identity provider have no local password to reset.
+	if !user.Active() || user.OIDCSubject != "" {
+		return nil
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	now := time.Now()
+	reset := &models.PasswordReset{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		IP:        ip,
+		CreatedAt: now,
+		ExpiresAt: now.Add(PasswordResetTTL),
+	}
+	token := reset.ID + "." + secret
+	reset.TokenHash = utils.HashToken(token)
+	if err := s.Resets.Create(ctx, reset); err != nil {
+		return fmt.Errorf("failed to create password reset: %w", err)
+	}
+
+	return s.Mailer.Send(ctx, s.resetMail(user, token))
+}
+
+// ConfirmReset sets a new password using a reset token. Every session of the
+// user is revoked and all other pending resets are invalidated.
+func (s *PasswordResetService) ConfirmReset(ctx context.Context, token, password string) error {
+	id, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidResetToken
+	}
+	reset, err := s.Resets.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to get password reset: %w", err)
+	}
+	if reset.TokenHash != utils.HashToken(token) || !reset.Usable(time.Now()) {
+		return ErrInvalidResetToken
+	}
+	if len(password) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+
+	user, err := s.Users.GetUser(ctx, reset.UserID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !user.Active() {
+		return ErrInvalidResetToken
+	}
+
+	// Use the token up before changing the password so it cannot be
+	// replayed if a later step fails.
+	if err := s.Resets.MarkAllUsedForUser(ctx, user.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to use password reset: %w", err)
+	}
+	if err := s.Users.SetPassword(ctx, user, password); err != nil {
+		return err
+	}
+	if err := s.Users.Sessions.RevokeAllForUser(ctx, user.ID, "password reset"); err != nil {
+		return err
+	}
+	log.Printf("password of user %s reset from request %s", user.ID, reset.ID)
+	return nil
+}
+
+func (s *PasswordResetService) resetMail(user *models.User, token string) utils.Mail {
+	link := token
+	if s.ResetURL != "" {
+		separator := "?"
+		if strings.Contains(s.ResetURL, "?") {
+			separator = "&"
+		}
+		link = s.ResetURL + separator + "token=" + url.QueryEscape(token)
+	}
+	return utils.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"we received a request to reset your password. Use the following link within %d minutes to choose a new one:\n\n"+
+			"%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			user.Name, int(PasswordResetTTL.Minutes()), link),
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// SearchType is a kind of record the site search covers.
+type SearchType string
+
+const (
+	SearchTypeBlog    SearchType = "blog"
+	SearchTypeVideo   SearchType = "video"
+	SearchTypeService SearchType = "service"
+)
+
+// SearchTypes lists every searchable type.
+var SearchTypes = []SearchType{SearchTypeBlog, SearchTypeVideo, SearchTypeService}
+
+// maxSearchLength is the longest search text accepted.
+const maxSearchLength = 200
+
+// snippetLength is the length of result snippets in characters.
+const snippetLength = 160
+
+// ErrInvalidSearch is returned for empty or overly long searches and unknown
+// types.
+var ErrInvalidSearch = errors.New("invalid search")
+
+// SearchQuery is a site search. Empty Types searches every type.
+type SearchQuery struct {
+	Text  string
+	Types []SearchType
+	Limit int
+}
+
+// SearchResult is a record matching a search. TitleHTML and Snippet are
+// HTML with the matching words wrapped in <mark> elements.
+type SearchResult struct {
+	Type      SearchType `json:"type"`
+	ID        string     `json:"id"`
+	Slug      string     `json:"slug,omitempty"`
+	Title     string     `json:"title"`
+	TitleHTML string     `json:"title_html"`
+	Snippet   string     `json:"snippet"`
+	Score     float64    `json:"score"`
+}
+
+// SearchService searches published blogs, videos and services.
+type SearchService struct {
+	Blogs    repositories.BlogRepository
+	Videos   repositories.VideoRepository
+	Services repositories.ServiceRepository
+}
+
+// NewSearchService creates a new SearchService.
+func NewSearchService(blogs repositories.BlogRepository, videos repositories.VideoRepository, services repositories.ServiceRepository) *SearchService {
+	return &SearchService{Blogs: blogs, Videos: videos, Services: services}
+}
+
+// Search returns the records matching a search, best first.
+func (s *SearchService) Search(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	text := strings.TrimSpace(q.Text)
+	if text == "" || len(text) > maxSearchLength {
+		return nil, fmt.Errorf("%w: search text must have 1 to %d characters", ErrInvalidSearch, maxSearchLength)
+	}
+	types := q.Types
+	if len(types) == 0 {
+		types = SearchTypes
+	}
+	terms := utils.SearchTerms(text)
+	query := repositories.ListQuery{Limit: q.Limit}
+
+	results := []SearchResult{}
+	for _, t := range types {
+		var found []SearchResult
+		var err error
+		switch t {
+		case SearchTypeBlog:
+			found, err = s.searchBlogs(ctx, text, terms, query)
+		case SearchTypeVideo:
+			found, err = s.searchVideos(ctx, text, terms, query)
+		case SearchTypeService:
+			found, err = s.searchServices(ctx, text, terms, query)
+		default:
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSearch, t)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	limit := q.Limit
+	if limit <= 0 {
+		limit = repositories.DefaultPageSize
+	}
+	if limit > repositories.MaxPageSize {
+		limit = repositories.MaxPageSize
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (s *SearchService) searchBlogs(ctx context.Context, text string, terms []string, query repositories.ListQuery) ([]SearchResult, error) {
+	query.AnyOf = visibleBlogFilters(time.Now())
+	hits, err := s.Blogs.Search(ctx, text, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search blogs: %w", err)
+	}
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		blog := hit.Item
+		content, err := utils.MarkdownText(blog.Content)
+		if err != nil {
+			log.Println("Error rendering blog for search:", err)
+			content = blog.Content
+		}
+		results = append(results, newSearchResult(SearchTypeBlog, blog.ID, blog.Title, content, terms, hit.Score))
+		results[len(results)-1].Slug = blog.Slug
+	}
+	return results, nil
+}
+
+func (s *SearchService) searchVideos(ctx context.Context, text string, terms []string, query repositories.ListQuery) ([]SearchResult, error) {
+	hits, err := s.Videos.Search(ctx, text, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search videos: %w", err)
+	}
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, newSearchResult(SearchTypeVideo, hit.Item.ID, hit.Item.Title, hit.Item.Content, terms, hit.Score))
+	}
+	return results, nil
+}
+
+func (s *SearchService) searchServices(ctx context.Context, text string, terms []string, query repositories.ListQuery) ([]SearchResult, error) {
+	hits, err := s.Services.Search(ctx, text, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search services: %w", err)
+	}
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		service := hit.Item
+		body := service.Description
+		if service.Location != "" {
+			body = service.Location + " — " + body
+		}
+		results = append(results, newSearchResult(SearchTypeService, service.ID, service.Name, body, terms, hit.Score))
+	}
+	return results, nil
+}
+
+func newSearchResult(t SearchType, id, title, body string, terms []string, score float64) SearchResult {
+	return SearchResult{
+		Type:      t,
+		ID:        id,
+		Title:     title,
+		TitleHTML: utils.HighlightSnippet(title, terms, len(title)),
+		Snippet:   utils.HighlightSnippet(body, terms, snippetLength),
+		Score:     score,
+	}
+}
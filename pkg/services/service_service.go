@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+)
+
+// ServiceService handles the services offered on the site.
+type ServiceService struct {
+	Services repositories.ServiceRepository
+}
+
+// NewServiceService creates a new ServiceService.
+func NewServiceService(services repositories.ServiceRepository) *ServiceService {
+	return &ServiceService{Services: services}
+}
+
+// CreateService stores a new service and returns it with its generated ID.
+func (s *ServiceService) CreateService(ctx context.Context, service models.Service) (*models.Service, error) {
+	service.ID = uuid.New().String()
+	if err := s.Services.Create(ctx, &service); err != nil {
+		return nil, fmt.Errorf("failed to create service: %w", err)
+	}
+	return &service, nil
+}
+
+// GetService returns a service by ID.
+func (s *ServiceService) GetService(ctx context.Context, id string) (*models.Service, error) {
+	service, err := s.Services.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	return service, nil
+}
+
+// GetAllServices returns a page of services.
+func (s *ServiceService) GetAllServices(ctx context.Context, query repositories.ListQuery) (*repositories.Page[models.Service], error) {
+	page, err := s.Services.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	return page, nil
+}
+
+// UpdateService replaces an existing service.
+func (s *ServiceService) UpdateService(ctx context.Context, service models.Service) (*models.Service, error) {
+	if err := s.Services.Update(ctx, &service); err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+	return &service, nil
+}
+
+// DeleteService deletes a service by ID.
+func (s *ServiceService) DeleteService(ctx context.Context, id string) error {
+	if err := s.Services.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+	return nil
+}
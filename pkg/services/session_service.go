@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+const (
+	// RefreshTokenTTL is how long a session lasts without being refreshed.
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	// maxPreviousTokenHashes bounds the rotated token hashes kept per session
+	// for reuse detection.
+	maxPreviousTokenHashes = 50
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or malformed
+	// refresh tokens.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again. The session is revoked since the token has most
+	// likely leaked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrSessionRevoked is returned for sessions that were revoked or expired.
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+// TokenPair is returned on login and refresh.
+type TokenPair struct {
+	AccessToken  string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// SessionService issues, rotates and revokes refresh tokens, and issues and
+// verifies the access tokens that go with them.
+type SessionService struct {
+	Sessions repositories.SessionRepository
+	Keys     utils.JWTKeySet
+	// Issuer is the "iss" claim of access tokens. It is not checked when
+	// empty.
+	Issuer string
+}
+
+// NewSessionService creates a new SessionService.
+func NewSessionService(sessions repositories.SessionRepository, keys utils.JWTKeySet, issuer string) *SessionService {
+	return &SessionService{Sessions: sessions, Keys: keys, Issuer: issuer}
+}
+
+// CreateSession starts a session for a user who just logged in.
+func (s *SessionService) CreateSession(ctx context.Context, userID, userAgent, ip string) (*TokenPair, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+	}
+	refreshToken, err := s.rotate(session)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Sessions.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return s.tokenPair(session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new
+// refresh token. The presented token becomes invalid.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken, userAgent, ip string) (*TokenPair, error) {
+	session, err := s.sessionForToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	previousHash := session.RefreshTokenHash
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(RefreshTokenTTL)
+	session.UserAgent = userAgent
+	session.IP = ip
+	newToken, err := s.rotate(session)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Sessions.Rotate(ctx, session, previousHash); err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("failed to update session: %w", err)
+		}
+		// A concurrent refresh rotated the token first or the session was
+		// revoked meanwhile. Looking the token up again tells which, and
+		// revokes the session on reuse.
+		if _, err := s.sessionForToken(ctx, refreshToken); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	return s.tokenPair(session, newToken)
+}
+
+// Logout revokes the session a refresh token belongs to.
+func (s *SessionService) Logout(ctx context.Context, refreshToken string) error {
+	session, err := s.sessionForToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.revoke(ctx, session, "logout")
+}
+
+// ValidateSession returns ErrSessionRevoked unless the session is active.
+func (s *SessionService) ValidateSession(ctx context.Context, sessionID string) error {
+	session, err := s.Sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrSessionRevoked
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if !session.Active(time.Now()) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// VerifyAccessToken checks the signature and expiry of an access token and
+// returns its claims. Whether the session is still active is checked by
+// ValidateSession.
+func (s *SessionService) VerifyAccessToken(token string) (*utils.Claims, error) {
+	return utils.VerifyJWT(s.Keys, s.Issuer, token)
+}
+
+// RevokeAllForUser ends every session of a user, for example after a
+// password change.
+func (s *SessionService) RevokeAllForUser(ctx context.Context, userID, reason string) error {
+	if err := s.Sessions.RevokeAllForUser(ctx, userID, time.Now(), reason); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// sessionForToken returns the active session a refresh token belongs to.
+// Presenting a rotated token revokes the session.
+func (s *SessionService) sessionForToken(ctx context.Context, refreshToken string) (*models.Session, error) {
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+	session, err := s.Sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if !session.Active(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	hash := utils.HashToken(refreshToken)
+	if hash == session.RefreshTokenHash {
+		return session, nil
+	}
+	for _, previous := range session.PreviousTokenHashes {
+		if hash == previous {
+			log.Printf("refresh token reuse detected for session %s of user %s", session.ID, session.UserID)
+			if err := s.revoke(ctx, session, "refresh token reuse"); err != nil {
+				return nil, err
+			}
+			return nil, ErrRefreshTokenReused
+		}
+	}
+	return nil, ErrInvalidRefreshToken
+}
+
+// revoke revokes a session. A session that is already revoked keeps its
+// original reason.
+func (s *SessionService) revoke(ctx context.Context, session *models.Session, reason string) error {
+	err := s.Sessions.Revoke(ctx, session.ID, time.Now(), reason)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// rotate gives the session a new refresh token and remembers the hash of the
+// previous one.
+func (s *SessionService) rotate(session *models.Session) (string, error) {
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	if session.RefreshTokenHash != "" {
+		session.PreviousTokenHashes = append(session.PreviousTokenHashes, session.RefreshTokenHash)
+		if len(session.PreviousTokenHashes) > maxPreviousTokenHashes {
+			session.PreviousTokenHashes = session.PreviousTokenHashes[len(session.PreviousTokenHashes)-maxPreviousTokenHashes:]
+		}
+	}
+	refreshToken := session.ID + "." + secret
+	session.RefreshTokenHash = utils.HashToken(refreshToken)
+	return refreshToken, nil
+}
+
+func (s *SessionService) tokenPair(session *models.Session, refreshToken string) (*TokenPair, error) {
+	accessToken, err := utils.GenerateJWT(s.Keys, s.Issuer, session.UserID, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(utils.AccessTokenTTL),
+	}, nil
+}
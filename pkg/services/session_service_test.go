@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// newTestRepositories returns repositories backed by an empty in-memory
+// database.
+func newTestRepositories(t *testing.T) *repositories.Repositories {
+	t.Helper()
+	db, err := repositories.NewMemoryDatabase("")
+	if err != nil {
+		t.Fatalf("NewMemoryDatabase: %v", err)
+	}
+	return repositories.NewMemoryRepositories(db)
+}
+
+func newTestSessionService(t *testing.T) *SessionService {
+	t.Helper()
+	repos := newTestRepositories(t)
+	keys := NewKeyService(repos.SigningKeys, utils.AlgorithmEdDSA, 0)
+	if err := keys.Init(context.Background()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	return NewSessionService(repos.Sessions, keys, "test")
+}
+
+func TestSessionRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// present returns the refresh token to present, given the tokens of
+		// the session so far, oldest first.
+		present   func(tokens []string) string
+		rotations int
+		wantErr   error
+		// wantRevoked is whether the session is revoked afterwards.
+		wantRevoked bool
+	}{
+		{
+			name:    "current token",
+			present: func(tokens []string) string { return tokens[len(tokens)-1] },
+		},
+		{
+			name:      "current token after rotations",
+			present:   func(tokens []string) string { return tokens[len(tokens)-1] },
+			rotations: 3,
+		},
+		{
+			name:        "rotated token",
+			present:     func(tokens []string) string { return tokens[0] },
+			rotations:   1,
+			wantErr:     ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			name:        "older rotated token",
+			present:     func(tokens []string) string { return tokens[1] },
+			rotations:   3,
+			wantErr:     ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			name:    "wrong secret",
+			present: func(tokens []string) string { return tokens[0][:len(tokens[0])-4] + "AAAA" },
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name:    "unknown session",
+			present: func(tokens []string) string { return "unknown.secret" },
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name:    "malformed",
+			present: func(tokens []string) string { return "malformed" },
+			wantErr: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestSessionService(t)
+			pair, err := s.CreateSession(ctx, "user-1", "agent", "192.0.2.1")
+			if err != nil {
+				t.Fatalf("CreateSession: %v", err)
+			}
+			tokens := []string{pair.RefreshToken}
+			for i := 0; i < tt.rotations; i++ {
+				pair, err := s.Refresh(ctx, tokens[len(tokens)-1], "agent", "192.0.2.1")
+				if err != nil {
+					t.Fatalf("Refresh %d: %v", i, err)
+				}
+				tokens = append(tokens, pair.RefreshToken)
+			}
+
+			pair, err = s.Refresh(ctx, tt.present(tokens), "agent", "192.0.2.2")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refresh: got error %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if pair.RefreshToken == tokens[len(tokens)-1] {
+					t.Error("Refresh did not rotate the refresh token")
+				}
+				claims, err := s.VerifyAccessToken(pair.AccessToken)
+				if err != nil {
+					t.Fatalf("VerifyAccessToken: %v", err)
+				}
+				if claims.UserID != "user-1" {
+					t.Errorf("access token user: got %q, want %q", claims.UserID, "user-1")
+				}
+				// The presented token is spent.
+				if _, err := s.Refresh(ctx, tt.present(tokens), "agent", "192.0.2.2"); !errors.Is(err, ErrRefreshTokenReused) {
+					t.Errorf("second Refresh: got error %v, want %v", err, ErrRefreshTokenReused)
+				}
+				return
+			}
+
+			sessionID, _, _ := strings.Cut(tokens[0], ".")
+			revoked := errors.Is(s.ValidateSession(ctx, sessionID), ErrSessionRevoked)
+			if revoked != tt.wantRevoked {
+				t.Errorf("session revoked: got %v, want %v", revoked, tt.wantRevoked)
+			}
+			if tt.wantRevoked {
+				// Reuse ends the session for the legitimate holder too.
+				if _, err := s.Refresh(ctx, tokens[len(tokens)-1], "agent", "192.0.2.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Errorf("Refresh after revocation: got error %v, want %v", err, ErrInvalidRefreshToken)
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentRefresh(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSessionService(t)
+	pair, err := s.CreateSession(ctx, "user-1", "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	const refreshes = 20
+	errs := make([]error, refreshes)
+	var wg sync.WaitGroup
+	for i := 0; i < refreshes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.Refresh(ctx, pair.RefreshToken, "agent", "192.0.2.1")
+		}(i)
+	}
+	wg.Wait()
+
+	// One refresh wins; the others are reuse of the spent token, or find
+	// the session already revoked for it.
+	succeeded, reused := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrRefreshTokenReused):
+			reused++
+		case !errors.Is(err, ErrInvalidRefreshToken):
+			t.Errorf("Refresh: unexpected error %v", err)
+		}
+	}
+	if succeeded != 1 || reused == 0 {
+		t.Errorf("refreshes: got %d succeeded and %d reused, want 1 and at least 1", succeeded, reused)
+	}
+	sessionID, _, _ := strings.Cut(pair.RefreshToken, ".")
+	if err := s.ValidateSession(ctx, sessionID); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("ValidateSession: got error %v, want %v", err, ErrSessionRevoked)
+	}
+}
+
+func TestRefreshDoesNotUndoRevocation(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSessionService(t)
+	pair, err := s.CreateSession(ctx, "user-1", "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	sessionID, _, _ := strings.Cut(pair.RefreshToken, ".")
+
+	// A rotation read before the revocation must not write the session back
+	// without it.
+	stale, err := s.Sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if err := s.RevokeAllForUser(ctx, "user-1", "password changed"); err != nil {
+		t.Fatalf("RevokeAllForUser: %v", err)
+	}
+	if err := s.Sessions.Rotate(ctx, stale, stale.RefreshTokenHash); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Rotate of a revoked session: got error %v, want %v", err, repositories.ErrNotFound)
+	}
+
+	// Refreshes racing a revocation leave the session revoked.
+	pair, err = s.CreateSession(ctx, "user-1", "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	sessionID, _, _ = strings.Cut(pair.RefreshToken, ".")
+	token := pair.RefreshToken
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			next, err := s.Refresh(ctx, token, "agent", "192.0.2.1")
+			if err != nil {
+				return
+			}
+			token = next.RefreshToken
+		}
+	}()
+	if err := s.RevokeAllForUser(ctx, "user-1", "password changed"); err != nil {
+		t.Fatalf("RevokeAllForUser: %v", err)
+	}
+	<-done
+	if err := s.ValidateSession(ctx, sessionID); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("ValidateSession: got error %v, want %v", err, ErrSessionRevoked)
+	}
+}
+
+func TestSessionLogout(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSessionService(t)
+	pair, err := s.CreateSession(ctx, "user-1", "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	claims, err := s.VerifyAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if err := s.ValidateSession(ctx, claims.SessionID); err != nil {
+		t.Fatalf("ValidateSession before logout: %v", err)
+	}
+
+	if err := s.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if err := s.ValidateSession(ctx, claims.SessionID); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("ValidateSession after logout: got error %v, want %v", err, ErrSessionRevoked)
+	}
+	if _, err := s.Refresh(ctx, pair.RefreshToken, "agent", "192.0.2.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh after logout: got error %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSessionService(t)
+	pair, err := s.CreateSession(ctx, "user-1", "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	claims, err := s.VerifyAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	session, err := s.Sessions.FindByID(ctx, claims.SessionID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	session.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := s.Sessions.Rotate(ctx, session, session.RefreshTokenHash); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	if _, err := s.Refresh(ctx, pair.RefreshToken, "agent", "192.0.2.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh: got error %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if err := s.ValidateSession(ctx, claims.SessionID); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("ValidateSession: got error %v, want %v", err, ErrSessionRevoked)
+	}
+}
+
+func TestRotateBoundsPreviousHashes(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSessionService(t)
+	pair, err := s.CreateSession(ctx, "user-1", "agent", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	token := pair.RefreshToken
+	for i := 0; i < maxPreviousTokenHashes+5; i++ {
+		pair, err := s.Refresh(ctx, token, "agent", "192.0.2.1")
+		if err != nil {
+			t.Fatalf("Refresh %d: %v", i, err)
+		}
+		token = pair.RefreshToken
+	}
+	claims, err := s.VerifyAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	session, err := s.Sessions.FindByID(ctx, claims.SessionID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got := len(session.PreviousTokenHashes); got != maxPreviousTokenHashes {
+		t.Errorf("previous token hashes: got %d, want %d", got, maxPreviousTokenHashes)
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// sitemapCacheTTL is how long the sitemap entries are reused, matching the
+// max-age of the sitemap responses.
+const sitemapCacheTTL = 5 * time.Minute
+
+// SitemapService lists the public pages of the site for search engines.
+type SitemapService struct {
+	Blogs    *BlogService
+	Videos   *VideoService
+	Services *ServiceService
+	// Site is the public website the entries point to.
+	Site string
+
+	mu      sync.Mutex
+	entries []utils.SitemapEntry
+	builtAt time.Time
+}
+
+// NewSitemapService creates a new SitemapService for the website at site.
+func NewSitemapService(blogs *BlogService, videos *VideoService, services *ServiceService, site string) *SitemapService {
+	return &SitemapService{Blogs: blogs, Videos: videos, Services: services, Site: strings.TrimSuffix(site, "/")}
+}
+
+// Entries returns the pages of the published blogs at /blogs/<slug>, the
+// videos at /videos/<id> and the services at /services/<id> of the site.
+// Services have no update time and thus no lastmod. The entries are built
+// at most once per sitemapCacheTTL, so every part of a large sitemap is
+// served from the same listing. Callers must not modify them.
+func (s *SitemapService) Entries(ctx context.Context) ([]utils.SitemapEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries != nil && time.Since(s.builtAt) < sitemapCacheTTL {
+		return s.entries, nil
+	}
+	entries, err := s.buildEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.entries, s.builtAt = entries, time.Now()
+	return entries, nil
+}
+
+func (s *SitemapService) buildEntries(ctx context.Context) ([]utils.SitemapEntry, error) {
+	blogs, err := s.Blogs.GetPublishedBlogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]utils.SitemapEntry, 0, len(blogs))
+	for i := range blogs {
+		lastMod := blogs[i].UpdatedAt
+		if published := blogPublished(&blogs[i]); published.After(lastMod) {
+			lastMod = published
+		}
+		entries = append(entries, utils.SitemapEntry{Loc: s.Site + "/blogs/" + url.PathEscape(blogs[i].Slug), LastMod: lastMod})
+	}
+
+	videos, err := allPages(ctx, s.Videos.GetAllPublicVideos, repositories.Sort{Field: "created_at", Desc: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, video := range videos {
+		entries = append(entries, utils.SitemapEntry{Loc: s.Site + "/videos/" + url.PathEscape(video.ID), LastMod: video.UpdatedAt})
+	}
+
+	services, err := allPages(ctx, s.Services.GetAllServices, repositories.Sort{Field: "name"})
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services {
+		entries = append(entries, utils.SitemapEntry{Loc: s.Site + "/services/" + url.PathEscape(service.ID)})
+	}
+	return entries, nil
+}
+
+// allPages collects every record of a paginated list.
+func allPages[T any](ctx context.Context, list func(context.Context, repositories.ListQuery) (*repositories.Page[T], error), sort repositories.Sort) ([]T, error) {
+	query := repositories.ListQuery{Sort: sort, Limit: repositories.MaxPageSize}
+	var items []T
+	for {
+		page, err := list(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			return items, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func TestSitemapEntriesAreCached(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepositories(t)
+	blogs := NewBlogService(repos.Blogs, repos.BlogRevisions, nil)
+	sitemaps := NewSitemapService(blogs, NewVideoService(repos.Videos, repos.PendingUploads, nil), NewServiceService(repos.Services), "https://example.com/")
+	create := func(id string) {
+		t.Helper()
+		blog := &models.Blog{ID: id, Slug: id, Status: models.BlogPublished, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		if err := repos.Blogs.Create(ctx, blog); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	create("first")
+
+	entries, err := sitemaps.Entries(ctx)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Loc != "https://example.com/blogs/first" {
+		t.Fatalf("entries: got %+v", entries)
+	}
+
+	// Within the TTL the listing is reused; after it, it is rebuilt.
+	create("second")
+	if entries, err = sitemaps.Entries(ctx); err != nil || len(entries) != 1 {
+		t.Fatalf("cached entries: got %d, error %v, want 1", len(entries), err)
+	}
+	sitemaps.builtAt = time.Now().Add(-sitemapCacheTTL)
+	if entries, err = sitemaps.Entries(ctx); err != nil || len(entries) != 2 {
+		t.Fatalf("rebuilt entries: got %d, error %v, want 2", len(entries), err)
+	}
+}
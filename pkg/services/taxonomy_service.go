@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+var (
+	// ErrInvalidTaxonomy is returned for empty names, unknown categories and
+	// category trees with cycles.
+	ErrInvalidTaxonomy = errors.New("invalid tag or category")
+	// ErrSlugTaken is returned when another tag or category already uses a
+	// slug.
+	ErrSlugTaken = errors.New("slug is already in use")
+)
+
+// CategoryRequest holds the fields of a category. An empty slug is derived
+// from the name.
+type CategoryRequest struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	ParentID    string `json:"parent_id"`
+}
+
+// TagCount is a tag with the number of published blogs using it.
+type TagCount struct {
+	models.Tag
+	Count int `json:"count"`
+}
+
+// CategoryCount is a category with the number of published blogs in it or
+// in its subcategories.
+type CategoryCount struct {
+	models.Category
+	Count int `json:"count"`
+}
+
+// TaxonomyService manages the tags and categories of blogs. Blogs refer to
+// them by slug, so renames and merges rewrite the blogs.
+type TaxonomyService struct {
+	Tags       repositories.TagRepository
+	Categories repositories.CategoryRepository
+	Blogs      *BlogService
+}
+
+// NewTaxonomyService creates a new TaxonomyService.
+func NewTaxonomyService(tags repositories.TagRepository, categories repositories.CategoryRepository, blogs *BlogService) *TaxonomyService {
+	return &TaxonomyService{Tags: tags, Categories: categories, Blogs: blogs}
+}
+
+// AssignTaxonomy normalizes the tags and categories of a blog before it is
+// saved. Tags are given by name or slug and created when new; categories
+// are given by slug and must exist.
+func (s *TaxonomyService) AssignTaxonomy(ctx context.Context, blog *models.Blog) error {
+	tags := make([]string, 0, len(blog.Tags))
+	for _, name := range blog.Tags {
+		tag, err := s.findOrCreateTag(ctx, name)
+		if err != nil {
+			return err
+		}
+		tags = appendUnique(tags, tag.Slug)
+	}
+	categories := make([]string, 0, len(blog.Categories))
+	for _, slug := range blog.Categories {
+		category, err := s.Categories.FindBySlug(ctx, utils.Slugify(slug))
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return fmt.Errorf("%w: unknown category %q", ErrInvalidTaxonomy, slug)
+			}
+			return fmt.Errorf("failed to get category: %w", err)
+		}
+		categories = appendUnique(categories, category.Slug)
+	}
+	blog.Tags, blog.Categories = tags, categories
+	return nil
+}
+
+func (s *TaxonomyService) findOrCreateTag(ctx context.Context, name string) (*models.Tag, error) {
+	name = strings.TrimSpace(name)
+	slug := utils.Slugify(name)
+	if slug == "" {
+		return nil, fmt.Errorf("%w: tag %q has no letters or digits", ErrInvalidTaxonomy, name)
+	}
+	tag, err := s.Tags.FindBySlug(ctx, slug)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	now := time.Now()
+	tag = &models.Tag{ID: uuid.New().String(), Name: name, Slug: slug, CreatedAt: now, UpdatedAt: now}
+	if err := s.Tags.Create(ctx, tag); err != nil {
+		// Another save created the tag first.
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return s.Tags.FindBySlug(ctx, slug)
+		}
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	return tag, nil
+}
+
+// ListTags returns every tag with the number of published blogs using it.
+func (s *TaxonomyService) ListTags(ctx context.Context) ([]TagCount, error) {
+	tags, err := s.Tags.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	blogs, err := s.Blogs.GetPublishedBlogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for i := range blogs {
+		for _, slug := range blogs[i].Tags {
+			counts[slug]++
+		}
+	}
+	result := make([]TagCount, len(tags))
+	for i, tag := range tags {
+		result[i] = TagCount{Tag: tag, Count: counts[tag.Slug]}
+	}
+	return result, nil
+}
+
+// BlogsByTag returns a tag and a page of the published blogs using it.
+func (s *TaxonomyService) BlogsByTag(ctx context.Context, slug string, query repositories.ListQuery) (*models.Tag, *repositories.Page[models.Blog], error) {
+	tag, err := s.Tags.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tag %s: %w", slug, err)
+	}
+	query.Filters = append(query.Filters, repositories.Filter{Field: "tags", Op: repositories.OpEq, Value: tag.Slug})
+	page, err := s.Blogs.ListPublishedBlogs(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tag, page, nil
+}
+
+// RenameTag changes the name and slug of a tag. Renaming to the slug of
+// another tag fails with ErrSlugTaken; merge the tags instead.
+func (s *TaxonomyService) RenameTag(ctx context.Context, id, name string) (*models.Tag, error) {
+	name = strings.TrimSpace(name)
+	slug := utils.Slugify(name)
+	if slug == "" {
+		return nil, fmt.Errorf("%w: tag %q has no letters or digits", ErrInvalidTaxonomy, name)
+	}
+	tag, err := s.Tags.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	old := *tag
+	tag.Name, tag.Slug, tag.UpdatedAt = name, slug, time.Now()
+	if err := s.Tags.Update(ctx, tag); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrSlugTaken
+		}
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+	if err := s.replaceInBlogs(ctx, "tags", old.Slug, slug); err != nil {
+		// Keep the tag under the slug its blogs still use.
+		if restoreErr := s.Tags.Update(ctx, &old); restoreErr != nil {
+			log.Println("Error restoring tag:", restoreErr)
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+// MergeTags moves every blog from one tag to another and deletes the first
+// tag.
+func (s *TaxonomyService) MergeTags(ctx context.Context, id, intoID string) (*models.Tag, error) {
+	if id == intoID {
+		return nil, fmt.Errorf("%w: cannot merge a tag into itself", ErrInvalidTaxonomy)
+	}
+	tag, err := s.Tags.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	into, err := s.Tags.FindByID(ctx, intoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	if err := s.replaceInBlogs(ctx, "tags", tag.Slug, into.Slug); err != nil {
+		return nil, err
+	}
+	if err := s.Tags.Delete(ctx, tag.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return into, nil
+}
+
+// ListCategories returns every category with the number of published blogs
+// in it or in its subcategories.
+func (s *TaxonomyService) ListCategories(ctx context.Context) ([]CategoryCount, error) {
+	categories, err := s.Categories.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	blogs, err := s.Blogs.GetPublishedBlogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]CategoryCount, len(categories))
+	for i, category := range categories {
+		slugs := subtreeSlugs(categories, category.ID)
+		for j := range blogs {
+			if containsAny(blogs[j].Categories, slugs) {
+				result[i].Count++
+			}
+		}
+		result[i].Category = category
+	}
+	return result, nil
+}
+
+// BlogsByCategory returns a category and a page of the published blogs in
+// it or in its subcategories.
+func (s *TaxonomyService) BlogsByCategory(ctx context.Context, slug string, query repositories.ListQuery) (*models.Category, *repositories.Page[models.Blog], error) {
+	category, err := s.Categories.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get category %s: %w", slug, err)
+	}
+	filter, err := s.CategoryFilter(ctx, category.Slug)
+	if err != nil {
+		return nil, nil, err
+	}
+	query.Filters = append(query.Filters, filter)
+	page, err := s.Blogs.ListPublishedBlogs(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return category, page, nil
+}
+
+// CategoryFilter returns a list filter matching the blogs in a category or
+// in its subcategories. An unknown slug only matches itself.
+func (s *TaxonomyService) CategoryFilter(ctx context.Context, slug string) (repositories.Filter, error) {
+	categories, err := s.Categories.FindAll(ctx)
+	if err != nil {
+		return repositories.Filter{}, fmt.Errorf("failed to get categories: %w", err)
+	}
+	slugs := []interface{}{slug}
+	for i := range categories {
+		if categories[i].Slug == slug {
+			slugs = slugs[:0]
+			for _, subtree := range subtreeSlugs(categories, categories[i].ID) {
+				slugs = append(slugs, subtree)
+			}
+			break
+		}
+	}
+	return repositories.Filter{Field: "categories", Op: repositories.OpIn, Value: slugs}, nil
+}
+
+// CreateCategory creates a category, optionally below a parent.
+func (s *TaxonomyService) CreateCategory(ctx context.Context, request CategoryRequest) (*models.Category, error) {
+	now := time.Now()
+	category := &models.Category{ID: uuid.New().String(), CreatedAt: now, UpdatedAt: now}
+	if err := s.applyCategory(ctx, category, request); err != nil {
+		return nil, err
+	}
+	if err := s.Categories.Create(ctx, category); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrSlugTaken
+		}
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	return category, nil
+}
+
+// UpdateCategory changes a category. A new slug is applied to its blogs.
+func (s *TaxonomyService) UpdateCategory(ctx context.Context, id string, request CategoryRequest) (*models.Category, error) {
+	category, err := s.Categories.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	old := *category
+	if err := s.applyCategory(ctx, category, request); err != nil {
+		return nil, err
+	}
+	category.UpdatedAt = time.Now()
+	if err := s.Categories.Update(ctx, category); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrSlugTaken
+		}
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	if err := s.replaceInBlogs(ctx, "categories", old.Slug, category.Slug); err != nil {
+		// Keep the category under the slug its blogs still use.
+		if restoreErr := s.Categories.Update(ctx, &old); restoreErr != nil {
+			log.Println("Error restoring category:", restoreErr)
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category without subcategories and removes it
+// from its blogs.
+func (s *TaxonomyService) DeleteCategory(ctx context.Context, id string) error {
+	category, err := s.Categories.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get category: %w", err)
+	}
+	categories, err := s.Categories.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get categories: %w", err)
+	}
+	for i := range categories {
+		if categories[i].ParentID == category.ID {
+			return fmt.Errorf("%w: category has subcategories", ErrInvalidTaxonomy)
+		}
+	}
+	// Remove the category from its blogs first, so that a failure leaves
+	// no blog in a deleted category.
+	if err := s.replaceInBlogs(ctx, "categories", category.Slug, ""); err != nil {
+		return err
+	}
+	if err := s.Categories.Delete(ctx, category.ID); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	return nil
+}
+
+// applyCategory validates a request and copies it onto the category. The
+// parent must exist and must not be the category or one of its
+// descendants.
+func (s *TaxonomyService) applyCategory(ctx context.Context, category *models.Category, request CategoryRequest) error {
+	name := strings.TrimSpace(request.Name)
+	slug := utils.Slugify(request.Slug)
+	if slug == "" {
+		slug = utils.Slugify(name)
+	}
+	if name == "" || slug == "" {
+		return fmt.Errorf("%w: category name is required", ErrInvalidTaxonomy)
+	}
+	if request.ParentID != "" {
+		categories, err := s.Categories.FindAll(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get categories: %w", err)
+		}
+		parents := make(map[string]string, len(categories))
+		for i := range categories {
+			parents[categories[i].ID] = categories[i].ParentID
+		}
+		if _, ok := parents[request.ParentID]; !ok {
+			return fmt.Errorf("%w: unknown parent category", ErrInvalidTaxonomy)
+		}
+		for id := request.ParentID; id != ""; id = parents[id] {
+			if id == category.ID {
+				return fmt.Errorf("%w: a category cannot be below itself", ErrInvalidTaxonomy)
+			}
+		}
+	}
+	category.Name = name
+	category.Slug = slug
+	category.Description = strings.TrimSpace(request.Description)
+	category.ParentID = request.ParentID
+	return nil
+}
+
+// replaceInBlogs replaces a slug in the "tags" or "categories" of every blog
+// in one update. An empty replacement removes the slug.
+func (s *TaxonomyService) replaceInBlogs(ctx context.Context, field, from, to string) error {
+	if from == to {
+		return nil
+	}
+	if err := s.Blogs.Blogs.ReplaceTaxonomySlug(ctx, field, from, to); err != nil {
+		return fmt.Errorf("failed to update blogs: %w", err)
+	}
+	return nil
+}
+
+// subtreeSlugs returns the slugs of a category and its descendants.
+func subtreeSlugs(categories []models.Category, id string) []string {
+	var slugs []string
+	for i := range categories {
+		for ancestor := categories[i].ID; ancestor != ""; ancestor = parentOf(categories, ancestor) {
+			if ancestor == id {
+				slugs = append(slugs, categories[i].Slug)
+				break
+			}
+		}
+	}
+	return slugs
+}
+
+func parentOf(categories []models.Category, id string) string {
+	for i := range categories {
+		if categories[i].ID == id {
+			return categories[i].ParentID
+		}
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, candidate := range candidates {
+		if contains(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+)
+
+// newTestTaxonomyService returns a service with the categories news and its
+// subcategory local, and the published blogs one (news, tags go and web),
+// two (local, tags web and api) and three (no tags or categories).
+func newTestTaxonomyService(t *testing.T) *TaxonomyService {
+	t.Helper()
+	ctx := context.Background()
+	repos := newTestRepositories(t)
+	s := NewTaxonomyService(repos.Tags, repos.Categories, NewBlogService(repos.Blogs, repos.BlogRevisions, nil))
+	news, err := s.CreateCategory(ctx, CategoryRequest{Name: "News"})
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	if _, err := s.CreateCategory(ctx, CategoryRequest{Name: "Local", ParentID: news.ID}); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	now := time.Now()
+	blogs := []models.Blog{
+		{ID: "one", Slug: "one", Tags: []string{"Go", "web"}, Categories: []string{"news"}},
+		{ID: "two", Slug: "two", Tags: []string{"web", "API"}, Categories: []string{"local"}},
+		{ID: "three", Slug: "three"},
+	}
+	for i := range blogs {
+		blog := &blogs[i]
+		blog.Status, blog.CreatedAt = models.BlogPublished, now.Add(-time.Duration(i)*time.Minute)
+		if err := s.AssignTaxonomy(ctx, blog); err != nil {
+			t.Fatalf("AssignTaxonomy: %v", err)
+		}
+		if err := s.Blogs.Blogs.Create(ctx, blog); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	return s
+}
+
+// taxonomyOf returns the tags and categories of every blog by ID.
+func taxonomyOf(t *testing.T, s *TaxonomyService) (map[string][]string, map[string][]string) {
+	t.Helper()
+	blogs, err := s.Blogs.GetAllBlogs(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllBlogs: %v", err)
+	}
+	tags, categories := map[string][]string{}, map[string][]string{}
+	for _, blog := range blogs {
+		tags[blog.ID], categories[blog.ID] = blog.Tags, blog.Categories
+	}
+	return tags, categories
+}
+
+func blogIDs(blogs []models.Blog) []string {
+	ids := []string{}
+	for _, blog := range blogs {
+		ids = append(ids, blog.ID)
+	}
+	return ids
+}
+
+func TestTaxonomyRewritesBlogs(t *testing.T) {
+	tests := []struct {
+		name           string
+		change         func(ctx context.Context, s *TaxonomyService) error
+		wantTags       map[string][]string
+		wantCategories map[string][]string
+	}{
+		{
+			name: "rename tag",
+			change: func(ctx context.Context, s *TaxonomyService) error {
+				tag, err := s.Tags.FindBySlug(ctx, "web")
+				if err != nil {
+					return err
+				}
+				_, err = s.RenameTag(ctx, tag.ID, "Frontend")
+				return err
+			},
+			wantTags:       map[string][]string{"one": {"go", "frontend"}, "two": {"frontend", "api"}, "three": nil},
+			wantCategories: map[string][]string{"one": {"news"}, "two": {"local"}, "three": nil},
+		},
+		{
+			name: "merge tags",
+			change: func(ctx context.Context, s *TaxonomyService) error {
+				web, err := s.Tags.FindBySlug(ctx, "web")
+				if err != nil {
+					return err
+				}
+				api, err := s.Tags.FindBySlug(ctx, "api")
+				if err != nil {
+					return err
+				}
+				if _, err := s.MergeTags(ctx, web.ID, api.ID); err != nil {
+					return err
+				}
+				if _, err := s.Tags.FindByID(ctx, web.ID); !errors.Is(err, repositories.ErrNotFound) {
+					t.Errorf("merged tag: got error %v, want %v", err, repositories.ErrNotFound)
+				}
+				return nil
+			},
+			wantTags:       map[string][]string{"one": {"go", "api"}, "two": {"api"}, "three": nil},
+			wantCategories: map[string][]string{"one": {"news"}, "two": {"local"}, "three": nil},
+		},
+		{
+			name: "change category slug",
+			change: func(ctx context.Context, s *TaxonomyService) error {
+				category, err := s.Categories.FindBySlug(ctx, "local")
+				if err != nil {
+					return err
+				}
+				_, err = s.UpdateCategory(ctx, category.ID, CategoryRequest{Name: "Local", Slug: "nearby", ParentID: category.ParentID})
+				return err
+			},
+			wantTags:       map[string][]string{"one": {"go", "web"}, "two": {"web", "api"}, "three": nil},
+			wantCategories: map[string][]string{"one": {"news"}, "two": {"nearby"}, "three": nil},
+		},
+		{
+			name: "delete category",
+			change: func(ctx context.Context, s *TaxonomyService) error {
+				category, err := s.Categories.FindBySlug(ctx, "local")
+				if err != nil {
+					return err
+				}
+				return s.DeleteCategory(ctx, category.ID)
+			},
+			wantTags:       map[string][]string{"one": {"go", "web"}, "two": {"web", "api"}, "three": nil},
+			wantCategories: map[string][]string{"one": {"news"}, "two": nil, "three": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestTaxonomyService(t)
+			if err := tt.change(context.Background(), s); err != nil {
+				t.Fatalf("change: %v", err)
+			}
+			tags, categories := taxonomyOf(t, s)
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags: got %v, want %v", tags, tt.wantTags)
+			}
+			if !reflect.DeepEqual(categories, tt.wantCategories) {
+				t.Errorf("categories: got %v, want %v", categories, tt.wantCategories)
+			}
+		})
+	}
+}
+
+func TestRenameTagToTakenSlug(t *testing.T) {
+	ctx := context.Background()
+	s := newTestTaxonomyService(t)
+	tag, err := s.Tags.FindBySlug(ctx, "web")
+	if err != nil {
+		t.Fatalf("FindBySlug: %v", err)
+	}
+	if _, err := s.RenameTag(ctx, tag.ID, "API"); !errors.Is(err, ErrSlugTaken) {
+		t.Fatalf("RenameTag: got error %v, want %v", err, ErrSlugTaken)
+	}
+	if tags, _ := taxonomyOf(t, s); !reflect.DeepEqual(tags["two"], []string{"web", "api"}) {
+		t.Errorf("tags of two: got %v", tags["two"])
+	}
+}
+
+func TestBlogsByTaxonomyPages(t *testing.T) {
+	tests := []struct {
+		name string
+		list func(ctx context.Context, s *TaxonomyService, query repositories.ListQuery) (*repositories.Page[models.Blog], error)
+		want []string
+	}{
+		{
+			name: "tag",
+			list: func(ctx context.Context, s *TaxonomyService, query repositories.ListQuery) (*repositories.Page[models.Blog], error) {
+				_, page, err := s.BlogsByTag(ctx, "web", query)
+				return page, err
+			},
+			want: []string{"one", "two"},
+		},
+		{
+			name: "category with subcategories",
+			list: func(ctx context.Context, s *TaxonomyService, query repositories.ListQuery) (*repositories.Page[models.Blog], error) {
+				_, page, err := s.BlogsByCategory(ctx, "news", query)
+				return page, err
+			},
+			want: []string{"one", "two"},
+		},
+		{
+			name: "subcategory",
+			list: func(ctx context.Context, s *TaxonomyService, query repositories.ListQuery) (*repositories.Page[models.Blog], error) {
+				_, page, err := s.BlogsByCategory(ctx, "local", query)
+				return page, err
+			},
+			want: []string{"two"},
+		},
+		{
+			name: "category filter of the blog list",
+			list: func(ctx context.Context, s *TaxonomyService, query repositories.ListQuery) (*repositories.Page[models.Blog], error) {
+				filter, err := s.CategoryFilter(ctx, "news")
+				if err != nil {
+					return nil, err
+				}
+				query.Filters = append(query.Filters, filter)
+				return s.Blogs.ListPublishedBlogs(ctx, query)
+			},
+			want: []string{"one", "two"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestTaxonomyService(t)
+			query := repositories.ListQuery{Sort: repositories.Sort{Field: "created_at", Desc: true}, Limit: 1}
+			var got []string
+			for {
+				page, err := tt.list(ctx, s, query)
+				if err != nil {
+					t.Fatalf("list: %v", err)
+				}
+				if len(page.Items) > 1 {
+					t.Fatalf("page: got %d blogs, want at most 1", len(page.Items))
+				}
+				got = append(got, blogIDs(page.Items)...)
+				if page.NextCursor == "" {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("blogs: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+const (
+	// LoginChallengeTTL is how long the second factor can be entered after
+	// the password was accepted.
+	LoginChallengeTTL = 5 * time.Minute
+	// maxChallengeAttempts is how many wrong codes a login challenge takes
+	// before it is discarded.
+	maxChallengeAttempts = 5
+	// recoveryCodeCount is how many recovery codes are issued at a time.
+	recoveryCodeCount = 10
+)
+
+var (
+	// ErrTOTPAlreadyEnabled is returned when enrolling a user who already
+	// uses two-factor authentication.
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTOTPNotEnabled is returned for users without two-factor
+	// authentication.
+	ErrTOTPNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTOTPNotStarted is returned when confirming an enrollment that was
+	// never started.
+	ErrTOTPNotStarted = errors.New("two-factor enrollment was not started")
+	// ErrInvalidCode is returned for wrong or reused TOTP and recovery codes.
+	ErrInvalidCode = errors.New("invalid code")
+	// ErrInvalidChallenge is returned for unknown, expired or exhausted
+	// login challenges.
+	ErrInvalidChallenge = errors.New("invalid login challenge")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPEnrollment is shown to the user to set up their authenticator app.
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// LoginChallengeToken is returned by the first login step for users with
+// two-factor authentication.
+type LoginChallengeToken struct {
+	MFARequired    bool      `json:"mfa_required"`
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// TwoFactorService manages TOTP enrollment and the second login step.
+type TwoFactorService struct {
+	Users      *UserService
+	Challenges repositories.LoginChallengeRepository
+	// Throttle counts wrong login codes like wrong passwords.
+	Throttle *LoginThrottleService
+	// Issuer is the account name shown in authenticator apps.
+	Issuer string
+}
+
+// NewTwoFactorService creates a new TwoFactorService.
+func NewTwoFactorService(users *UserService, challenges repositories.LoginChallengeRepository, throttle *LoginThrottleService, issuer string) *TwoFactorService {
+	return &TwoFactorService{Users: users, Challenges: challenges, Throttle: throttle, Issuer: issuer}
+}
+
+// BeginEnrollment generates a new secret for the user. Two-factor
+// authentication is only enabled once ConfirmEnrollment receives a valid
+// code for it.
+func (s *TwoFactorService) BeginEnrollment(ctx context.Context, userID string) (*TOTPEnrollment, error) {
+	user, err := s.Users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	user.TOTPPendingSecret = secret
+	if err := s.Users.save(ctx, user); err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication if the code matches
+// the pending secret and returns the recovery codes.
+func (s *TwoFactorService) ConfirmEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.Users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPPendingSecret == "" {
+		return nil, ErrTOTPNotStarted
+	}
+	counter, ok := utils.ValidateTOTP(user.TOTPPendingSecret, code, time.Now(), 0)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPEnabled = true
+	user.TOTPSecret = user.TOTPPendingSecret
+	user.TOTPPendingSecret = ""
+	user.TOTPLastCounter = counter
+	user.RecoveryCodeHashes = hashes
+	if err := s.Users.save(ctx, user); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication after checking the password
+// and a current code.
+func (s *TwoFactorService) Disable(ctx context.Context, userID, password, code string) error {
+	user, err := s.Users.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	if !utils.CompareHashAndPassword(password, user.Password) {
+		return ErrIncorrectPassword
+	}
+	if !s.checkCode(user, code) {
+		return ErrInvalidCode
+	}
+	clearTOTP(user)
+	return s.Users.save(ctx, user)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after
+// checking a current code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.Users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTOTPNotEnabled
+	}
+	if !s.checkCode(user, code) {
+		return nil, ErrInvalidCode
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.RecoveryCodeHashes = hashes
+	if err := s.Users.save(ctx, user); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ResetForUser turns off two-factor authentication for a user who lost
+// their device.
+func (s *TwoFactorService) ResetForUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.Users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	clearTOTP(user)
+	return user, s.Users.save(ctx, user)
+}
+
+// CreateChallenge starts the second login step for a user whose password
+// was accepted.
+func (s *TwoFactorService) CreateChallenge(ctx context.Context, userID string) (*LoginChallengeToken, error) {
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+	now := time.Now()
+	challenge := &models.LoginChallenge{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(LoginChallengeTTL),
+	}
+	token := challenge.ID + "." + secret
+	challenge.TokenHash = utils.HashToken(token)
+	if err := s.Challenges.Create(ctx, challenge); err != nil {
+		return nil, fmt.Errorf("failed to create login challenge: %w", err)
+	}
+	return &LoginChallengeToken{MFARequired: true, ChallengeToken: token, ExpiresAt: challenge.ExpiresAt}, nil
+}
+
+// VerifyChallenge completes a login from ip with a TOTP or recovery code and
+// returns the user to start a session for. Recovery codes can only be used
+// once. Wrong codes count as failed logins of the account and the IP, so a
+// *ThrottledError is returned once they are locked, and the failed logins of
+// the account are only cleared when the code is right.
+func (s *TwoFactorService) VerifyChallenge(ctx context.Context, token, code, ip string) (*models.User, error) {
+	id, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidChallenge
+	}
+	challenge, err := s.Challenges.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, fmt.Errorf("failed to get login challenge: %w", err)
+	}
+	if challenge.TokenHash != utils.HashToken(token) || !time.Now().Before(challenge.ExpiresAt) {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := s.Users.GetUser(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+	if !user.Active() || !user.TOTPEnabled {
+		return nil, ErrInvalidChallenge
+	}
+	wait, err := s.Throttle.Check(ctx, user.Email, ip)
+	if err != nil {
+		return nil, err
+	}
+	if wait > 0 {
+		return nil, &ThrottledError{Wait: wait}
+	}
+
+	if !s.checkCode(user, code) {
+		if err := s.Throttle.RecordFailure(ctx, user.Email, ip, user.ID); err != nil {
+			log.Println("Error recording login failure:", err)
+		}
+		challenge.Attempts++
+		if challenge.Attempts >= maxChallengeAttempts {
+			if err := s.Challenges.Delete(ctx, challenge.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete login challenge: %w", err)
+			}
+			return nil, ErrInvalidChallenge
+		}
+		if err := s.Challenges.Update(ctx, challenge); err != nil {
+			return nil, fmt.Errorf("failed to update login challenge: %w", err)
+		}
+		return nil, ErrInvalidCode
+	}
+
+	if err := s.Challenges.Delete(ctx, challenge.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete login challenge: %w", err)
+	}
+	if err := s.Users.save(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.Throttle.RecordSuccess(ctx, user.Email); err != nil {
+		log.Println("Error resetting login throttle:", err)
+	}
+	return user, nil
+}
+
+// checkCode accepts a TOTP code or an unused recovery code. The user is
+// updated in place to consume the code; callers save it on success.
+func (s *TwoFactorService) checkCode(user *models.User, code string) bool {
+	if counter, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastCounter); ok {
+		user.TOTPLastCounter = counter
+		return true
+	}
+	hash := utils.HashToken(normalizeRecoveryCode(code))
+	for i, recoveryHash := range user.RecoveryCodeHashes {
+		if recoveryHash == hash {
+			user.RecoveryCodeHashes = append(user.RecoveryCodeHashes[:i:i], user.RecoveryCodeHashes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func clearTOTP(user *models.User) {
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPPendingSecret = ""
+	user.TOTPLastCounter = 0
+	user.RecoveryCodeHashes = nil
+}
+
+// generateRecoveryCodes returns new recovery codes formatted as
+// "xxxxx-xxxxx" together with the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = utils.HashToken(code)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
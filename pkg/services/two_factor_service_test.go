@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+func TestVerifyChallengeThrottle(t *testing.T) {
+	tests := []struct {
+		name string
+		// wrongCodes is how many wrong codes are entered, each on a new
+		// challenge, before the right one.
+		wrongCodes    int
+		wantThrottled bool
+	}{
+		{name: "no failures", wrongCodes: 0},
+		{name: "below backoff", wrongCodes: accountThrottlePolicy.backoffAfter - 1},
+		{name: "backoff", wrongCodes: accountThrottlePolicy.backoffAfter, wantThrottled: true},
+		{name: "failures across challenges", wrongCodes: maxChallengeAttempts + 1, wantThrottled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := newTestRepositories(t)
+			throttle := NewLoginThrottleService(repos.LoginThrottles, NewAuditService(repos.Audit))
+			s := NewTwoFactorService(NewUserService(repos.Users, nil), repos.LoginChallenges, throttle, "test")
+			secret, err := utils.GenerateTOTPSecret()
+			if err != nil {
+				t.Fatalf("GenerateTOTPSecret: %v", err)
+			}
+			user := &models.User{ID: "user-1", Email: "user@example.com", Role: models.RoleEditor, TOTPEnabled: true, TOTPSecret: secret}
+			if err := repos.Users.Create(ctx, user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			const ip = "192.0.2.1"
+
+			for i := 0; i < tt.wrongCodes; i++ {
+				challenge, err := s.CreateChallenge(ctx, user.ID)
+				if err != nil {
+					t.Fatalf("CreateChallenge: %v", err)
+				}
+				_, err = s.VerifyChallenge(ctx, challenge.ChallengeToken, "not-a-code", ip)
+				var throttled *ThrottledError
+				if !errors.Is(err, ErrInvalidCode) && !errors.As(err, &throttled) {
+					t.Fatalf("VerifyChallenge with wrong code %d: got error %v", i, err)
+				}
+			}
+
+			challenge, err := s.CreateChallenge(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("CreateChallenge: %v", err)
+			}
+			code, err := utils.TOTPCode(secret, utils.TOTPCounter(time.Now()))
+			if err != nil {
+				t.Fatalf("TOTPCode: %v", err)
+			}
+			_, err = s.VerifyChallenge(ctx, challenge.ChallengeToken, code, ip)
+			var throttled *ThrottledError
+			if got := errors.As(err, &throttled); got != tt.wantThrottled {
+				t.Fatalf("VerifyChallenge with right code: got error %v, want throttled %v", err, tt.wantThrottled)
+			}
+			if tt.wantThrottled {
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyChallenge with right code: %v", err)
+			}
+
+			// Success clears the failures of the account but not of the IP.
+			_, err = repos.LoginThrottles.FindByID(ctx, models.LoginThrottleID(models.ThrottleKindAccount, user.Email))
+			if !errors.Is(err, repositories.ErrNotFound) {
+				t.Errorf("account throttle after success: got error %v, want %v", err, repositories.ErrNotFound)
+			}
+			ipThrottle, err := repos.LoginThrottles.FindByID(ctx, models.LoginThrottleID(models.ThrottleKindIP, ip))
+			switch {
+			case tt.wrongCodes == 0 && !errors.Is(err, repositories.ErrNotFound):
+				t.Errorf("IP throttle without failures: got error %v, want %v", err, repositories.ErrNotFound)
+			case tt.wrongCodes > 0 && (err != nil || ipThrottle.Failures != tt.wrongCodes):
+				t.Errorf("IP throttle: got %+v, %v, want %d failures", ipThrottle, err, tt.wrongCodes)
+			}
+		})
+	}
+}
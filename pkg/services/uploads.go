@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// ErrStorageNotConfigured is returned when a file operation is attempted
+// without a configured storage backend.
+var ErrStorageNotConfigured = errors.New("file storage is not configured")
+
+// ErrUnsupportedFileType is returned for uploads whose extension is not one
+// of the accepted image or video types.
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// Accepted upload extensions and the content type files are stored with.
+// Types a browser could run as a page, such as HTML or SVG, are left out
+// because uploads may be served from the API origin.
+var (
+	imageTypes = map[string]string{
+		".jpg":  "image/jpeg",
+		".jpeg": "image/jpeg",
+		".png":  "image/png",
+		".gif":  "image/gif",
+		".webp": "image/webp",
+		".avif": "image/avif",
+	}
+	videoTypes = map[string]string{
+		".mp4":  "video/mp4",
+		".m4v":  "video/x-m4v",
+		".mov":  "video/quicktime",
+		".webm": "video/webm",
+		".ogv":  "video/ogg",
+	}
+)
+
+// uploadType returns the lower case extension of filename and the content
+// type to store it with, or ErrUnsupportedFileType if types does not accept
+// the extension.
+func uploadType(filename string, types map[string]string) (string, string, error) {
+	extension := strings.ToLower(filepath.Ext(filename))
+	contentType, ok := types[extension]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %q", ErrUnsupportedFileType, extension)
+	}
+	return extension, contentType, nil
+}
+
+// storeFile uploads file under prefix with a random name keeping the
+// extension of filename, which must be one of types, and returns its key and
+// public URL.
+func storeFile(ctx context.Context, storage utils.Storage, prefix string, file io.Reader, filename string, types map[string]string) (string, string, error) {
+	if storage == nil {
+		return "", "", ErrStorageNotConfigured
+	}
+	extension, contentType, err := uploadType(filename, types)
+	if err != nil {
+		return "", "", err
+	}
+	key := fmt.Sprintf("%s/%s%s", prefix, uuid.New(), extension)
+	if err := storage.Put(ctx, key, file, contentType); err != nil {
+		return "", "", fmt.Errorf("failed to upload file: %w", err)
+	}
+	return key, storage.PublicURL(key), nil
+}
+
+// removeFile deletes a stored file by the key storeFile issued for it.
+// Records without a key point at files the server did not store, such as
+// images hosted elsewhere or URLs a client supplied, and are left alone:
+// deriving a key from such a URL would let a client delete any object.
+func removeFile(ctx context.Context, storage utils.Storage, key string) error {
+	if storage == nil || key == "" {
+		return nil
+	}
+	if err := storage.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+// MinPasswordLength is the shortest password accepted for an account.
+const MinPasswordLength = 8
+
+var (
+	// ErrInvalidRole is returned when assigning an unknown role.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrInvalidUser is returned for profile updates with missing fields.
+	ErrInvalidUser = errors.New("invalid user")
+	// ErrWeakPassword is returned for passwords shorter than
+	// MinPasswordLength.
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	// ErrIncorrectPassword is returned when the current password does not
+	// match on a password change.
+	ErrIncorrectPassword = errors.New("incorrect password")
+	// ErrLastAdmin is returned when a change would leave no active admin.
+	ErrLastAdmin = errors.New("at least one active admin is required")
+)
+
+// UserUpdate holds the profile fields of a user that can be changed. Nil
+// fields are left as they are.
+type UserUpdate struct {
+	Name  *string `json:"name"`
+	Email *string `json:"email"`
+}
+
+// UserService manages user accounts.
+type UserService struct {
+	Users    repositories.UserRepository
+	Sessions *SessionService
+}
+
+// NewUserService creates a new UserService.
+func NewUserService(users repositories.UserRepository, sessions *SessionService) *UserService {
+	return &UserService{Users: users, Sessions: sessions}
+}
+
+// CreateUser creates a user with the given role. It returns
+// repositories.ErrDuplicateKey if the email is taken.
+func (s *UserService) CreateUser(ctx context.Context, name, email, password string, role models.Role) (*models.User, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	if name == "" || email == "" {
+		return nil, fmt.Errorf("%w: name and email are required", ErrInvalidUser)
+	}
+	if len(password) < MinPasswordLength {
+		return nil, ErrWeakPassword
+	}
+	hashedPassword, err := utils.GenerateHash(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Email:     email,
+		Password:  hashedPassword,
+		Role:      role,
+		IsAdmin:   role == models.RoleAdmin,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.Users.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers returns every user.
+func (s *UserService) ListUsers(ctx context.Context) ([]models.User, error) {
+	users, err := s.Users.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	return users, nil
+}
+
+// GetUser returns a user by ID.
+func (s *UserService) GetUser(ctx context.Context, id string) (*models.User, error) {
+	user, err := s.Users.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// UpdateUser changes the name and email of a user.
+func (s *UserService) UpdateUser(ctx context.Context, id string, update UserUpdate) (*models.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		user.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Email != nil {
+		email, err := parseEmail(*update.Email)
+		if err != nil {
+			return nil, err
+		}
+		user.Email = email
+	}
+	if user.Name == "" || user.Email == "" {
+		return nil, fmt.Errorf("%w: name and email are required", ErrInvalidUser)
+	}
+	return user, s.save(ctx, user)
+}
+
+// UpdateProfile changes the name and email of a user on their own behalf.
+// A new email needs the current password, so that a stolen access token
+// cannot redirect password resets to another address.
+func (s *UserService) UpdateProfile(ctx context.Context, id string, update UserUpdate, currentPassword string) (*models.User, error) {
+	if update.Email != nil {
+		user, err := s.GetUser(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		email, err := parseEmail(*update.Email)
+		if err != nil {
+			return nil, err
+		}
+		if email != user.Email && !utils.CompareHashAndPassword(currentPassword, user.Password) {
+			return nil, ErrIncorrectPassword
+		}
+	}
+	return s.UpdateUser(ctx, id, update)
+}
+
+// parseEmail checks that email is a bare address and normalizes it.
+func parseEmail(email string) (string, error) {
+	email = normalizeEmail(email)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", fmt.Errorf("%w: invalid email address", ErrInvalidUser)
+	}
+	return email, nil
+}
+
+// SetRole changes the role of a user.
+func (s *UserService) SetRole(ctx context.Context, id string, role models.Role) (*models.User, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.RoleAdmin {
+		if err := s.ensureOtherAdmin(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	user.Role = role
+	user.IsAdmin = role == models.RoleAdmin
+	return user, s.save(ctx, user)
+}
+
+// DeactivateUser blocks a user from logging in and ends their sessions.
+func (s *UserService) DeactivateUser(ctx context.Context, id string) (*models.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Active() {
+		return user, nil
+	}
+	if err := s.ensureOtherAdmin(ctx, user); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.DeactivatedAt = &now
+	if err := s.save(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.Sessions.RevokeAllForUser(ctx, user.ID, "user deactivated"); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ActivateUser lets a deactivated user log in again.
+func (s *UserService) ActivateUser(ctx context.Context, id string) (*models.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	user.DeactivatedAt = nil
+	return user, s.save(ctx, user)
+}
+
+// DeleteUser deletes a user and ends their sessions.
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureOtherAdmin(ctx, user); err != nil {
+		return err
+	}
+	if err := s.Users.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return s.Sessions.RevokeAllForUser(ctx, id, "user deleted")
+}
+
+// ChangePassword replaces the password of a user after checking the current
+// one. Every session of the user is revoked.
+func (s *UserService) ChangePassword(ctx context.Context, id, currentPassword, newPassword string) error {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !utils.CompareHashAndPassword(currentPassword, user.Password) {
+		return ErrIncorrectPassword
+	}
+	if err := s.SetPassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+	return s.Sessions.RevokeAllForUser(ctx, user.ID, "password changed")
+}
+
+// SetPassword hashes and stores a new password for a user.
+func (s *UserService) SetPassword(ctx context.Context, user *models.User, password string) error {
+	if len(password) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+	hashedPassword, err := utils.GenerateHash(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = hashedPassword
+	return s.save(ctx, user)
+}
+
+func (s *UserService) save(ctx context.Context, user *models.User) error {
+	user.UpdatedAt = time.Now()
+	if err := s.Users.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// ensureOtherAdmin returns ErrLastAdmin if user is the only active admin.
+func (s *UserService) ensureOtherAdmin(ctx context.Context, user *models.User) error {
+	if user.EffectiveRole() != models.RoleAdmin || !user.Active() {
+		return nil
+	}
+	users, err := s.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		other := &users[i]
+		if other.ID != user.ID && other.Active() && other.EffectiveRole() == models.RoleAdmin {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	stringPtr := func(s string) *string { return &s }
+	tests := []struct {
+		name            string
+		update          UserUpdate
+		currentPassword string
+		wantErr         error
+		wantEmail       string
+	}{
+		{name: "name only", update: UserUpdate{Name: stringPtr("New Name")}, wantEmail: "user@example.com"},
+		{name: "same email without password", update: UserUpdate{Email: stringPtr(" User@Example.com ")}, wantEmail: "user@example.com"},
+		{name: "new email", update: UserUpdate{Email: stringPtr("New@Example.com")}, currentPassword: "password1", wantEmail: "new@example.com"},
+		{name: "new email without password", update: UserUpdate{Email: stringPtr("new@example.com")}, wantErr: ErrIncorrectPassword},
+		{name: "new email with wrong password", update: UserUpdate{Email: stringPtr("new@example.com")}, currentPassword: "password2", wantErr: ErrIncorrectPassword},
+		{name: "invalid email", update: UserUpdate{Email: stringPtr("not-an-email")}, currentPassword: "password1", wantErr: ErrInvalidUser},
+		{name: "email with display name", update: UserUpdate{Email: stringPtr("New <new@example.com>")}, currentPassword: "password1", wantErr: ErrInvalidUser},
+	}
+	ctx := context.Background()
+	s := NewUserService(newTestRepositories(t).Users, nil)
+	// Hashing the password is slow, so the user is created once and reset
+	// before every case.
+	user, err := s.CreateUser(ctx, "User", "user@example.com", "password1", models.RoleEditor)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := *user
+			if err := s.Users.Update(ctx, &original); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			updated, err := s.UpdateProfile(ctx, user.ID, tt.update, tt.currentPassword)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateProfile: got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if updated.Email != tt.wantEmail {
+				t.Errorf("email: got %q, want %q", updated.Email, tt.wantEmail)
+			}
+		})
+	}
+}
@@ -1,178 +1,68 @@
-package controllers
+package services
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
-	"net/http"
-	"os"
-	"path/filepath"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 
-	"your_project/pkg/middlewares"
-	"your_project/pkg/models"
-	"your_project/pkg/utils"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
 )
 
 type VideoService struct {
-	DB    *gorm.DB
-	S3    *utils.S3Client
-	Store string
+	Videos  repositories.VideoRepository
+	Uploads repositories.PendingUploadRepository
+	Storage utils.Storage
 }
 
-func NewVideoService(db *gorm.DB, s3 *utils.S3Client) *VideoService {
+func NewVideoService(videos repositories.VideoRepository, uploads repositories.PendingUploadRepository, storage utils.Storage) *VideoService {
 	return &VideoService{
-		DB:    db,
-		S3:    s3,
-		Store: os.Getenv("AWS_BUCKET"),
+		Videos:  videos,
+		Uploads: uploads,
+		Storage: storage,
 	}
 }
 
-func (s *VideoService) CreateVideo(w http.ResponseWriter, r *http.Request) {
-	user, err := middlewares.GetUserFromContext(r.Context())
+// CreateVideo uploads the video file and stores the video record.
+func (s *VideoService) CreateVideo(ctx context.Context, video *models.Video, file io.Reader, filename string) error {
+	key, uploadURL, err := storeFile(ctx, s.Storage, videoFolder, file, filename, videoTypes)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
-		return
+		return err
 	}
-	if !user.IsAdmin {
-		http.Error(w, "User is not admin", http.StatusUnauthorized)
-		return
-	}
-
-	var video models.Video
-	if err := json.NewDecoder(r.Body).Decode(&video); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	file, header, err := r.FormFile("video")
-	if err != nil {
-		http.Error(w, "Error getting the file", http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
 
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		http.Error(w, "Error reading the file", http.StatusInternalServerError)
-		return
-	}
-	extension := filepath.Ext(header.Filename)
-	uuidValue := uuid.New()
-	key := fmt.Sprintf("%s%s", uuidValue, extension)
-	uploadUrl, err := s.S3.UploadFile(key, fileBytes, s.Store)
-	if err != nil {
-		http.Error(w, "Error while uploading the file", http.StatusInternalServerError)
-		return
-	}
-	video.VideoURL = uploadUrl
+	video.ID = uuid.New().String()
+	video.VideoKey = key
+	video.VideoURL = uploadURL
 	video.CreatedAt = time.Now()
-	video.UpdatedAt = time.Now()
-
-	result := s.DB.Create(&video)
-	if result.Error != nil {
-		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(video)
-}
-
-func (s *VideoService) DeleteVideo(w http.ResponseWriter, r *http.Request) {
-	user, err := middlewares.GetUserFromContext(r.Context())
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
-		return
-	}
-	if !user.IsAdmin {
-		http.Error(w, "User is not admin", http.StatusUnauthorized)
-		return
-	}
-
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "ID is required", http.StatusBadRequest)
-		return
-	}
-
-	var video models.Video
-	if err := s.DB.First(&video, id).Error; err != nil {
-		http.Error(w, "Video not found", http.StatusNotFound)
-		return
-	}
-	key := video.VideoURL[len("https://"+s.Store+".s3.amazonaws.com/"):]
-	if err := s.S3.DeleteFile(key, s.Store); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	video.UpdatedAt = video.CreatedAt
 
-	result := s.DB.Delete(&video)
-	if result.Error != nil {
-		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
-		return
+	if err := s.Videos.Create(ctx, video); err != nil {
+		return fmt.Errorf("failed to create video: %w", err)
 	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Video deleted successfully"})
+	return nil
 }
 
-func (s *VideoService) UpdateVideo(w http.ResponseWriter, r *http.Request) {
-	user, err := middlewares.GetUserFromContext(r.Context())
+// UpdateVideo updates the video details and, when file is not nil, replaces
+// the uploaded video file.
+func (s *VideoService) UpdateVideo(ctx context.Context, id string, updatedVideo *models.Video, file io.Reader, filename string) (*models.Video, error) {
+	video, err := s.Videos.FindByID(ctx, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
-		return
-	}
-	if !user.IsAdmin {
-		http.Error(w, "User is not admin", http.StatusUnauthorized)
-		return
-	}
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "ID is required", http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("failed to find video: %w", err)
 	}
 
-	var video models.Video
-	if err := s.DB.First(&video, id).Error; err != nil {
-		http.Error(w, "Video not found", http.StatusNotFound)
-		return
-	}
-
-	var updatedVideo models.Video
-	if err := json.NewDecoder(r.Body).Decode(&updatedVideo); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	file, header, err := r.FormFile("video")
-	if err != nil && file != nil {
-		http.Error(w, "Error getting the file", http.StatusBadRequest)
-		return
-	}
+	oldKey, oldURL := video.VideoKey, video.VideoURL
 	if file != nil {
-		defer file.Close()
-		fileBytes, err := io.ReadAll(file)
-		if err != nil {
-			http.Error(w, "Error reading the file", http.StatusInternalServerError)
-			return
-		}
-		key := video.VideoURL[len("https://"+s.Store+".s3.amazonaws.com/"):]
-		if err := s.S3.DeleteFile(key, s.Store); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		extension := filepath.Ext(header.Filename)
-		uuidValue := uuid.New()
-		key = fmt.Sprintf("%s%s", uuidValue, extension)
-		uploadUrl, err := s.S3.UploadFile(key, fileBytes, s.Store)
+		key, uploadURL, err := storeFile(ctx, s.Storage, videoFolder, file, filename, videoTypes)
 		if err != nil {
-			http.Error(w, "Error while uploading the file", http.StatusInternalServerError)
-			return
+			return nil, err
 		}
-		video.VideoURL = uploadUrl
+		video.VideoKey = key
+		video.VideoURL = uploadURL
 	}
 
 	video.Category = updatedVideo.Category
@@ -180,41 +70,45 @@ func (s *VideoService) UpdateVideo(w http.ResponseWriter, r *http.Request) {
 	video.Content = updatedVideo.Content
 	video.UpdatedAt = time.Now()
 
-	result := s.DB.Save(&video)
-	if result.Error != nil {
-		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
-		return
+	if err := s.Videos.Update(ctx, video); err != nil {
+		return nil, fmt.Errorf("failed to update video: %w", err)
 	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(video)
+	if video.VideoURL != oldURL {
+		if err := removeFile(ctx, s.Storage, oldKey); err != nil {
+			log.Printf("failed to remove replaced video file %s: %v", oldURL, err)
+		}
+	}
+	return video, nil
 }
 
-func (s *VideoService) GetVideo(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "ID is required", http.StatusBadRequest)
-		return
+// DeleteVideo deletes the video record and its uploaded file.
+func (s *VideoService) DeleteVideo(ctx context.Context, id string) error {
+	video, err := s.Videos.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find video: %w", err)
 	}
-
-	var video models.Video
-	if err := s.DB.First(&video, id).Error; err != nil {
-		http.Error(w, "Video not found", http.StatusNotFound)
-		return
+	if err := removeFile(ctx, s.Storage, video.VideoKey); err != nil {
+		return err
 	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(video)
+	if err := s.Videos.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete video: %w", err)
+	}
+	return nil
 }
 
-func (s *VideoService) GetPublicVideos(w http.ResponseWriter, r *http.Request) {
-	var videos []models.Video
-	result := s.DB.Find(&videos)
-	if result.Error != nil {
-		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
-		return
+func (s *VideoService) GetVideo(ctx context.Context, id string) (*models.Video, error) {
+	video, err := s.Videos.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
+	return video, nil
+}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(videos)
-}
\ No newline at end of file
+// GetAllPublicVideos returns a page of videos.
+func (s *VideoService) GetAllPublicVideos(ctx context.Context, query repositories.ListQuery) (*repositories.Page[models.Video], error) {
+	page, err := s.Videos.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get videos: %w", err)
+	}
+	return page, nil
+}
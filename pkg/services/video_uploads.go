@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/models"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/repositories"
+	"github.com/GoogleCloudPlatform/golang-samples/run/helloworld/pkg/utils"
+)
+
+const (
+	// directUploadExpiry is how long presigned upload URLs stay valid.
+	directUploadExpiry = 2 * time.Hour
+	// pendingUploadGrace is how long an issued upload can still be completed
+	// after its URLs expired.
+	pendingUploadGrace = time.Hour
+	// maxDirectUploadSize is the largest video a client can upload.
+	maxDirectUploadSize = 10 << 30
+	// maxSingleUploadSize is the S3 limit on a single PUT; larger files need
+	// a multipart upload.
+	maxSingleUploadSize = 5 << 30
+	// directUploadPartSize is the default part size of multipart uploads.
+	directUploadPartSize = 64 << 20
+	// maxUploadParts is the S3 limit on the number of parts of an upload.
+	maxUploadParts = 10000
+	// videoFolder is the storage prefix of every uploaded video.
+	videoFolder = "videos"
+)
+
+var (
+	// ErrDirectUploadNotSupported is returned when the storage backend cannot
+	// presign uploads.
+	ErrDirectUploadNotSupported = errors.New("direct uploads are not supported by the configured storage")
+	// ErrInvalidUpload is returned for upload requests that do not describe
+	// an upload issued by StartDirectUpload.
+	ErrInvalidUpload = errors.New("invalid upload")
+	// ErrUploadNotFound is returned when a completed upload has no object in
+	// the bucket.
+	ErrUploadNotFound = errors.New("uploaded file not found")
+)
+
+// DirectUploadRequest describes a video file the client wants to upload
+// straight to the bucket. The content type follows from the file extension.
+type DirectUploadRequest struct {
+	Filename string `json:"filename" binding:"required"`
+	// Size in bytes; the upload must match it exactly.
+	Size      int64 `json:"size" binding:"required"`
+	Multipart bool  `json:"multipart"`
+}
+
+// DirectUpload tells the client where to send the file. Single uploads use
+// Upload; multipart uploads PUT each chunk of PartSize bytes to the matching
+// entry of Parts and keep the returned ETag headers for completion.
+type DirectUpload struct {
+	Key      string                  `json:"key"`
+	Upload   *utils.PresignedRequest `json:"upload,omitempty"`
+	UploadID string                  `json:"upload_id,omitempty"`
+	PartSize int64                   `json:"part_size,omitempty"`
+	Parts    []DirectUploadPart      `json:"parts,omitempty"`
+}
+
+// DirectUploadPart is the presigned request for one part of a multipart upload.
+type DirectUploadPart struct {
+	PartNumber int32 `json:"part_number"`
+	*utils.PresignedRequest
+}
+
+// CompleteDirectUploadRequest finishes a direct upload and carries the
+// details of the video to create.
+type CompleteDirectUploadRequest struct {
+	Key      string                `json:"key" binding:"required"`
+	UploadID string                `json:"upload_id"`
+	Parts    []utils.CompletedPart `json:"parts"`
+	Category string                `json:"category"`
+	Title    string                `json:"title"`
+	Content  string                `json:"content"`
+}
+
+// AbortDirectUploadRequest cancels a multipart upload.
+type AbortDirectUploadRequest struct {
+	Key      string `json:"key" binding:"required"`
+	UploadID string `json:"upload_id" binding:"required"`
+}
+
+func (s *VideoService) presignedUploader() (utils.PresignedUploader, error) {
+	if s.Storage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+	uploader, ok := s.Storage.(utils.PresignedUploader)
+	if !ok {
+		return nil, ErrDirectUploadNotSupported
+	}
+	return uploader, nil
+}
+
+// StartDirectUpload issues presigned URLs the client uses to upload a video
+// file without sending it through the API. The upload is recorded for the
+// current user so that only they can complete or abort it, once, and every
+// presigned PUT is limited to the announced size.
+func (s *VideoService) StartDirectUpload(ctx context.Context, req DirectUploadRequest) (*DirectUpload, error) {
+	uploader, err := s.presignedUploader()
+	if err != nil {
+		return nil, err
+	}
+	extension, contentType, err := uploadType(req.Filename, videoTypes)
+	if err != nil {
+		return nil, err
+	}
+	if req.Size <= 0 || req.Size > maxDirectUploadSize {
+		return nil, fmt.Errorf("%w: size must be between 1 and %d bytes", ErrInvalidUpload, int64(maxDirectUploadSize))
+	}
+	if !req.Multipart && req.Size > maxSingleUploadSize {
+		return nil, fmt.Errorf("%w: files over %d bytes need a multipart upload", ErrInvalidUpload, int64(maxSingleUploadSize))
+	}
+
+	upload := &DirectUpload{
+		Key: fmt.Sprintf("%s/%s%s", videoFolder, uuid.New(), extension),
+	}
+	if !req.Multipart {
+		upload.Upload, err = uploader.PresignPut(ctx, upload.Key, contentType, req.Size, directUploadExpiry)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.savePendingUpload(ctx, upload, req.Size); err != nil {
+			return nil, err
+		}
+		return upload, nil
+	}
+
+	upload.PartSize = directUploadPartSize
+	if minPartSize := (req.Size + maxUploadParts - 1) / maxUploadParts; minPartSize > upload.PartSize {
+		upload.PartSize = minPartSize
+	}
+	partCount := (req.Size + upload.PartSize - 1) / upload.PartSize
+
+	upload.UploadID, err = uploader.CreateMultipartUpload(ctx, upload.Key, contentType)
+	if err != nil {
+		return nil, err
+	}
+	abort := func(err error) error {
+		if abortErr := uploader.AbortMultipartUpload(ctx, upload.Key, upload.UploadID); abortErr != nil {
+			return errors.Join(err, abortErr)
+		}
+		return err
+	}
+	for partNumber := int32(1); partNumber <= int32(partCount); partNumber++ {
+		// Every part is full size except the last, which holds the rest.
+		partSize := upload.PartSize
+		if int64(partNumber) == partCount {
+			partSize = req.Size - (partCount-1)*upload.PartSize
+		}
+		request, err := uploader.PresignUploadPart(ctx, upload.Key, upload.UploadID, partNumber, partSize, directUploadExpiry)
+		if err != nil {
+			return nil, abort(err)
+		}
+		upload.Parts = append(upload.Parts, DirectUploadPart{PartNumber: partNumber, PresignedRequest: request})
+	}
+	if err := s.savePendingUpload(ctx, upload, req.Size); err != nil {
+		return nil, abort(err)
+	}
+	return upload, nil
+}
+
+// savePendingUpload records an issued upload. It outlives the presigned URLs
+// by a grace period so a client can still complete an upload it finished
+// just before they expired.
+func (s *VideoService) savePendingUpload(ctx context.Context, upload *DirectUpload, size int64) error {
+	now := time.Now()
+	pending := &models.PendingUpload{
+		ID:         upload.Key,
+		UploadID:   upload.UploadID,
+		UploaderID: AuditActorFrom(ctx).UserID,
+		Size:       size,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(directUploadExpiry + pendingUploadGrace),
+	}
+	if err := s.Uploads.Create(ctx, pending); err != nil {
+		return fmt.Errorf("failed to record upload: %w", err)
+	}
+	return nil
+}
+
+// consumePendingUpload removes the record of an issued upload and returns it.
+// Only the user the upload was issued to can consume it, and only once.
+func (s *VideoService) consumePendingUpload(ctx context.Context, key, uploadID string) (*models.PendingUpload, error) {
+	pending, err := s.Uploads.FindByID(ctx, key)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidUpload, key)
+		}
+		return nil, fmt.Errorf("failed to find upload: %w", err)
+	}
+	if pending.UploaderID != AuditActorFrom(ctx).UserID || pending.UploadID != uploadID {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidUpload, key)
+	}
+	if time.Now().After(pending.ExpiresAt) {
+		return nil, fmt.Errorf("%w: upload %q has expired", ErrInvalidUpload, key)
+	}
+	// Deleting is what claims the upload: of two concurrent requests only
+	// one gets past it.
+	if err := s.Uploads.Delete(ctx, key); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidUpload, key)
+		}
+		return nil, fmt.Errorf("failed to consume upload: %w", err)
+	}
+	return pending, nil
+}
+
+// restorePendingUpload puts back an upload that was consumed by a request
+// that failed, so the client can retry.
+func (s *VideoService) restorePendingUpload(ctx context.Context, pending *models.PendingUpload) {
+	if err := s.Uploads.Create(ctx, pending); err != nil {
+		log.Println("Error restoring pending upload:", err)
+	}
+}
+
+// CompleteDirectUpload finishes a direct upload issued to the current user,
+// checks the file in the bucket has the announced size and creates the video
+// record.
+func (s *VideoService) CompleteDirectUpload(ctx context.Context, req CompleteDirectUploadRequest) (*models.Video, error) {
+	uploader, err := s.presignedUploader()
+	if err != nil {
+		return nil, err
+	}
+	if req.UploadID != "" && len(req.Parts) == 0 {
+		return nil, fmt.Errorf("%w: parts are required to complete a multipart upload", ErrInvalidUpload)
+	}
+	pending, err := s.consumePendingUpload(ctx, req.Key, req.UploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.UploadID != "" {
+		if err := uploader.CompleteMultipartUpload(ctx, req.Key, req.UploadID, req.Parts); err != nil {
+			s.restorePendingUpload(ctx, pending)
+			return nil, err
+		}
+	}
+
+	object, err := s.Storage.Stat(ctx, req.Key)
+	if err != nil {
+		s.restorePendingUpload(ctx, pending)
+		if errors.Is(err, utils.ErrObjectNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	if object.Size != pending.Size {
+		if err := s.Storage.Delete(ctx, req.Key); err != nil {
+			log.Println("Error deleting upload of unexpected size:", err)
+		}
+		return nil, fmt.Errorf("%w: uploaded %d bytes, expected %d", ErrInvalidUpload, object.Size, pending.Size)
+	}
+
+	video := &models.Video{
+		ID:       uuid.New().String(),
+		Category: req.Category,
+		Title:    req.Title,
+		Content:  req.Content,
+		VideoKey: req.Key,
+		VideoURL: s.Storage.PublicURL(req.Key),
+	}
+	video.CreatedAt = time.Now()
+	video.UpdatedAt = video.CreatedAt
+	if err := s.Videos.Create(ctx, video); err != nil {
+		s.restorePendingUpload(ctx, pending)
+		return nil, fmt.Errorf("failed to create video: %w", err)
+	}
+	return video, nil
+}
+
+// AbortDirectUpload cancels a multipart upload issued to the current user
+// and discards its parts.
+func (s *VideoService) AbortDirectUpload(ctx context.Context, req AbortDirectUploadRequest) error {
+	uploader, err := s.presignedUploader()
+	if err != nil {
+		return err
+	}
+	pending, err := s.consumePendingUpload(ctx, req.Key, req.UploadID)
+	if err != nil {
+		return err
+	}
+	if err := uploader.AbortMultipartUpload(ctx, req.Key, req.UploadID); err != nil {
+		s.restorePendingUpload(ctx, pending)
+		return err
+	}
+	return nil
+}
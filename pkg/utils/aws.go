@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -16,70 +19,122 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
-// S3Client represents the AWS S3 client.
+// S3Client represents the AWS S3 client. It also works with S3 compatible
+// services such as MinIO, Cloudflare R2 and Ceph when Endpoint is set.
 type S3Client struct {
 	Client *s3.Client
 	Bucket string
+	Region string
+	// Endpoint is the base URL of an S3 compatible service. Empty means AWS.
+	Endpoint string
+	// UsePathStyle addresses objects as <endpoint>/<bucket>/<key> instead of
+	// <bucket>.<endpoint>/<key>.
+	UsePathStyle bool
+	// PublicBaseURL, when set, is the URL objects are publicly served from,
+	// for example a CDN or a reverse proxy in front of the bucket.
+	PublicBaseURL string
+	// Upload tunes how UploadFile splits large bodies into multipart uploads.
+	Upload UploadOptions
 }
 
-// NewS3Client configures and returns a new S3 client.
+// S3Config holds the settings used to build an S3Client.
+type S3Config struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Region          string
+	Bucket          string
+	Endpoint        string
+	UsePathStyle    bool
+	PublicBaseURL   string
+	Upload          UploadOptions
+}
+
+// S3ConfigFromEnv reads the S3 settings from the environment.
+func S3ConfigFromEnv() S3Config {
+	pathStyle, _ := strconv.ParseBool(os.Getenv("S3_FORCE_PATH_STYLE"))
+	partSizeMB, _ := strconv.ParseInt(os.Getenv("S3_UPLOAD_PART_SIZE_MB"), 10, 64)
+	concurrency, _ := strconv.Atoi(os.Getenv("S3_UPLOAD_CONCURRENCY"))
+	retries, _ := strconv.Atoi(os.Getenv("S3_UPLOAD_PART_RETRIES"))
+	return S3Config{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		Region:          os.Getenv("AWS_REGION"),
+		Bucket:          os.Getenv("AWS_BUCKET"),
+		Endpoint:        os.Getenv("S3_ENDPOINT"),
+		UsePathStyle:    pathStyle,
+		PublicBaseURL:   os.Getenv("S3_PUBLIC_URL"),
+		Upload: UploadOptions{
+			PartSize:    partSizeMB << 20,
+			Concurrency: concurrency,
+			PartRetries: retries,
+		},
+	}
+}
+
+// NewS3Client configures and returns a new S3 client from the environment.
 func NewS3Client() (*S3Client, error) {
-	accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID")
-	secretAccessKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
-	region := os.Getenv("AWS_REGION")
-	bucket := os.Getenv("AWS_BUCKET")
+	return NewS3ClientFromConfig(S3ConfigFromEnv())
+}
 
-	if accessKeyID == "" || secretAccessKey == "" || region == "" || bucket == "" {
+// NewS3ClientFromConfig configures and returns a new S3 client.
+func NewS3ClientFromConfig(cfg S3Config) (*S3Client, error) {
+	if cfg.Region == "" && cfg.Endpoint != "" {
+		// S3 compatible services generally ignore the region, but requests
+		// still have to be signed with one.
+		cfg.Region = "us-east-1"
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" || cfg.Region == "" || cfg.Bucket == "" {
 		return nil, errors.New("missing AWS credentials or bucket information in environment variables")
 	}
 
-	creds := credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region), config.WithCredentialsProvider(creds))
+	creds := credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(cfg.Region), config.WithCredentialsProvider(creds))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
 	}
 
-	client := s3.NewFromConfig(cfg)
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
 	return &S3Client{
-		Client: client,
-		Bucket: bucket,
+		Client:        client,
+		Bucket:        cfg.Bucket,
+		Region:        cfg.Region,
+		Endpoint:      endpoint,
+		UsePathStyle:  cfg.UsePathStyle,
+		PublicBaseURL: strings.TrimSuffix(cfg.PublicBaseURL, "/"),
+		Upload:        cfg.Upload,
 	}, nil
 }
 
-// UploadFile uploads a file to S3.
-func (s *S3Client) UploadFile(ctx context.Context, key string, body io.Reader) (*s3.PutObjectOutput, error) {
+// ensureBucket creates the bucket if it does not exist yet.
+func (s *S3Client) ensureBucket(ctx context.Context) error {
 	_, err := s.Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.Bucket)})
-    if err != nil {
-        var notFoundError *types.NotFound
-        if errors.As(err, &notFoundError) {
-            log.Printf("Bucket %s does not exist or is not accessible. Creating...", s.Bucket)
-            _, err := s.Client.CreateBucket(ctx, &s3.CreateBucketInput{
-                Bucket: aws.String(s.Bucket),
-                CreateBucketConfiguration: &types.CreateBucketConfiguration{
-                    LocationConstraint: types.BucketLocationConstraint(os.Getenv("AWS_REGION")),
-                },
-            })
-            if err != nil {
-                return nil, fmt.Errorf("error creating bucket: %w", err)
-            }
-            log.Printf("Bucket %s created successfully.", s.Bucket)
-        } else {
-            return nil, fmt.Errorf("error checking bucket: %w", err)
-        }
-    }
-
-	input := &s3.PutObjectInput{
-		Bucket: aws.String(s.Bucket),
-		Key:    aws.String(key),
-		Body:   body,
-	}
-
-	output, err := s.Client.PutObject(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload file: %w", err)
+		var notFoundError *types.NotFound
+		if errors.As(err, &notFoundError) {
+			log.Printf("Bucket %s does not exist or is not accessible. Creating...", s.Bucket)
+			input := &s3.CreateBucketInput{Bucket: aws.String(s.Bucket)}
+			// us-east-1 is the default location and must not be sent as a constraint.
+			if s.Region != "us-east-1" {
+				input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+					LocationConstraint: types.BucketLocationConstraint(s.Region),
+				}
+			}
+			_, err := s.Client.CreateBucket(ctx, input)
+			if err != nil {
+				return fmt.Errorf("error creating bucket: %w", err)
+			}
+			log.Printf("Bucket %s created successfully.", s.Bucket)
+		} else {
+			return fmt.Errorf("error checking bucket: %w", err)
+		}
 	}
-
-	return output, nil
+	return nil
 }
 
 // DeleteFile deletes a file from S3.
@@ -112,6 +167,98 @@ func (s *S3Client) ListFiles(ctx context.Context, prefix string) ([]types.Object
 	return output.Contents, nil
 }
 
+// Put uploads an object, streaming large bodies as a multipart upload. It
+// implements Storage.
+func (s *S3Client) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	_, err := s.UploadFile(ctx, key, body, contentType)
+	return err
+}
+
+// Delete deletes an object. It implements Storage.
+func (s *S3Client) Delete(ctx context.Context, key string) error {
+	_, err := s.DeleteFile(ctx, key)
+	return err
+}
+
+// List lists every object whose key starts with prefix. It implements Storage.
+func (s *S3Client) List(ctx context.Context, prefix string) ([]StorageObject, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	objects := []StorageObject{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		for _, object := range page.Contents {
+			objects = append(objects, StorageObject{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// Open returns the contents of an object. It implements Storage.
+func (s *S3Client) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return output.Body, nil
+}
+
+// Stat returns the size and modification time of an object. It implements Storage.
+func (s *S3Client) Stat(ctx context.Context, key string) (*StorageObject, error) {
+	output, err := s.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return &StorageObject{
+		Key:          key,
+		Size:         aws.ToInt64(output.ContentLength),
+		LastModified: aws.ToTime(output.LastModified),
+	}, nil
+}
+
+// PublicURL returns the public URL of an object. It implements Storage.
+func (s *S3Client) PublicURL(key string) string {
+	if s.PublicBaseURL != "" {
+		return s.PublicBaseURL + "/" + key
+	}
+	if s.Endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", s.Bucket, key)
+	}
+	if s.UsePathStyle {
+		return fmt.Sprintf("%s/%s/%s", s.Endpoint, s.Bucket, key)
+	}
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return fmt.Sprintf("%s/%s/%s", s.Endpoint, s.Bucket, key)
+	}
+	endpoint.Host = s.Bucket + "." + endpoint.Host
+	return strings.TrimSuffix(endpoint.String(), "/") + "/" + key
+}
+
 // Example of how to use the UploadFile function.
 func main() {
 	client, err := NewS3Client()
@@ -128,10 +275,10 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	output, err := client.UploadFile(ctx, "test.txt", file) // Replace with desired key
+	output, err := client.UploadFile(ctx, "test.txt", file, "text/plain") // Replace with desired key
 	if err != nil {
 		log.Fatalf("Error uploading file: %v", err)
 	}
 
 	log.Printf("File uploaded successfully: %v", output)
-}
\ No newline at end of file
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// PresignPut returns a presigned PUT request uploading a whole object of
+// size bytes. It implements PresignedUploader.
+func (s *S3Client) PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedRequest, error) {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.Bucket),
+		Key:           aws.String(key),
+		ContentLength: aws.Int64(size),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	request, err := s3.NewPresignClient(s.Client).PresignPutObject(ctx, input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign upload: %w", err)
+	}
+	return &PresignedRequest{
+		Method:    request.Method,
+		URL:       request.URL,
+		Headers:   request.SignedHeader,
+		ExpiresAt: time.Now().Add(expires),
+	}, nil
+}
+
+// CreateMultipartUpload starts a multipart upload and returns its ID. It
+// implements PresignedUploader.
+func (s *S3Client) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	output, err := s.Client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+	return aws.ToString(output.UploadId), nil
+}
+
+// PresignUploadPart returns a presigned PUT request uploading one part of
+// size bytes of a multipart upload. It implements PresignedUploader.
+func (s *S3Client) PresignUploadPart(ctx context.Context, key, uploadID string, partNumber int32, size int64, expires time.Duration) (*PresignedRequest, error) {
+	request, err := s3.NewPresignClient(s.Client).PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(s.Bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign part %d: %w", partNumber, err)
+	}
+	return &PresignedRequest{
+		Method:    request.Method,
+		URL:       request.URL,
+		Headers:   request.SignedHeader,
+		ExpiresAt: time.Now().Add(expires),
+	}, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the final
+// object. It implements PresignedUploader.
+func (s *S3Client) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, types.CompletedPart{
+			PartNumber: aws.Int32(part.PartNumber),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return aws.ToInt32(completed[i].PartNumber) < aws.ToInt32(completed[j].PartNumber)
+	})
+
+	_, err := s.Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.Bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards a multipart upload and its uploaded parts.
+// It implements PresignedUploader.
+func (s *S3Client) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := s.Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// MinUploadPartSize is the smallest part size S3 accepts, except for the
+	// last part of an upload.
+	MinUploadPartSize = 5 << 20
+	// MaxUploadParts is the largest number of parts of a multipart upload.
+	MaxUploadParts = 10000
+
+	defaultUploadPartSize    = 16 << 20
+	defaultUploadConcurrency = 4
+	defaultUploadPartRetries = 3
+	uploadRetryBackoff       = 500 * time.Millisecond
+	abortUploadTimeout       = 30 * time.Second
+)
+
+// UploadOptions tunes UploadFile. Zero values select the defaults.
+type UploadOptions struct {
+	// PartSize is the size of each part in bytes. Bodies smaller than one
+	// part are sent with a single PutObject.
+	PartSize int64
+	// Concurrency is the number of parts uploaded in parallel. At most
+	// Concurrency parts are held in memory at once.
+	Concurrency int
+	// PartRetries is how many times a failed part is retried. A negative
+	// value disables retries.
+	PartRetries int
+}
+
+func (o UploadOptions) withDefaults() UploadOptions {
+	if o.PartSize <= 0 {
+		o.PartSize = defaultUploadPartSize
+	}
+	if o.PartSize < MinUploadPartSize {
+		o.PartSize = MinUploadPartSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultUploadConcurrency
+	}
+	if o.PartRetries < 0 {
+		o.PartRetries = 0
+	} else if o.PartRetries == 0 {
+		o.PartRetries = defaultUploadPartRetries
+	}
+	return o
+}
+
+// UploadOutput describes an uploaded object.
+type UploadOutput struct {
+	Key   string
+	Size  int64
+	Parts int
+}
+
+type uploadPart struct {
+	number int32
+	data   []byte
+}
+
+// UploadFile uploads a file to S3. The body is read in parts of
+// Upload.PartSize bytes: a body that fits in one part is sent with a single
+// PutObject, anything larger is streamed as a multipart upload so the whole
+// body is never held in memory. If any part fails after its retries, the
+// multipart upload is aborted so no orphaned parts are left in the bucket.
+func (s *S3Client) UploadFile(ctx context.Context, key string, body io.Reader, contentType string) (*UploadOutput, error) {
+	if err := s.ensureBucket(ctx); err != nil {
+		return nil, err
+	}
+
+	options := s.Upload.withDefaults()
+	first := make([]byte, options.PartSize)
+	n, err := io.ReadFull(body, first)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return s.putObject(ctx, key, first[:n], contentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return s.multipartUpload(ctx, key, first, body, contentType, options)
+}
+
+func (s *S3Client) putObject(ctx context.Context, key string, data []byte, contentType string) (*UploadOutput, error) {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.Bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	if _, err := s.Client.PutObject(ctx, input); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+	return &UploadOutput{Key: key, Size: int64(len(data)), Parts: 1}, nil
+}
+
+// multipartUpload uploads first and the rest of body as a multipart upload.
+func (s *S3Client) multipartUpload(ctx context.Context, key string, first []byte, body io.Reader, contentType string, options UploadOptions) (*UploadOutput, error) {
+	uploadID, err := s.CreateMultipartUpload(ctx, key, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		completed []CompletedPart
+		uploadErr error
+		wg        sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if uploadErr == nil {
+			uploadErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	// buffers bounds the number of parts in memory. A nil buffer is
+	// allocated on first use.
+	buffers := make(chan []byte, options.Concurrency)
+	for i := 0; i < options.Concurrency; i++ {
+		buffers <- nil
+	}
+	parts := make(chan uploadPart)
+	for i := 0; i < options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range parts {
+				etag, err := s.uploadPartWithRetries(ctx, key, uploadID, part, options.PartRetries)
+				buffers <- part.data[:cap(part.data)]
+				if err != nil {
+					fail(err)
+					continue
+				}
+				mu.Lock()
+				completed = append(completed, CompletedPart{PartNumber: part.number, ETag: etag})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	<-buffers
+	size := int64(len(first))
+	next := uploadPart{number: 1, data: first}
+	for {
+		select {
+		case parts <- next:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		var buf []byte
+		select {
+		case buf = <-buffers:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if buf == nil {
+			buf = make([]byte, options.PartSize)
+		}
+
+		n, err := io.ReadFull(body, buf)
+		if n == 0 && errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			fail(fmt.Errorf("failed to read file: %w", err))
+			break
+		}
+		if next.number == MaxUploadParts {
+			fail(fmt.Errorf("file exceeds %d parts of %d bytes", MaxUploadParts, options.PartSize))
+			break
+		}
+		size += int64(n)
+		next = uploadPart{number: next.number + 1, data: buf[:n]}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			select {
+			case parts <- next:
+			case <-ctx.Done():
+			}
+			break
+		}
+	}
+	close(parts)
+	wg.Wait()
+
+	if uploadErr == nil && ctx.Err() != nil {
+		uploadErr = ctx.Err()
+	}
+	if uploadErr != nil {
+		// The request context may already be cancelled; the abort must still
+		// go through or the uploaded parts are billed until they expire.
+		abortCtx, abortCancel := context.WithTimeout(context.Background(), abortUploadTimeout)
+		defer abortCancel()
+		if err := s.AbortMultipartUpload(abortCtx, key, uploadID); err != nil {
+			log.Printf("failed to abort multipart upload %s of %s: %v", uploadID, key, err)
+		}
+		return nil, fmt.Errorf("failed to upload file: %w", uploadErr)
+	}
+
+	if err := s.CompleteMultipartUpload(ctx, key, uploadID, completed); err != nil {
+		abortCtx, abortCancel := context.WithTimeout(context.Background(), abortUploadTimeout)
+		defer abortCancel()
+		if abortErr := s.AbortMultipartUpload(abortCtx, key, uploadID); abortErr != nil {
+			log.Printf("failed to abort multipart upload %s of %s: %v", uploadID, key, abortErr)
+		}
+		return nil, err
+	}
+	return &UploadOutput{Key: key, Size: size, Parts: len(completed)}, nil
+}
+
+// uploadPartWithRetries uploads one part, retrying failures with an
+// exponential backoff.
+func (s *S3Client) uploadPartWithRetries(ctx context.Context, key, uploadID string, part uploadPart, retries int) (string, error) {
+	for attempt := 0; ; attempt++ {
+		output, err := s.Client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(s.Bucket),
+			Key:           aws.String(key),
+			UploadId:      aws.String(uploadID),
+			PartNumber:    aws.Int32(part.number),
+			Body:          bytes.NewReader(part.data),
+			ContentLength: aws.Int64(int64(len(part.data))),
+		})
+		if err == nil {
+			return aws.ToString(output.ETag), nil
+		}
+		if attempt >= retries || ctx.Err() != nil {
+			return "", fmt.Errorf("failed to upload part %d: %w", part.number, err)
+		}
+
+		select {
+		case <-time.After(uploadRetryBackoff << attempt):
+		case <-ctx.Done():
+			return "", fmt.Errorf("failed to upload part %d: %w", part.number, ctx.Err())
+		}
+	}
+}
@@ -1,7 +1,9 @@
+package utils
+
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"time"
 
@@ -9,10 +11,11 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func ConnectDB() (*mongo.Client, context.Context) {
+// ConnectDB connects to the MongoDB instance configured by MONGO_URI.
+func ConnectDB() (*mongo.Client, error) {
 	mongoURI := os.Getenv("MONGO_URI")
 	if mongoURI == "" {
-		log.Fatal("MONGO_URI environment variable is not set")
+		return nil, errors.New("MONGO_URI environment variable is not set")
 	}
 
 	clientOptions := options.Client().ApplyURI(mongoURI)
@@ -21,14 +24,14 @@ func ConnectDB() (*mongo.Client, context.Context) {
 
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
 	}
 
 	fmt.Println("Connected to MongoDB!")
-	return client, ctx
-}
\ No newline at end of file
+	return client, nil
+}
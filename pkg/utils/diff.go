@@ -0,0 +1,142 @@
+package utils
+
+import "strings"
+
+// DiffOp says what happened to a line between two texts.
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffLine is a line of a diff.
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxDiffEdits bounds the edit distance the Myers algorithm searches. Its
+// memory grows with the square of the distance, so texts that differ in
+// more lines are diffed as a plain replacement.
+const maxDiffEdits = 1000
+
+// DiffLines returns a shortest line diff turning a into b, using the Myers
+// algorithm. Beyond maxDiffEdits changed lines, the changed part of a is
+// deleted and the one of b inserted as a whole.
+func DiffLines(a, b string) []DiffLine {
+	x, y := splitLines(a), splitLines(b)
+
+	// Most edits touch a small part of the text; only diff that part.
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]DiffLine, 0, len(x)+len(y)-prefix-suffix)
+	for _, text := range x[:prefix] {
+		lines = append(lines, DiffLine{Op: DiffEqual, Text: text})
+	}
+	lines = append(lines, myersDiff(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, text := range x[len(x)-suffix:] {
+		lines = append(lines, DiffLine{Op: DiffEqual, Text: text})
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// myersDiff finds the furthest reaching path for every number of edits d,
+// keeping the frontier of each step to walk the path back. It falls back to
+// replaceDiff when no path takes at most maxDiffEdits edits.
+func myersDiff(x, y []string) []DiffLine {
+	n, m := len(x), len(y)
+	if n == 0 && m == 0 {
+		return nil
+	}
+	max := n + m
+	if max > maxDiffEdits {
+		max = maxDiffEdits
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] holds the frontier for diagonals -d-1 to d+1 before step d.
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= n && j >= m {
+				return myersPath(trace, x, y)
+			}
+		}
+	}
+	return replaceDiff(x, y)
+}
+
+// replaceDiff deletes every line of x and inserts every line of y.
+func replaceDiff(x, y []string) []DiffLine {
+	lines := make([]DiffLine, 0, len(x)+len(y))
+	for _, text := range x {
+		lines = append(lines, DiffLine{Op: DiffDelete, Text: text})
+	}
+	for _, text := range y {
+		lines = append(lines, DiffLine{Op: DiffInsert, Text: text})
+	}
+	return lines
+}
+
+func myersPath(trace [][]int, x, y []string) []DiffLine {
+	var lines []DiffLine
+	i, j := len(x), len(y)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v, offset := trace[d], d+1
+		k := i - j
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevI := v[offset+prevK]
+		prevJ := prevI - prevK
+		for i > prevI && j > prevJ {
+			lines = append(lines, DiffLine{Op: DiffEqual, Text: x[i-1]})
+			i--
+			j--
+		}
+		if d == 0 {
+			break
+		}
+		if i == prevI {
+			lines = append(lines, DiffLine{Op: DiffInsert, Text: y[j-1]})
+			j--
+		} else {
+			lines = append(lines, DiffLine{Op: DiffDelete, Text: x[i-1]})
+			i--
+		}
+	}
+	for a, b := 0, len(lines)-1; a < b; a, b = a+1, b-1 {
+		lines[a], lines[b] = lines[b], lines[a]
+	}
+	return lines
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// applyDiff returns the texts a diff turns into each other.
+func applyDiff(lines []DiffLine) (string, string) {
+	var a, b []string
+	for _, line := range lines {
+		if line.Op != DiffInsert {
+			a = append(a, line.Text)
+		}
+		if line.Op != DiffDelete {
+			b = append(b, line.Text)
+		}
+	}
+	return strings.Join(a, "\n"), strings.Join(b, "\n")
+}
+
+func numberedLines(prefix string, from, to int) string {
+	lines := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		lines = append(lines, fmt.Sprintf("%s %d", prefix, i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      string
+		wantEdits int
+	}{
+		{name: "equal", a: "one\ntwo", b: "one\ntwo"},
+		{name: "both empty"},
+		{name: "from empty", b: "one\ntwo", wantEdits: 2},
+		{name: "to empty", a: "one\ntwo", wantEdits: 2},
+		{name: "insert", a: "one\nthree", b: "one\ntwo\nthree", wantEdits: 1},
+		{name: "delete", a: "one\ntwo\nthree", b: "one\nthree", wantEdits: 1},
+		{name: "change", a: "one\ntwo\nthree", b: "one\nzwei\nthree", wantEdits: 2},
+		{name: "moved line", a: "a\nb\nc\nd", b: "b\nc\nd\na", wantEdits: 2},
+		{name: "interleaved", a: "a\nb\nc\na\nb\nb\na", b: "c\nb\na\nb\na\nc", wantEdits: 5},
+		{
+			name:      "beyond the edit limit",
+			a:         numberedLines("old", 0, maxDiffEdits),
+			b:         numberedLines("new", 0, maxDiffEdits),
+			wantEdits: 2 * maxDiffEdits,
+		},
+		{
+			name:      "few edits in a long text",
+			a:         numberedLines("line", 0, 3*maxDiffEdits),
+			b:         strings.Replace(numberedLines("line", 0, 3*maxDiffEdits), "line 1500\n", "", 1),
+			wantEdits: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := DiffLines(tt.a, tt.b)
+			gotA, gotB := applyDiff(lines)
+			if gotA != tt.a || gotB != tt.b {
+				t.Fatalf("diff does not turn a into b:\n%v", lines)
+			}
+			edits := 0
+			for _, line := range lines {
+				if line.Op != DiffEqual {
+					edits++
+				}
+			}
+			if edits != tt.wantEdits {
+				t.Errorf("edits: got %d, want %d", edits, tt.wantEdits)
+			}
+		})
+	}
+}
+
+func TestDiffLinesFallsBackToReplace(t *testing.T) {
+	// Alternating lines keep the common prefix and suffix empty and need
+	// more edits than the limit allows.
+	var a, b []string
+	for i := 0; i < maxDiffEdits; i++ {
+		a = append(a, fmt.Sprintf("kept %d", i), fmt.Sprintf("old %d", i))
+		b = append(b, fmt.Sprintf("kept %d", i), fmt.Sprintf("new %d", i))
+	}
+	a = append([]string{"first a"}, a...)
+	b = append([]string{"first b"}, b...)
+	lines := DiffLines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+
+	if len(lines) != len(a)+len(b) {
+		t.Fatalf("lines: got %d, want %d", len(lines), len(a)+len(b))
+	}
+	for i, line := range lines {
+		want := DiffDelete
+		if i >= len(a) {
+			want = DiffInsert
+		}
+		if line.Op != want {
+			t.Fatalf("line %d: got %s, want %s", i, line.Op, want)
+		}
+	}
+}
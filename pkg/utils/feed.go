@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+// Feed is a syndication feed that can be written as RSS 2.0 or Atom 1.0.
+// Link is the website the feed belongs to and FeedURL the URL the feed is
+// served at.
+type Feed struct {
+	Title       string
+	Link        string
+	FeedURL     string
+	Description string
+	Author      string
+	Updated     time.Time
+	Items       []FeedItem
+}
+
+// FeedItem is an entry of a feed. Summary and Content are HTML.
+type FeedItem struct {
+	ID         string
+	Title      string
+	Link       string
+	Author     string
+	Summary    string
+	Content    string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+	Enclosure  *FeedEnclosure
+}
+
+// FeedEnclosure is a media file attached to a feed item. Length is zero when
+// the size is unknown.
+type FeedEnclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          *atomLink `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	Creator     string        `xml:"dc:creator,omitempty"`
+	Description string        `xml:"description,omitempty"`
+	Content     string        `xml:"content:encoded,omitempty"`
+	Categories  []string      `xml:"category"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// RSS returns the feed as an RSS 2.0 document. RSS has no update time per
+// item, so items are dated by their publication.
+func (f *Feed) RSS() ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		Items:       []rssItem{},
+	}
+	if f.FeedURL != "" {
+		channel.Self = &atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"}
+	}
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range f.Items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.ID},
+			Creator:     item.Author,
+			Description: item.Summary,
+			Content:     item.Content,
+			Categories:  item.Categories,
+		}
+		if !item.Published.IsZero() {
+			entry.PubDate = item.Published.UTC().Format(time.RFC1123Z)
+		}
+		if item.Enclosure != nil {
+			entry.Enclosure = &rssEnclosure{
+				URL:    item.Enclosure.URL,
+				Type:   item.Enclosure.Type,
+				Length: strconv.FormatInt(item.Enclosure.Length, 10),
+			}
+		}
+		channel.Items = append(channel.Items, entry)
+	}
+	return marshalXML(rssFeed{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel:   channel,
+	})
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   *atomPerson `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Author     *atomPerson    `xml:"author"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary"`
+	Content    *atomText      `xml:"content"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Atom returns the feed as an Atom 1.0 document. Items without an author
+// fall back to the author of the feed.
+func (f *Feed) Atom() ([]byte, error) {
+	feed := atomFeed{
+		ID:       f.FeedURL,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  atomTime(f.Updated),
+		Links:    []atomLink{{Href: f.Link, Rel: "alternate", Type: "text/html"}},
+		Entries:  []atomEntry{},
+	}
+	if feed.ID == "" {
+		feed.ID = f.Link
+	}
+	if f.FeedURL != "" {
+		feed.Links = append(feed.Links, atomLink{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+	if f.Author != "" {
+		feed.Author = &atomPerson{Name: f.Author}
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:      item.ID,
+			Title:   item.Title,
+			Updated: atomTime(item.Updated),
+			Links:   []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+		}
+		if !item.Published.IsZero() {
+			entry.Published = atomTime(item.Published)
+		}
+		if item.Author != "" {
+			entry.Author = &atomPerson{Name: item.Author}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "html", Body: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Body: item.Content}
+		}
+		if item.Enclosure != nil {
+			entry.Links = append(entry.Links, atomLink{
+				Href:   item.Enclosure.URL,
+				Rel:    "enclosure",
+				Type:   item.Enclosure.Type,
+				Length: item.Enclosure.Length,
+			})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalXML(feed)
+}
+
+// atomTime formats a time as RFC 3339. Atom requires update times, so the
+// zero time is written as the Unix epoch.
+func atomTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileMailer is a Mailer for local development. It writes every mail as an
+// .eml file to Dir, or only logs it when Dir is empty.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// NewFileMailer creates dir if needed and returns a FileMailer writing to it.
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %w", err)
+		}
+	}
+	return &FileMailer{Dir: dir, From: from}, nil
+}
+
+// Send writes or logs the mail.
+func (m *FileMailer) Send(ctx context.Context, mail Mail) error {
+	if m.Dir == "" {
+		log.Printf("mail to %s: %s\n%s", mail.To, mail.Subject, mail.Body)
+		return nil
+	}
+	name := time.Now().UTC().Format("20060102T150405") + "-" + uuid.New().String()[:8] + ".eml"
+	path := filepath.Join(m.Dir, name)
+	if err := os.WriteFile(path, mail.message(m.From), 0o600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	log.Printf("mail to %s written to %s", mail.To, path)
+	return nil
+}
@@ -1,36 +1,68 @@
 package utils
 
 import (
+	"crypto"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET"))
+// AccessTokenTTL is the lifetime of the access tokens created by GenerateJWT.
+// Clients use their refresh token to get a new one.
+const AccessTokenTTL = 15 * time.Minute
+
+// JWTKey is a key pair used to sign access tokens. PrivateKey is nil for
+// keys that are only used for verification.
+type JWTKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// JWTKeySet provides the current signing key and looks up verification keys
+// by their ID, which is sent in the "kid" header of every token.
+type JWTKeySet interface {
+	SigningKey() (*JWTKey, error)
+	VerificationKey(kid string) (*JWTKey, error)
+}
 
 type Claims struct {
-	UserID string `json:"user_id"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID string) (string, error) {
-	expirationTime := time.Now().Add(1 * time.Hour)
+func GenerateJWT(keys JWTKeySet, issuer, userID, sessionID string) (string, error) {
+	key, err := keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	method := jwt.GetSigningMethod(key.Algorithm)
+	if method == nil {
+		return "", fmt.Errorf("unsupported signing algorithm %q", key.Algorithm)
+	}
+
+	expirationTime := time.Now().Add(AccessTokenTTL)
 
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   userID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
 
-	tokenString, err := token.SignedString(jwtKey)
+	tokenString, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 		return "", err
 	}
@@ -38,13 +70,28 @@ func GenerateJWT(userID string) (string, error) {
 	return tokenString, nil
 }
 
-func VerifyJWT(tokenString string) (*Claims, error) {
+// VerifyJWT checks the signature of a token against the key named by its
+// "kid" header. An empty issuer is not checked.
+func VerifyJWT(keys JWTKeySet, issuer, tokenString string) (*Claims, error) {
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA})}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no key ID")
+		}
+		key, err := keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		// The algorithm is bound to the key so a token cannot pick another one.
+		if token.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return jwtKey, nil
-	})
+		return key.PublicKey, nil
+	}, options...)
 
 	if err != nil {
 		return nil, err
@@ -57,14 +104,6 @@ func VerifyJWT(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-func GetUserIDFromToken(tokenString string) (string, error) {
-	claims, err := VerifyJWT(tokenString)
-	if err != nil {
-		return "", err
-	}
-	return claims.UserID, nil
-}
-
 func GenerateHash(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	return string(bytes), err
@@ -73,4 +112,4 @@ func GenerateHash(password string) (string, error) {
 func CompareHashAndPassword(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
-}
\ No newline at end of file
+}
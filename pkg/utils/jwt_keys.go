@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Supported JWT signing algorithms.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+	// AlgorithmES256 is only used to verify tokens of identity providers.
+	AlgorithmES256 = "ES256"
+)
+
+const rsaKeyBits = 2048
+
+// GenerateJWTKey creates a new key pair for the given algorithm.
+func GenerateJWTKey(id, algorithm string) (*JWTKey, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	return &JWTKey{ID: id, Algorithm: algorithm, PrivateKey: private, PublicKey: private.Public()}, nil
+}
+
+// EncodePrivateKey returns the private key as a PKCS #8 PEM block.
+func (k *JWTKey) EncodePrivateKey() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// EncodePublicKey returns the public key as a PKIX PEM block.
+func (k *JWTKey) EncodePublicKey() (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParseJWTKey decodes a key stored with EncodePrivateKey and
+// EncodePublicKey. privatePEM may be empty for verification-only keys.
+func ParseJWTKey(id, algorithm, privatePEM, publicPEM string) (*JWTKey, error) {
+	key := &JWTKey{ID: id, Algorithm: algorithm}
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("invalid public key PEM")
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	key.PublicKey = public
+
+	if privatePEM != "" {
+		block, _ := pem.Decode([]byte(privatePEM))
+		if block == nil {
+			return nil, errors.New("invalid private key PEM")
+		}
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("private key cannot sign")
+		}
+		key.PrivateKey = signer
+	}
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys (RFC 8037) and, with Y, EC keys.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWK returns the public key in JSON Web Key format.
+func (k *JWTKey) JWK() (JWK, error) {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+	switch public := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", k.PublicKey)
+	}
+	return jwk, nil
+}
+
+// PublicKey decodes the key. RSA, P-256 and Ed25519 keys are supported.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.KeyType == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case k.KeyType == "EC" && k.Curve == "P-256":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		point := append([]byte{4}, append(leftPad(x, 32), leftPad(y, 32)...)...)
+		return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+	case k.KeyType == "OKP" && k.Curve == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
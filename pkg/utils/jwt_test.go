@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeySet signs with the key named by signing and verifies with any of
+// its keys.
+type testKeySet struct {
+	keys    map[string]*JWTKey
+	signing string
+}
+
+func (s *testKeySet) SigningKey() (*JWTKey, error) {
+	return s.keys[s.signing], nil
+}
+
+func (s *testKeySet) VerificationKey(kid string) (*JWTKey, error) {
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+	return key, nil
+}
+
+func newTestKey(t *testing.T, id, algorithm string) *JWTKey {
+	t.Helper()
+	key, err := GenerateJWTKey(id, algorithm)
+	if err != nil {
+		t.Fatalf("GenerateJWTKey(%s): %v", algorithm, err)
+	}
+	return key
+}
+
+// signTestToken signs claims with key, setting the kid header unless it is
+// empty.
+func signTestToken(t *testing.T, key *JWTKey, kid string, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func TestVerifyJWT(t *testing.T) {
+	rsaKey := newTestKey(t, "rsa", AlgorithmRS256)
+	edKey := newTestKey(t, "ed", AlgorithmEdDSA)
+	otherEdKey := newTestKey(t, "other", AlgorithmEdDSA)
+	keys := &testKeySet{keys: map[string]*JWTKey{"rsa": rsaKey, "ed": edKey}, signing: "ed"}
+	claims := func(issuer string, expiresIn time.Duration) *Claims {
+		return &Claims{
+			UserID:    "user-1",
+			SessionID: "session-1",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    issuer,
+				Subject:   "user-1",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		issuer  string
+		wantErr bool
+	}{
+		{name: "RS256 key", token: signTestToken(t, rsaKey, "rsa", claims("", time.Minute))},
+		{name: "EdDSA key", token: signTestToken(t, edKey, "ed", claims("", time.Minute))},
+		{name: "issuer", token: signTestToken(t, edKey, "ed", claims("tvwc", time.Minute)), issuer: "tvwc"},
+		{name: "wrong issuer", token: signTestToken(t, edKey, "ed", claims("other", time.Minute)), issuer: "tvwc", wantErr: true},
+		{name: "no kid", token: signTestToken(t, edKey, "", claims("", time.Minute)), wantErr: true},
+		{name: "unknown kid", token: signTestToken(t, otherEdKey, "other", claims("", time.Minute)), wantErr: true},
+		{name: "kid of another key", token: signTestToken(t, otherEdKey, "ed", claims("", time.Minute)), wantErr: true},
+		{name: "kid of a key with another algorithm", token: signTestToken(t, edKey, "rsa", claims("", time.Minute)), wantErr: true},
+		{name: "expired", token: signTestToken(t, edKey, "ed", claims("", -time.Minute)), wantErr: true},
+		{name: "unsigned", token: func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodNone, claims("", time.Minute))
+			token.Header["kid"] = "ed"
+			signed, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return signed
+		}(), wantErr: true},
+		{name: "malformed", token: "not.a.token", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyJWT(keys, tt.issuer, tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyJWT: got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (got.UserID != "user-1" || got.SessionID != "session-1") {
+				t.Errorf("VerifyJWT: got claims %+v", got)
+			}
+		})
+	}
+}
+
+func TestGenerateJWT(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			old := newTestKey(t, "old", algorithm)
+			current := newTestKey(t, "current", algorithm)
+			keys := &testKeySet{keys: map[string]*JWTKey{"old": old, "current": current}, signing: "current"}
+
+			token, err := GenerateJWT(keys, "tvwc", "user-1", "session-1")
+			if err != nil {
+				t.Fatalf("GenerateJWT: %v", err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified: %v", err)
+			}
+			if kid := parsed.Header["kid"]; kid != "current" {
+				t.Errorf("kid: got %v, want %q", kid, "current")
+			}
+			if alg := parsed.Header["alg"]; alg != algorithm {
+				t.Errorf("alg: got %v, want %q", alg, algorithm)
+			}
+
+			claims, err := VerifyJWT(keys, "tvwc", token)
+			if err != nil {
+				t.Fatalf("VerifyJWT: %v", err)
+			}
+			if claims.UserID != "user-1" || claims.Subject != "user-1" || claims.SessionID != "session-1" {
+				t.Errorf("claims: got %+v", claims)
+			}
+			if ttl := time.Until(claims.ExpiresAt.Time); ttl <= 0 || ttl > AccessTokenTTL {
+				t.Errorf("expiry: got %v from now, want at most %v", ttl, AccessTokenTTL)
+			}
+
+			// Without its key the token no longer verifies.
+			delete(keys.keys, "current")
+			if _, err := VerifyJWT(keys, "tvwc", token); err == nil {
+				t.Error("VerifyJWT without the signing key: got no error")
+			}
+		})
+	}
+}
+
+func TestGenerateJWTUnsupportedAlgorithm(t *testing.T) {
+	key := newTestKey(t, "ed", AlgorithmEdDSA)
+	key.Algorithm = "HS256"
+	keys := &testKeySet{keys: map[string]*JWTKey{"ed": key}, signing: "ed"}
+	if _, err := GenerateJWT(keys, "", "user-1", "session-1"); err == nil {
+		t.Error("GenerateJWT with an HMAC key: got no error")
+	}
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores files on the local filesystem. The files are expected
+// to be served by a static route at BaseURL.
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+// NewLocalStorage creates the storage directory if needed and returns a
+// LocalStorage serving its files from baseURL.
+func NewLocalStorage(dir, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStorage{
+		Dir:     dir,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// path maps a key to a file inside Dir, rejecting keys that escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(clean)), nil
+}
+
+// Put writes a file. It implements Storage.
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a file. Deleting a missing file is not an error. It
+// implements Storage.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// List lists every file whose key starts with prefix. It implements Storage.
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]StorageObject, error) {
+	objects := []StorageObject{}
+	err := filepath.WalkDir(s.Dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Dir, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, StorageObject{
+			Key:          key,
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return objects, nil
+}
+
+// Open opens a file for reading. It implements Storage.
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, nil
+}
+
+// Stat returns the size and modification time of a file. It implements Storage.
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*StorageObject, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return &StorageObject{Key: key, Size: info.Size(), LastModified: info.ModTime()}, nil
+}
+
+// PublicURL returns the URL the file is served from. It implements Storage.
+func (s *LocalStorage) PublicURL(key string) string {
+	return s.BaseURL + "/" + key
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Mailer sends emails such as password reset links.
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// Mail is a plain text email.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// message renders the mail in RFC 5322 format.
+func (m Mail) message(from string) []byte {
+	var buf bytes.Buffer
+	if from != "" {
+		fmt.Fprintf(&buf, "From: %s\r\n", headerValue(from))
+	}
+	fmt.Fprintf(&buf, "To: %s\r\n", headerValue(m.To))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(m.Subject)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+// headerValue strips line breaks so values cannot inject headers.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
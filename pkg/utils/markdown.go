@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"bytes"
+	stdhtml "html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// TOCEntry is a heading of a rendered document. ID is the anchor of the
+// heading.
+type TOCEntry struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// RenderedMarkdown is a Markdown document rendered to sanitized HTML.
+type RenderedMarkdown struct {
+	HTML string     `json:"html"`
+	TOC  []TOCEntry `json:"toc"`
+}
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	// Raw HTML is passed through and removed by the sanitizer if unsafe.
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var markdownPolicy = newMarkdownPolicy()
+
+// newMarkdownPolicy allows the user generated content policy plus the
+// attributes the renderer adds: code fence languages for syntax
+// highlighters, heading anchors and task list checkboxes.
+func newMarkdownPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	policy.AllowAttrs("id").Matching(regexp.MustCompile(`^[\pL\pN_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	return policy
+}
+
+// RenderMarkdown renders CommonMark with GitHub tables, strikethrough,
+// autolinks and task lists to sanitized HTML. Headings get anchors, which
+// the table of contents links to.
+func RenderMarkdown(source string) (*RenderedMarkdown, error) {
+	src := []byte(source)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+
+	toc := []TOCEntry{}
+	err := ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		entry := TOCEntry{Level: heading.Level, Title: strings.TrimSpace(nodeText(heading, src))}
+		if id, ok := heading.AttributeString("id"); ok {
+			if id, ok := id.([]byte); ok {
+				entry.ID = string(id)
+			}
+		}
+		toc = append(toc, entry)
+		return ast.WalkSkipChildren, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+	return &RenderedMarkdown{HTML: markdownPolicy.Sanitize(buf.String()), TOC: toc}, nil
+}
+
+// nodeText returns the plain text of an inline node and its children.
+func nodeText(node ast.Node, src []byte) string {
+	var b strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child := child.(type) {
+		case *ast.Text:
+			b.Write(child.Segment.Value(src))
+			if child.SoftLineBreak() || child.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(child.Value)
+		default:
+			b.WriteString(nodeText(child, src))
+		}
+	}
+	return b.String()
+}
+
+var plainTextPolicy = bluemonday.StrictPolicy().AddSpaceWhenStrippingTag(true)
+
+// MarkdownText returns the plain text of a Markdown document, without markup
+// or raw HTML.
+func MarkdownText(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	text := plainTextPolicy.Sanitize(buf.String())
+	return strings.Join(strings.Fields(stdhtml.UnescapeString(text)), " "), nil
+}
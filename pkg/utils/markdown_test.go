@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		notWant []string
+		wantTOC []TOCEntry
+	}{
+		{
+			name:    "script",
+			source:  "Hello\n\n<script>alert(1)</script>",
+			want:    []string{"<p>Hello</p>"},
+			notWant: []string{"<script", "alert(1)"},
+		},
+		{
+			name:    "event handler",
+			source:  `<img src="https://example.com/a.png" onerror="alert(1)">`,
+			want:    []string{`src="https://example.com/a.png"`},
+			notWant: []string{"onerror", "alert(1)"},
+		},
+		{
+			name:    "javascript link",
+			source:  "[click](javascript:alert(1)) and <a href=\"javascript:alert(1)\">raw</a>",
+			want:    []string{"click", "raw"},
+			notWant: []string{"javascript:"},
+		},
+		{
+			name:   "safe link",
+			source: "[site](https://example.com)",
+			want:   []string{`href="https://example.com"`},
+		},
+		{
+			name:    "code language",
+			source:  "```go\nfmt.Println(1)\n```",
+			want:    []string{`<code class="language-go">`},
+			notWant: []string{"<pre><code>"},
+		},
+		{
+			name:    "unsafe code class",
+			source:  `<code class="evil">x</code>`,
+			notWant: []string{"evil"},
+		},
+		{
+			name:    "duplicate headings",
+			source:  "# X\n\n## X\n\ntext\n\n### Other *topic*",
+			want:    []string{`<h1 id="x">X</h1>`, `<h2 id="x-1">X</h2>`, `<h3 id="other-topic">`},
+			wantTOC: []TOCEntry{{Level: 1, ID: "x", Title: "X"}, {Level: 2, ID: "x-1", Title: "X"}, {Level: 3, ID: "other-topic", Title: "Other topic"}},
+		},
+		{
+			name:    "no headings",
+			source:  "just text",
+			want:    []string{"<p>just text</p>"},
+			wantTOC: []TOCEntry{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := RenderMarkdown(tt.source)
+			if err != nil {
+				t.Fatalf("RenderMarkdown: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(rendered.HTML, want) {
+					t.Errorf("HTML %q does not contain %q", rendered.HTML, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(rendered.HTML, notWant) {
+					t.Errorf("HTML %q contains %q", rendered.HTML, notWant)
+				}
+			}
+			if tt.wantTOC != nil && !reflect.DeepEqual(rendered.TOC, tt.wantTOC) {
+				t.Errorf("TOC: got %v, want %v", rendered.TOC, tt.wantTOC)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oidcKeyRefreshInterval limits how often the provider's keys are fetched
+// for tokens signed with an unknown key.
+const oidcKeyRefreshInterval = 10 * time.Second
+
+// OIDCClient signs users in with an OpenID Connect provider using the
+// authorization code flow with PKCE. The provider configuration is
+// discovered from the issuer on first use.
+type OIDCClient struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mu           sync.Mutex
+	provider     *oidcProvider
+	keys         map[string]oidcKey
+	keysLoadedAt time.Time
+}
+
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcKey struct {
+	algorithm string
+	publicKey crypto.PublicKey
+}
+
+// IDToken holds the verified claims of an ID token. Claims has every claim,
+// including the ones copied into the other fields.
+type IDToken struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Claims        map[string]interface{}
+}
+
+// NewOIDCClient creates a new OIDCClient. Scopes default to "openid email
+// profile".
+func NewOIDCClient(issuer, clientID, clientSecret, redirectURL string, scopes []string) (*OIDCClient, error) {
+	if issuer == "" || clientID == "" || redirectURL == "" {
+		return nil, errors.New("missing OIDC issuer, client ID or redirect URL")
+	}
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCClient{
+		Issuer:       strings.TrimSuffix(issuer, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// PKCEChallenge returns the S256 code challenge for a code verifier
+// (RFC 7636).
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the provider URL the user is sent to for signing in.
+func (c *OIDCClient) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	provider, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.ClientID)
+	query.Set("redirect_uri", c.RedirectURL)
+	query.Set("scope", strings.Join(c.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", PKCEChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return provider.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the raw ID token.
+func (c *OIDCClient) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	provider, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", c.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	}
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := c.doJSON(req, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	if status != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("token endpoint returned %d: %s %s", status, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("token response has no ID token")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of
+// an ID token.
+func (c *OIDCClient) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*IDToken, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := c.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.algorithm != "" && token.Method.Alg() != key.algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.publicKey, nil
+	},
+		jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA}),
+		jwt.WithIssuer(c.Issuer),
+		jwt.WithAudience(c.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("ID token nonce does not match")
+	}
+	// A token for several audiences must name us as the authorized party.
+	if audience, _ := claims.GetAudience(); len(audience) > 1 {
+		if azp, _ := claims["azp"].(string); azp != c.ClientID {
+			return nil, errors.New("ID token was not issued to this client")
+		}
+	}
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	idToken := &IDToken{Subject: subject, Claims: claims}
+	idToken.Email, _ = claims["email"].(string)
+	idToken.Name, _ = claims["name"].(string)
+	// Some providers send email_verified as a string.
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		idToken.EmailVerified = verified
+	case string:
+		idToken.EmailVerified = verified == "true"
+	}
+	return idToken, nil
+}
+
+// discover fetches the provider configuration once.
+func (c *OIDCClient) discover(ctx context.Context) (*oidcProvider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider != nil {
+		return c.provider, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var provider oidcProvider
+	status, err := c.doJSON(req, &provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("OIDC discovery returned %d", status)
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != c.Issuer {
+		return nil, fmt.Errorf("OIDC provider reports issuer %q, expected %q", provider.Issuer, c.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("OIDC provider configuration is incomplete")
+	}
+	c.provider = &provider
+	return c.provider, nil
+}
+
+// key returns the provider key with the given ID. The keys are fetched
+// again when the ID is unknown, so that rotated keys are picked up.
+func (c *OIDCClient) key(ctx context.Context, kid string) (oidcKey, error) {
+	provider, err := c.discover(ctx)
+	if err != nil {
+		return oidcKey{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(c.keysLoadedAt) < oidcKeyRefreshInterval {
+		return oidcKey{}, errors.New("unknown signing key")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.JWKSURI, nil)
+	if err != nil {
+		return oidcKey{}, err
+	}
+	var set struct {
+		Keys []JWK `json:"keys"`
+	}
+	status, err := c.doJSON(req, &set)
+	if err != nil {
+		return oidcKey{}, fmt.Errorf("failed to get OIDC provider keys: %w", err)
+	}
+	if status != http.StatusOK {
+		return oidcKey{}, fmt.Errorf("OIDC provider keys returned %d", status)
+	}
+	keys := make(map[string]oidcKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			// Skip key types we cannot use rather than failing every login.
+			continue
+		}
+		keys[jwk.KeyID] = oidcKey{algorithm: jwk.Algorithm, publicKey: publicKey}
+	}
+	c.keys = keys
+	c.keysLoadedAt = time.Now()
+
+	if key, ok := c.lookupKey(kid); ok {
+		return key, nil
+	}
+	return oidcKey{}, errors.New("unknown signing key")
+}
+
+// lookupKey finds a cached key. Tokens without a key ID are accepted when
+// the provider has a single key. c.mu must be held.
+func (c *OIDCClient) lookupKey(kid string) (oidcKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// doJSON sends the request and decodes a JSON response of at most 1 MiB.
+func (c *OIDCClient) doJSON(req *http.Request, v interface{}) (int, error) {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return resp.StatusCode, fmt.Errorf("invalid response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestOIDCProvider serves the discovery document and the public keys of
+// keys, and returns a client for it.
+func newTestOIDCProvider(t *testing.T, keys ...*JWTKey) *OIDCClient {
+	t.Helper()
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"jwks_uri":               server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		var set struct {
+			Keys []JWK `json:"keys"`
+		}
+		for _, key := range keys {
+			jwk, err := key.JWK()
+			if err != nil {
+				t.Errorf("JWK: %v", err)
+			}
+			set.Keys = append(set.Keys, jwk)
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewOIDCClient(server.URL, "client-1", "", server.URL+"/callback", nil)
+	if err != nil {
+		t.Fatalf("NewOIDCClient: %v", err)
+	}
+	return client
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	edKey := newTestKey(t, "ed", AlgorithmEdDSA)
+	rsaKey := newTestKey(t, "rsa", AlgorithmRS256)
+	unknownKey := newTestKey(t, "unknown", AlgorithmEdDSA)
+	client := newTestOIDCProvider(t, edKey, rsaKey)
+	const nonce = "nonce-1"
+
+	// claims returns valid claims of a token for client-1 with changes
+	// applied; a nil value removes a claim.
+	claims := func(changes map[string]interface{}) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":            client.Issuer,
+			"aud":            "client-1",
+			"sub":            "subject-1",
+			"exp":            time.Now().Add(time.Minute).Unix(),
+			"iat":            time.Now().Unix(),
+			"nonce":          nonce,
+			"email":          "user@example.com",
+			"email_verified": true,
+			"name":           "User",
+		}
+		for claim, value := range changes {
+			if value == nil {
+				delete(c, claim)
+			} else {
+				c[claim] = value
+			}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name      string
+		key       *JWTKey
+		kid       string
+		claims    jwt.MapClaims
+		nonce     string
+		wantErr   bool
+		wantEmail bool
+	}{
+		{name: "valid", key: edKey, kid: "ed", claims: claims(nil), wantEmail: true},
+		{name: "RSA key", key: rsaKey, kid: "rsa", claims: claims(nil), wantEmail: true},
+		{name: "email_verified as string", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"email_verified": "true"}), wantEmail: true},
+		{name: "email not verified", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"email_verified": false})},
+		{name: "wrong issuer", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"iss": "https://evil.example.com"}), wantErr: true},
+		{name: "no issuer", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"iss": nil}), wantErr: true},
+		{name: "wrong audience", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"aud": "client-2"}), wantErr: true},
+		{name: "several audiences with azp", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"aud": []string{"client-1", "client-2"}, "azp": "client-1"}), wantEmail: true},
+		{name: "several audiences without azp", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"aud": []string{"client-1", "client-2"}}), wantErr: true},
+		{name: "several audiences with other azp", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"aud": []string{"client-1", "client-2"}, "azp": "client-2"}), wantErr: true},
+		{name: "wrong nonce", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"nonce": "nonce-2"}), wantErr: true},
+		{name: "no nonce", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"nonce": nil}), wantErr: true},
+		{name: "expired within leeway", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"exp": time.Now().Add(-30 * time.Second).Unix()}), wantEmail: true},
+		{name: "expired", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"exp": time.Now().Add(-2 * time.Minute).Unix()}), wantErr: true},
+		{name: "no expiry", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"exp": nil}), wantErr: true},
+		{name: "no subject", key: edKey, kid: "ed", claims: claims(map[string]interface{}{"sub": nil}), wantErr: true},
+		{name: "unknown key", key: unknownKey, kid: "unknown", claims: claims(nil), wantErr: true},
+		{name: "signed with another key", key: unknownKey, kid: "ed", claims: claims(nil), wantErr: true},
+		{name: "algorithm of another key", key: rsaKey, kid: "ed", claims: claims(nil), wantErr: true},
+		{name: "no kid with several keys", key: edKey, claims: claims(nil), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signTestToken(t, tt.key, tt.kid, tt.claims)
+			idToken, err := client.VerifyIDToken(context.Background(), token, nonce)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyIDToken: got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if idToken.Subject != "subject-1" || idToken.Email != "user@example.com" || idToken.Name != "User" {
+				t.Errorf("VerifyIDToken: got %+v", idToken)
+			}
+			if idToken.EmailVerified != tt.wantEmail {
+				t.Errorf("EmailVerified: got %v, want %v", idToken.EmailVerified, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenSingleKeyWithoutKID(t *testing.T) {
+	key := newTestKey(t, "ed", AlgorithmEdDSA)
+	client := newTestOIDCProvider(t, key)
+	token := signTestToken(t, key, "", jwt.MapClaims{
+		"iss":   client.Issuer,
+		"aud":   "client-1",
+		"sub":   "subject-1",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "nonce-1",
+	})
+	if _, err := client.VerifyIDToken(context.Background(), token, "nonce-1"); err != nil {
+		t.Errorf("VerifyIDToken: %v", err)
+	}
+}
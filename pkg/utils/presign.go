@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// PresignedRequest is a request a client can send directly to the storage
+// backend without holding any credentials.
+type PresignedRequest struct {
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	Headers   http.Header `json:"headers,omitempty"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// CompletedPart identifies an uploaded part of a multipart upload.
+type CompletedPart struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+// PresignedUploader is implemented by storage backends that accept uploads
+// straight from the client, either as a single PUT or as a multipart upload
+// whose parts are PUT individually. The size of every PUT is signed, so the
+// client cannot upload more than it announced.
+type PresignedUploader interface {
+	PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedRequest, error)
+	CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error)
+	PresignUploadPart(ctx context.Context, key, uploadID string, partNumber int32, size int64, expires time.Duration) (*PresignedRequest, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+}
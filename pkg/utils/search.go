@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// searchStopWords are common English words that are not indexed.
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "from": true, "has": true, "have": true, "in": true, "into": true, "is": true,
+	"it": true, "its": true, "of": true, "on": true, "or": true, "our": true, "that": true, "the": true,
+	"their": true, "this": true, "to": true, "was": true, "we": true, "were": true, "with": true, "you": true,
+	"your": true,
+}
+
+// wordSpan is the position of a word in a slice of runes.
+type wordSpan struct {
+	start, end int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func wordSpans(runes []rune) []wordSpan {
+	var spans []wordSpan
+	start := -1
+	for i, r := range runes {
+		switch {
+		case isWordRune(r) && start < 0:
+			start = i
+		case !isWordRune(r) && start >= 0:
+			spans = append(spans, wordSpan{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, wordSpan{start, len(runes)})
+	}
+	return spans
+}
+
+// searchTerm returns the indexed form of a word, or "" if the word is not
+// indexed. A light stemmer folds plurals and -ing and -ed forms so that
+// "gardening" finds "gardens".
+func searchTerm(word string) string {
+	word = strings.ToLower(word)
+	if len([]rune(word)) < 2 || searchStopWords[word] {
+		return ""
+	}
+	switch {
+	case len(word) > 5 && strings.HasSuffix(word, "ing"):
+		word = strings.TrimSuffix(word, "ing")
+	case len(word) > 4 && strings.HasSuffix(word, "ed") && !strings.HasSuffix(word, "eed"):
+		word = strings.TrimSuffix(word, "ed")
+	case len(word) > 4 && strings.HasSuffix(word, "ies") && !strings.HasSuffix(word, "eies") && !strings.HasSuffix(word, "aies"):
+		word = strings.TrimSuffix(word, "ies") + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "oes"):
+		word = strings.TrimSuffix(word, "es")
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		word = strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// SearchTokens returns the indexed terms of a text in order, including
+// repeated terms.
+func SearchTokens(text string) []string {
+	runes := []rune(text)
+	var tokens []string
+	for _, span := range wordSpans(runes) {
+		if term := searchTerm(string(runes[span.start:span.end])); term != "" {
+			tokens = append(tokens, term)
+		}
+	}
+	return tokens
+}
+
+// SearchTerms returns the distinct indexed terms of a search query.
+func SearchTerms(query string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, token := range SearchTokens(query) {
+		if !seen[token] {
+			seen[token] = true
+			terms = append(terms, token)
+		}
+	}
+	return terms
+}
+
+// HighlightSnippet returns an HTML escaped excerpt of text of about length
+// characters around the first word matching one of terms. Matching words
+// are wrapped in <mark> elements; an ellipsis marks cut text.
+func HighlightSnippet(text string, terms []string, length int) string {
+	wanted := map[string]bool{}
+	for _, term := range terms {
+		wanted[term] = true
+	}
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	spans := wordSpans(runes)
+	matches := func(span wordSpan) bool {
+		return wanted[searchTerm(string(runes[span.start:span.end]))]
+	}
+
+	start, end := 0, len(runes)
+	if len(runes) > length {
+		// Show some context before the first match.
+		for _, span := range spans {
+			if matches(span) {
+				start = span.start - length/4
+				break
+			}
+		}
+		if start > len(runes)-length {
+			start = len(runes) - length
+		}
+		if start <= 0 {
+			start = 0
+		} else {
+			for _, span := range spans {
+				if span.start >= start {
+					start = span.start
+					break
+				}
+			}
+		}
+		if end = start + length; end < len(runes) {
+			// Cut at a space so no word is split, unless the window has
+			// none, as in a long URL or text without spaces.
+			for end > start && runes[end] != ' ' {
+				end--
+			}
+			if end == start {
+				end = start + length
+			}
+		} else {
+			end = len(runes)
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	pos := start
+	for _, span := range spans {
+		if span.start < start || span.end > end || !matches(span) {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:span.start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[span.start:span.end])))
+		b.WriteString("</mark>")
+		pos = span.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString(" …")
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightSnippet(t *testing.T) {
+	longURL := "https://example.com/" + strings.Repeat("a", 100)
+	cjk := strings.Repeat("日本語", 40)
+	tests := []struct {
+		name   string
+		text   string
+		terms  []string
+		length int
+		want   string
+	}{
+		{name: "short text", text: "Growing  tomatoes\nat home", terms: []string{"tomato"}, length: 100, want: "Growing <mark>tomatoes</mark> at home"},
+		{name: "escaped", text: "<b>tomatoes</b> & more", terms: []string{"tomato"}, length: 100, want: "&lt;b&gt;<mark>tomatoes</mark>&lt;/b&gt; &amp; more"},
+		{name: "no terms", text: "one two three four five", length: 10, want: "one two …"},
+		{name: "context before match", text: "one two three four five six seven eight tomatoes nine ten eleven", terms: []string{"tomato"}, length: 24, want: "… eight <mark>tomatoes</mark> nine ten …"},
+		{name: "match at the end", text: "one two three four five six seven tomatoes", terms: []string{"tomato"}, length: 20, want: "… six seven <mark>tomatoes</mark>"},
+		{name: "long URL", text: longURL + " end", length: 30, want: string([]rune(longURL)[:30]) + " …"},
+		{name: "text without spaces", text: cjk, length: 30, want: string([]rune(cjk)[:30]) + " …"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HighlightSnippet(tt.text, tt.terms, tt.length); got != tt.want {
+				t.Errorf("HighlightSnippet: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxSitemapURLs is the most URLs the sitemap protocol allows in one
+// sitemap. Larger sites need a sitemap index.
+const MaxSitemapURLs = 50000
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapEntry is a URL in a sitemap or a sitemap in a sitemap index.
+// LastMod is left out when zero.
+type SitemapEntry struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// EncodeSitemap returns a sitemap listing the entries.
+func EncodeSitemap(entries []SitemapEntry) ([]byte, error) {
+	return marshalXML(sitemapURLSet{XMLNS: sitemapNamespace, URLs: sitemapURLs(entries)})
+}
+
+// EncodeSitemapIndex returns a sitemap index listing the sitemaps.
+func EncodeSitemapIndex(sitemaps []SitemapEntry) ([]byte, error) {
+	return marshalXML(sitemapIndex{XMLNS: sitemapNamespace, Sitemaps: sitemapURLs(sitemaps)})
+}
+
+func sitemapURLs(entries []SitemapEntry) []sitemapURL {
+	urls := make([]sitemapURL, 0, len(entries))
+	for _, entry := range entries {
+		url := sitemapURL{Loc: entry.Loc}
+		if !entry.LastMod.IsZero() {
+			url.LastMod = entry.LastMod.UTC().Format(time.RFC3339)
+		}
+		urls = append(urls, url)
+	}
+	return urls
+}
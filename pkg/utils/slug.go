@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify turns a title into a lowercase, hyphen separated URL segment.
+func Slugify(title string) string {
+	var b strings.Builder
+	lastHyphen := true
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			lastHyphen = false
+		case !lastHyphen:
+			b.WriteByte('-')
+			lastHyphen = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+)
+
+// SMTPMailer sends mail through an SMTP server. The connection is upgraded
+// with STARTTLS when the server supports it.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPConfigFromEnv reads the SMTP settings from the environment.
+func SMTPConfigFromEnv() SMTPMailer {
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	return SMTPMailer{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+}
+
+// NewSMTPMailer checks the configuration and returns an SMTPMailer.
+func NewSMTPMailer(cfg SMTPMailer) (*SMTPMailer, error) {
+	if cfg.Host == "" || cfg.From == "" {
+		return nil, errors.New("missing SMTP host or sender address")
+	}
+	return &cfg, nil
+}
+
+// Send delivers the mail. The context is not used since net/smtp does not
+// support cancellation.
+func (m *SMTPMailer) Send(ctx context.Context, mail Mail) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	addr := net.JoinHostPort(m.Host, m.Port)
+	if err := smtp.SendMail(addr, auth, m.From, []string{mail.To}, mail.message(m.From)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
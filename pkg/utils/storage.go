@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrObjectNotFound is returned by Storage.Open and Storage.Stat when the key
+// does not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage stores uploaded files such as videos and images.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]StorageObject, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (*StorageObject, error)
+	PublicURL(key string) string
+}
+
+// StorageObject describes a stored file.
+type StorageObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
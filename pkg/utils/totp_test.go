@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors,
+// "12345678901234567890", in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// The RFC lists 8 digit codes; 6 digit codes are their last 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPCounter(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d): got %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTOTPCodeSecretFormat(t *testing.T) {
+	want, err := TOTPCode(rfc6238Secret, 1)
+	if err != nil {
+		t.Fatalf("TOTPCode: %v", err)
+	}
+	for _, secret := range []string{strings.ToLower(rfc6238Secret), rfc6238Secret + "===="} {
+		got, err := TOTPCode(secret, 1)
+		if err != nil {
+			t.Fatalf("TOTPCode(%q): %v", secret, err)
+		}
+		if got != want {
+			t.Errorf("TOTPCode(%q): got %s, want %s", secret, got, want)
+		}
+	}
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode with an invalid secret: got no error")
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := TOTPCounter(now)
+	code := func(counter int64) string {
+		c, err := TOTPCode(rfc6238Secret, counter)
+		if err != nil {
+			t.Fatalf("TOTPCode: %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name        string
+		code        string
+		lastCounter int64
+		wantCounter int64
+		wantOK      bool
+	}{
+		{name: "current step", code: code(current), wantCounter: current, wantOK: true},
+		{name: "previous step", code: code(current - 1), wantCounter: current - 1, wantOK: true},
+		{name: "next step", code: code(current + 1), wantCounter: current + 1, wantOK: true},
+		{name: "two steps back", code: code(current - 2)},
+		{name: "two steps ahead", code: code(current + 2)},
+		{name: "spaces", code: code(current)[:3] + " " + code(current)[3:], wantCounter: current, wantOK: true},
+		{name: "reused", code: code(current), lastCounter: current},
+		{name: "older than last use", code: code(current - 1), lastCounter: current - 1},
+		{name: "after last use", code: code(current), lastCounter: current - 1, wantCounter: current, wantOK: true},
+		{name: "too short", code: code(current)[:5]},
+		{name: "too long", code: code(current) + "0"},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, ok := ValidateTOTP(rfc6238Secret, tt.code, now, tt.lastCounter)
+			if ok != tt.wantOK || counter != tt.wantCounter {
+				t.Errorf("ValidateTOTP(%q): got %d, %v, want %d, %v", tt.code, counter, ok, tt.wantCounter, tt.wantOK)
+			}
+		})
+	}
+}